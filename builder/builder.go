@@ -0,0 +1,447 @@
+// Package builder builds GraphQL operations in Go rather than by
+// concatenating strings, e.g.:
+//
+//	q := builder.Query("GetUser").Var("id", "ID!")
+//	q.Field("user", builder.Arg("id", builder.Var("id"))).Select("name", "email")
+//	query, err := q.String()
+//
+// Names are checked and values are escaped when the operation is built, so
+// the printed operation is always syntactically valid.
+package builder
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+)
+
+var nameRegExp = regexp.MustCompile("^[_a-zA-Z][_a-zA-Z0-9]*$")
+
+func newName(name string) (*ast.Name, error) {
+	astName, err := validName(name)
+	if err != nil {
+		return nil, fmt.Errorf("builder: %v", err)
+	}
+	return astName, nil
+}
+
+// validName is like newName, for the errors wrapped by the caller.
+func validName(name string) (*ast.Name, error) {
+	if !nameRegExp.MatchString(name) {
+		return nil, fmt.Errorf(`invalid name %q`, name)
+	}
+	return ast.NewName(&ast.Name{Value: name}), nil
+}
+
+// Operation builds a document holding a single operation. The first error met
+// while building it is reported by Document.
+type Operation struct {
+	definition *ast.OperationDefinition
+	err        error
+}
+
+// Query starts building a query. The name may be empty for an anonymous query.
+func Query(name string) *Operation {
+	return newOperation(ast.OperationTypeQuery, name)
+}
+
+// Mutation starts building a mutation.
+func Mutation(name string) *Operation {
+	return newOperation(ast.OperationTypeMutation, name)
+}
+
+// Subscription starts building a subscription.
+func Subscription(name string) *Operation {
+	return newOperation(ast.OperationTypeSubscription, name)
+}
+
+func newOperation(operation string, name string) *Operation {
+	o := &Operation{
+		definition: ast.NewOperationDefinition(&ast.OperationDefinition{
+			Operation:           operation,
+			VariableDefinitions: []*ast.VariableDefinition{},
+			Directives:          []*ast.Directive{},
+			SelectionSet:        ast.NewSelectionSet(&ast.SelectionSet{}),
+		}),
+	}
+	if name != "" {
+		o.definition.Name, o.err = newName(name)
+	}
+	return o
+}
+
+func (o *Operation) fail(err error) {
+	if o.err == nil {
+		o.err = err
+	}
+}
+
+// Var declares the variable name of type ttype, such as "[ID!]!".
+func (o *Operation) Var(name string, ttype string) *Operation {
+	o.addVar(name, ttype, nil)
+	return o
+}
+
+// VarDefault declares the variable name of type ttype, with a default value.
+// See Arg for the values allowed.
+func (o *Operation) VarDefault(name string, ttype string, defaultValue interface{}) *Operation {
+	value, err := astValue(defaultValue)
+	if err != nil {
+		o.fail(fmt.Errorf("builder: variable %q: %v", name, err))
+		return o
+	}
+	o.addVar(name, ttype, value)
+	return o
+}
+
+func (o *Operation) addVar(name string, ttype string, defaultValue ast.Value) {
+	varName, err := newName(name)
+	if err != nil {
+		o.fail(err)
+		return
+	}
+	varType, err := parser.ParseType(parser.ParseParams{
+		Source:  ttype,
+		Options: parser.ParseOptions{NoLocation: true},
+	})
+	if err != nil {
+		o.fail(fmt.Errorf("builder: variable %q: invalid type %q", name, ttype))
+		return
+	}
+	o.definition.VariableDefinitions = append(o.definition.VariableDefinitions, ast.NewVariableDefinition(&ast.VariableDefinition{
+		Variable:     ast.NewVariable(&ast.Variable{Name: varName}),
+		Type:         varType,
+		DefaultValue: defaultValue,
+	}))
+}
+
+// Field adds the field name to the operation and returns it, so that its own
+// selections can be added.
+func (o *Operation) Field(name string, opts ...Option) *Selection {
+	return o.selection().Field(name, opts...)
+}
+
+// Select adds leaf fields to the operation.
+func (o *Operation) Select(names ...string) *Operation {
+	o.selection().Select(names...)
+	return o
+}
+
+// On adds an inline fragment on the type typeName to the operation, or with
+// no type condition if typeName is empty.
+func (o *Operation) On(typeName string, opts ...Option) *Selection {
+	return o.selection().On(typeName, opts...)
+}
+
+func (o *Operation) selection() *Selection {
+	return &Selection{
+		operation:    o,
+		selectionSet: o.definition.SelectionSet,
+	}
+}
+
+// Document returns the document holding the operation, or the first error met
+// while building it. The document must not be edited.
+func (o *Operation) Document() (*ast.Document, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	return ast.NewDocument(&ast.Document{
+		Definitions: []ast.Node{o.definition},
+	}), nil
+}
+
+// String prints the operation.
+func (o *Operation) String() (string, error) {
+	doc, err := o.Document()
+	if err != nil {
+		return "", err
+	}
+	return printer.Format(doc, printer.Options{}), nil
+}
+
+// Validate builds the operation and validates it against schema.
+func (o *Operation) Validate(schema *graphql.Schema) error {
+	doc, err := o.Document()
+	if err != nil {
+		return err
+	}
+	if result := graphql.ValidateDocument(schema, doc, nil); !result.IsValid {
+		return ValidationErrors(result.Errors)
+	}
+	return nil
+}
+
+// ValidationErrors is the error returned by Validate for an operation which
+// is not valid against the schema.
+type ValidationErrors []gqlerrors.FormattedError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Selection builds the selections of a field or of an inline fragment.
+type Selection struct {
+	operation    *Operation
+	selectionSet *ast.SelectionSet
+	node         ast.Selection
+}
+
+// Operation returns the operation the selection belongs to.
+func (s *Selection) Operation() *Operation {
+	return s.operation
+}
+
+// Field adds the sub field name and returns it.
+func (s *Selection) Field(name string, opts ...Option) *Selection {
+	fieldName, err := newName(name)
+	if err != nil {
+		s.operation.fail(err)
+	}
+	field := ast.NewField(&ast.Field{
+		Name:       fieldName,
+		Arguments:  []*ast.Argument{},
+		Directives: []*ast.Directive{},
+	})
+	return s.add(field, opts)
+}
+
+// Select adds leaf sub fields.
+func (s *Selection) Select(names ...string) *Selection {
+	for _, name := range names {
+		s.Field(name)
+	}
+	return s
+}
+
+// On adds an inline fragment on the type typeName, or with no type condition
+// if typeName is empty, and returns it.
+func (s *Selection) On(typeName string, opts ...Option) *Selection {
+	fragment := ast.NewInlineFragment(&ast.InlineFragment{
+		Directives: []*ast.Directive{},
+	})
+	if typeName != "" {
+		name, err := newName(typeName)
+		if err != nil {
+			s.operation.fail(err)
+		}
+		fragment.TypeCondition = ast.NewNamed(&ast.Named{Name: name})
+	}
+	return s.add(fragment, opts)
+}
+
+func (s *Selection) add(node ast.Selection, opts []Option) *Selection {
+	for _, opt := range opts {
+		if err := opt.apply(node); err != nil {
+			s.operation.fail(err)
+		}
+	}
+	if s.selectionSet == nil {
+		s.selectionSet = ast.NewSelectionSet(&ast.SelectionSet{})
+		switch parent := s.node.(type) {
+		case *ast.Field:
+			parent.SelectionSet = s.selectionSet
+		case *ast.InlineFragment:
+			parent.SelectionSet = s.selectionSet
+		}
+	}
+	s.selectionSet.Selections = append(s.selectionSet.Selections, node)
+	return &Selection{
+		operation: s.operation,
+		node:      node,
+	}
+}
+
+// Option configures a field or an inline fragment.
+type Option interface {
+	apply(node ast.Selection) error
+}
+
+type optionFunc func(node ast.Selection) error
+
+func (f optionFunc) apply(node ast.Selection) error {
+	return f(node)
+}
+
+// Alias sets the alias of a field.
+func Alias(alias string) Option {
+	return optionFunc(func(node ast.Selection) error {
+		field, ok := node.(*ast.Field)
+		if !ok {
+			return fmt.Errorf("builder: alias %q of an inline fragment", alias)
+		}
+		name, err := newName(alias)
+		field.Alias = name
+		return err
+	})
+}
+
+// Argument is an argument of a field or a directive.
+type Argument struct {
+	name  string
+	value interface{}
+}
+
+// Arg returns the argument name with the value value, which is either an
+// ast.Value, such as the ones returned by Var and Enum, or a Go value: a
+// string, a boolean, a number, a slice of values or a map of values keyed by
+// strings.
+func Arg(name string, value interface{}) Argument {
+	return Argument{name: name, value: value}
+}
+
+func (a Argument) apply(node ast.Selection) error {
+	field, ok := node.(*ast.Field)
+	if !ok {
+		return fmt.Errorf("builder: argument %q of an inline fragment", a.name)
+	}
+	arg, err := a.argument()
+	if err != nil {
+		return err
+	}
+	field.Arguments = append(field.Arguments, arg)
+	return nil
+}
+
+func (a Argument) argument() (*ast.Argument, error) {
+	name, err := newName(a.name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := astValue(a.value)
+	if err != nil {
+		return nil, fmt.Errorf("builder: argument %q: %v", a.name, err)
+	}
+	return ast.NewArgument(&ast.Argument{
+		Name:  name,
+		Value: value,
+	}), nil
+}
+
+// Directive adds the directive name to a field or an inline fragment.
+func Directive(name string, args ...Argument) Option {
+	return optionFunc(func(node ast.Selection) error {
+		directiveName, err := newName(name)
+		if err != nil {
+			return err
+		}
+		directive := ast.NewDirective(&ast.Directive{
+			Name:      directiveName,
+			Arguments: []*ast.Argument{},
+		})
+		for _, a := range args {
+			arg, err := a.argument()
+			if err != nil {
+				return err
+			}
+			directive.Arguments = append(directive.Arguments, arg)
+		}
+		switch node := node.(type) {
+		case *ast.Field:
+			node.Directives = append(node.Directives, directive)
+		case *ast.InlineFragment:
+			node.Directives = append(node.Directives, directive)
+		}
+		return nil
+	})
+}
+
+// Var returns a reference to the variable name, to be used as a value.
+func Var(name string) ast.Value {
+	return ast.NewVariable(&ast.Variable{
+		Name: ast.NewName(&ast.Name{Value: name}),
+	})
+}
+
+// Enum returns the enum value value.
+func Enum(value string) ast.Value {
+	return ast.NewEnumValue(&ast.EnumValue{Value: value})
+}
+
+// astValue converts the Go value to an AST value. See Arg.
+func astValue(value interface{}) (ast.Value, error) {
+	switch value := value.(type) {
+	case *ast.Variable:
+		if _, err := validName(value.Name.Value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case *ast.EnumValue:
+		if _, err := validName(value.Value); err != nil || value.Value == "true" || value.Value == "false" || value.Value == "null" {
+			return nil, fmt.Errorf("invalid enum value %q", value.Value)
+		}
+		return value, nil
+	case ast.Value:
+		return value, nil
+	case string:
+		return ast.NewStringValue(&ast.StringValue{Value: value}), nil
+	case bool:
+		return ast.NewBooleanValue(&ast.BooleanValue{Value: value}), nil
+	case nil:
+		return nil, fmt.Errorf("null values are not supported")
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ast.NewIntValue(&ast.IntValue{Value: strconv.FormatInt(v.Int(), 10)}), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ast.NewIntValue(&ast.IntValue{Value: strconv.FormatUint(v.Uint(), 10)}), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("invalid float %v", f)
+		}
+		return ast.NewFloatValue(&ast.FloatValue{Value: strconv.FormatFloat(f, 'g', -1, 64)}), nil
+	case reflect.Slice, reflect.Array:
+		values := []ast.Value{}
+		for i := 0; i < v.Len(); i++ {
+			item, err := astValue(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, item)
+		}
+		return ast.NewListValue(&ast.ListValue{Values: values}), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %v", v.Type().Key())
+		}
+		keys := []string{}
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		fields := []*ast.ObjectField{}
+		for _, key := range keys {
+			name, err := validName(key)
+			if err != nil {
+				return nil, err
+			}
+			fieldValue, err := astValue(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).Interface())
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, ast.NewObjectField(&ast.ObjectField{
+				Name:  name,
+				Value: fieldValue,
+			}))
+		}
+		return ast.NewObjectValue(&ast.ObjectValue{Fields: fields}), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
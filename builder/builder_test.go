@@ -0,0 +1,167 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/builder"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/testutil"
+)
+
+func userSchema(t *testing.T) graphql.Schema {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.String},
+			"email": &graphql.Field{Type: graphql.String},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"user": &graphql.Field{
+					Type: userType,
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{Name: "id", Type: graphql.NewNonNull(graphql.ID)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return map[string]interface{}{"name": "John", "email": p.Args["id"]}, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("wrong result, unexpected errors: %v", err.Error())
+	}
+	return schema
+}
+
+func TestBuilder_BuildsOperations(t *testing.T) {
+	q := builder.Query("GetUser").Var("id", "ID!").VarDefault("size", "Int", 64)
+	q.Field("user", builder.Arg("id", builder.Var("id"))).Select("name", "email")
+	me := q.Field("user", builder.Alias("me"), builder.Arg("id", "me"),
+		builder.Directive("include", builder.Arg("if", true)))
+	me.Field("avatar", builder.Arg("size", builder.Var("size")), builder.Arg("format", builder.Enum("PNG")))
+	me.On("Admin").Select("role")
+	me.On("", builder.Directive("skip", builder.Arg("if", false))).Select("name")
+
+	expected := `query GetUser($id: ID!, $size: Int = 64) {
+  user(id: $id) {
+    name
+    email
+  }
+  me: user(id: "me") @include(if: true) {
+    avatar(size: $size, format: PNG)
+    ... on Admin {
+      role
+    }
+    ... @skip(if: false) {
+      name
+    }
+  }
+}
+`
+	results, err := q.String()
+	if err != nil {
+		t.Fatalf("String failed: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestBuilder_ConvertsGoValues(t *testing.T) {
+	q := builder.Mutation("")
+	q.Field("update", builder.Arg("input", map[string]interface{}{
+		"name":   "quote \" and backslash \\ and\nnewline",
+		"tags":   []string{"a", "b"},
+		"score":  1.5,
+		"count":  uint8(3),
+		"nested": map[string]interface{}{"ok": true},
+	})).Select("id")
+
+	expected := `mutation {
+  update(input: {count: 3, name: "quote \" and backslash \\ and\nnewline", nested: {ok: true}, score: 1.5, tags: ["a", "b"]}) {
+    id
+  }
+}
+`
+	doc, err := q.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	results := printer.Format(doc, printer.Options{})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// the printed operation parses back to the same document
+	parsed, err := parser.Parse(parser.ParseParams{
+		Source:  results,
+		Options: parser.ParseOptions{NoLocation: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if reprinted := printer.Format(parsed, printer.Options{}); reprinted != results {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(results, reprinted))
+	}
+}
+
+func TestBuilder_ReportsTheFirstError(t *testing.T) {
+	tests := []struct {
+		operation   *builder.Operation
+		expectedErr string
+	}{
+		{builder.Query("Get User"), `builder: invalid name "Get User"`},
+		{builder.Query("").Var("id", "[ID"), `builder: variable "id": invalid type "[ID"`},
+		{builder.Query("").Var("$id", "ID"), `builder: invalid name "$id"`},
+		{builder.Query("").Select("a", "b c", "d{"), `builder: invalid name "b c"`},
+		{builder.Query("").Field("a", builder.Arg("x", nil)).Operation(), `builder: argument "x": null values are not supported`},
+		{builder.Query("").Field("a", builder.Arg("x", map[int]int{})).Operation(), `builder: argument "x": unsupported map key type int`},
+		{builder.Query("").Field("a", builder.Arg("x", builder.Enum("true"))).Operation(), `builder: argument "x": invalid enum value "true"`},
+		{builder.Query("").Field("a", builder.Arg("x", map[string]int{"bad name": 1})).Operation(), `builder: argument "x": invalid name "bad name"`},
+		{builder.Query("").Field("a", builder.Arg("x", builder.Var("bad name"))).Operation(), `builder: argument "x": invalid name "bad name"`},
+		{builder.Query("").VarDefault("v", "Int", []interface{}{builder.Var("$v")}), `builder: variable "v": invalid name "$v"`},
+		{builder.Query("").On("T", builder.Alias("a")).Operation(), `builder: alias "a" of an inline fragment`},
+	}
+	for _, test := range tests {
+		_, err := test.operation.Document()
+		if err == nil || err.Error() != test.expectedErr {
+			t.Fatalf("Unexpected error, expected: %v, got: %v", test.expectedErr, err)
+		}
+	}
+}
+
+func TestBuilder_ValidatesAgainstTheSchema(t *testing.T) {
+	schema := userSchema(t)
+
+	q := builder.Query("GetUser").Var("id", "ID!")
+	q.Field("user", builder.Arg("id", builder.Var("id"))).Select("name", "email")
+	if err := q.Validate(&schema); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	query, _ := q.String()
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  query,
+		VariableValues: map[string]interface{}{"id": "john@example.com"},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Unexpected errors: %v", result.Errors)
+	}
+
+	q = builder.Query("")
+	q.Field("user").Select("name", "phone")
+	expected := `Cannot query field "phone" on type "User".` + "\n" +
+		`Field "user" argument "id" of type "ID!" is required but not provided.`
+	err := q.Validate(&schema)
+	if _, ok := err.(builder.ValidationErrors); !ok || err.Error() != expected {
+		t.Fatalf("Unexpected error, expected: %v, got: %v", expected, err)
+	}
+}
@@ -22,6 +22,18 @@ type ExecuteParams struct {
 	// Context may be provided to pass application-specific per-request
 	// information to resolve functions.
 	Context context.Context
+
+	// FieldResolver is used to resolve the fields that have no Resolve
+	// function. DefaultResolveFn is used when nil.
+	FieldResolver FieldResolveFn
+
+	// FieldSubscriber is used by ExecuteSubscription to subscribe to the
+	// root field when it has no Subscribe function.
+	FieldSubscriber FieldResolveFn
+
+	// Backpressure configures the buffering of the results of
+	// ExecuteSubscription.
+	Backpressure Backpressure
 }
 
 func Execute(p ExecuteParams) (result *Result) {
@@ -67,6 +79,7 @@ func Execute(p ExecuteParams) (result *Result) {
 			Args:          p.Args,
 			Result:        result,
 			Context:       p.Context,
+			FieldResolver: p.FieldResolver,
 		})
 
 		if err != nil {
@@ -100,6 +113,7 @@ type buildExecutionCtxParams struct {
 	Args          map[string]interface{}
 	Result        *Result
 	Context       context.Context
+	FieldResolver FieldResolveFn
 }
 
 type executionContext struct {
@@ -110,6 +124,7 @@ type executionContext struct {
 	VariableValues map[string]interface{}
 	Errors         []gqlerrors.FormattedError
 	Context        context.Context
+	FieldResolver  FieldResolveFn
 }
 
 func buildExecutionContext(p buildExecutionCtxParams) (*executionContext, error) {
@@ -155,6 +170,7 @@ func buildExecutionContext(p buildExecutionCtxParams) (*executionContext, error)
 	eCtx.Operation = operation
 	eCtx.VariableValues = variableValues
 	eCtx.Context = p.Context
+	eCtx.FieldResolver = p.FieldResolver
 	return eCtx, nil
 }
 
@@ -615,8 +631,11 @@ func resolveField(eCtx *executionContext, parentType *Object, source interface{}
 		resultState.hasNoFieldDefs = true
 		return nil, resultState
 	}
-	returnType = fieldDef.Type
+	returnType = nullabilityType(fieldDef.Type, fieldAST.Nullability)
 	resolveFn := fieldDef.Resolve
+	if resolveFn == nil {
+		resolveFn = eCtx.FieldResolver
+	}
 	if resolveFn == nil {
 		resolveFn = DefaultResolveFn
 	}
@@ -630,7 +649,7 @@ func resolveField(eCtx *executionContext, parentType *Object, source interface{}
 		FieldName:      fieldName,
 		FieldASTs:      fieldASTs,
 		Path:           path,
-		ReturnType:     returnType,
+		ReturnType:     fieldDef.Type,
 		ParentType:     parentType,
 		Schema:         eCtx.Schema,
 		Fragments:      eCtx.Fragments,
@@ -666,6 +685,47 @@ func resolveField(eCtx *executionContext, parentType *Object, source interface{}
 	return completed, resultState
 }
 
+// nullabilityType returns the type of a field modified by its client
+// controlled nullability designator: `!` makes the type non-null, so that a
+// null propagates to the parent field, and `?` nullable, so that errors stop
+// at the field. Brackets apply the designators to the items of lists, and are
+// ignored when the type has less list dimensions.
+func nullabilityType(ttype Type, nullability ast.Nullability) Type {
+	switch nullability := nullability.(type) {
+	case *ast.RequiredDesignator:
+		if nullability.Element != nil {
+			ttype = nullabilityType(ttype, nullability.Element)
+		}
+		if _, ok := ttype.(*NonNull); ok {
+			return ttype
+		}
+		return NewNonNull(ttype)
+	case *ast.OptionalDesignator:
+		if nullability.Element != nil {
+			ttype = nullabilityType(ttype, nullability.Element)
+		}
+		if nonNull, ok := ttype.(*NonNull); ok {
+			return nonNull.OfType
+		}
+		return ttype
+	case *ast.ListNullability:
+		nonNull, isNonNull := ttype.(*NonNull)
+		list, ok := ttype.(*List)
+		if isNonNull {
+			list, ok = nonNull.OfType.(*List)
+		}
+		if !ok {
+			return ttype
+		}
+		var modified Type = NewList(nullabilityType(list.OfType, nullability.Element))
+		if isNonNull {
+			modified = NewNonNull(modified)
+		}
+		return modified
+	}
+	return ttype
+}
+
 func completeValueCatchingError(eCtx *executionContext, returnType Type, fieldASTs []*ast.Field, info ResolveInfo, path *ResponsePath, result interface{}) (completed interface{}) {
 	// catch panic
 	defer func() interface{} {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/dagger/graphql"
@@ -469,3 +470,131 @@ func (t *testExt) ExecutionDidStart(ctx context.Context) (context.Context, graph
 func (t *testExt) ResolveFieldDidStart(ctx context.Context, i *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
 	return t.resolveFieldDidStartFn(ctx, i)
 }
+
+func TestExtensionsSubscription(t *testing.T) {
+	calls := map[string]int{}
+	var mu sync.Mutex
+	called := func(hook string) {
+		mu.Lock()
+		calls[hook]++
+		mu.Unlock()
+	}
+
+	ext := newtestExt("testExt")
+	ext.initFn = func(ctx context.Context, p *graphql.Params) context.Context {
+		called("Init")
+		return ctx
+	}
+	ext.parseDidStartFn = func(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+		called("ParseDidStart")
+		return ctx, func(err error) {}
+	}
+	ext.validationDidStartFn = func(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+		called("ValidationDidStart")
+		return ctx, func([]gqlerrors.FormattedError) {}
+	}
+	ext.executionDidStartFn = func(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+		called("ExecutionDidStart")
+		return ctx, func(r *graphql.Result) {}
+	}
+	ext.resolveFieldDidStartFn = func(ctx context.Context, i *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+		called("ResolveFieldDidStart")
+		return ctx, func(v interface{}, err error) {}
+	}
+	ext.hasResultFn = func() bool {
+		return true
+	}
+	ext.getResultFn = func(context.Context) interface{} {
+		return "ok"
+	}
+
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"sub": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: makeSubscribeToStringFunction([]string{"a", "b"}),
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+
+	results := []*graphql.Result{}
+	for result := range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { sub }`,
+		Context:       context.Background(),
+	}) {
+		results = append(results, result)
+	}
+
+	expected := []*graphql.Result{
+		{
+			Data:       map[string]interface{}{"sub": "a"},
+			Extensions: map[string]interface{}{"testExt": "ok"},
+		},
+		{
+			Data:       map[string]interface{}{"sub": "b"},
+			Extensions: map[string]interface{}{"testExt": "ok"},
+		},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	expectedCalls := map[string]int{
+		"Init":               1,
+		"ParseDidStart":      1,
+		"ValidationDidStart": 1,
+		"ExecutionDidStart":  2,
+		// the subscribe function, and the resolver for each event
+		"ResolveFieldDidStart": 3,
+	}
+	if !reflect.DeepEqual(expectedCalls, calls) {
+		t.Fatalf("Unexpected calls, Diff: %v", testutil.Diff(expectedCalls, calls))
+	}
+}
+
+func TestExtensionsSubscriptionValidationDidStartPanic(t *testing.T) {
+	ext := newtestExt("testExt")
+	ext.validationDidStartFn = func(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+		if true {
+			panic(errors.New("test error"))
+		}
+		return ctx, func([]gqlerrors.FormattedError) {}
+	}
+
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"sub": &graphql.Field{
+				Type:      graphql.String,
+				Subscribe: makeSubscribeToStringFunction([]string{"a"}),
+			},
+		},
+	})
+	schema.AddExtensions(ext)
+
+	results := []*graphql.Result{}
+	for result := range graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { sub }`,
+		Context:       context.Background(),
+	}) {
+		results = append(results, result)
+	}
+
+	expected := []*graphql.Result{
+		{
+			Errors: []gqlerrors.FormattedError{
+				gqlerrors.FormatError(fmt.Errorf("%s.ValidationDidStart: %v", ext.Name(), errors.New("test error"))),
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
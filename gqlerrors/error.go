@@ -18,6 +18,10 @@ type Error struct {
 	Locations     []location.SourceLocation
 	OriginalError error
 	Path          []interface{}
+
+	// Sources holds the source of each of Locations, which differ when the
+	// nodes of the error were parsed from several sources.
+	Sources []*source.Source
 }
 
 // implements Golang's built-in `error` interface
@@ -25,6 +29,11 @@ func (g Error) Error() string {
 	return fmt.Sprintf("%v", g.Message)
 }
 
+// Unwrap returns the original error, for errors.Is and errors.As.
+func (g Error) Unwrap() error {
+	return g.OriginalError
+}
+
 func NewError(message string, nodes []ast.Node, stack string, source *source.Source, positions []int, origError error) *Error {
 	return newError(message, nodes, stack, source, positions, nil, origError)
 }
@@ -33,22 +42,24 @@ func NewErrorWithPath(message string, nodes []ast.Node, stack string, source *so
 	return newError(message, nodes, stack, source, positions, path, origError)
 }
 
-func newError(message string, nodes []ast.Node, stack string, source *source.Source, positions []int, path []interface{}, origError error) *Error {
+func newError(message string, nodes []ast.Node, stack string, src *source.Source, positions []int, path []interface{}, origError error) *Error {
 	if stack == "" && message != "" {
 		stack = message
 	}
-	if source == nil {
+	if src == nil {
 		for _, node := range nodes {
 			// get source from first node
 			if node == nil || reflect.ValueOf(node).IsNil() {
 				continue
 			}
 			if node.GetLoc() != nil {
-				source = node.GetLoc().Source
+				src = node.GetLoc().Source
 			}
 			break
 		}
 	}
+	// sources holds the source of each position
+	sources := []*source.Source{}
 	if len(positions) == 0 && len(nodes) > 0 {
 		for _, node := range nodes {
 			if node == nil || reflect.ValueOf(node).IsNil() {
@@ -58,20 +69,30 @@ func newError(message string, nodes []ast.Node, stack string, source *source.Sou
 				continue
 			}
 			positions = append(positions, node.GetLoc().Start)
+			if s := node.GetLoc().Source; s != nil {
+				sources = append(sources, s)
+			} else {
+				sources = append(sources, src)
+			}
+		}
+	} else {
+		for range positions {
+			sources = append(sources, src)
 		}
 	}
 	locations := []location.SourceLocation{}
-	for _, pos := range positions {
-		loc := location.GetLocation(source, pos)
+	for i, pos := range positions {
+		loc := location.GetLocation(sources[i], pos)
 		locations = append(locations, loc)
 	}
 	return &Error{
 		Message:       message,
 		Stack:         stack,
 		Nodes:         nodes,
-		Source:        source,
+		Source:        src,
 		Positions:     positions,
 		Locations:     locations,
+		Sources:       sources,
 		OriginalError: origError,
 		Path:          path,
 	}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/dagger/graphql/language/location"
+	"github.com/dagger/graphql/language/source"
 )
 
 type ExtendedError interface {
@@ -17,6 +18,12 @@ type FormattedError struct {
 	Path          []interface{}             `json:"path,omitempty"`
 	Extensions    map[string]interface{}    `json:"extensions,omitempty"`
 	originalError error
+
+	// Sources holds the name of the source of each of Locations, such as the
+	// file a schema definition was parsed from. It is not part of the JSON
+	// encoding, which follows the GraphQL specification: clients only get the
+	// source names included in Message, or in Extensions.
+	Sources []string `json:"-"`
 }
 
 func (g FormattedError) OriginalError() error {
@@ -41,6 +48,7 @@ func FormatError(err error) FormattedError {
 			Message:       err.Error(),
 			Locations:     err.Locations,
 			Path:          err.Path,
+			Sources:       sourceNames(err.Sources),
 			originalError: err,
 		}
 		if err := err.OriginalError; err != nil {
@@ -60,6 +68,20 @@ func FormatError(err error) FormattedError {
 	}
 }
 
+// sourceNames returns the names of sources, or nil if there is none.
+func sourceNames(sources []*source.Source) []string {
+	if len(sources) == 0 {
+		return nil
+	}
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		if s != nil {
+			names[i] = s.Name
+		}
+	}
+	return names
+}
+
 func FormatErrors(errs ...error) []FormattedError {
 	formattedErrors := []FormattedError{}
 	for _, err := range errs {
@@ -22,6 +22,34 @@ func NewSyntaxError(s *source.Source, position int, description string) *Error {
 	)
 }
 
+// LimitError is the original error of the syntax errors returned when a
+// document exceeds a limit set in the parse options.
+type LimitError struct {
+	// Limit is the name of the exceeded limit, e.g. "MaxDepth".
+	Limit string
+	// Max is the value of the limit.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Document exceeds %s of %d.", e.Limit, e.Max)
+}
+
+// NewLimitError returns a syntax error reporting that the limit named limit,
+// of value max, is exceeded at position.
+func NewLimitError(s *source.Source, position int, limit string, max int) *Error {
+	limitErr := &LimitError{Limit: limit, Max: max}
+	l := location.GetLocation(s, position)
+	return NewError(
+		fmt.Sprintf("Syntax Error %s (%d:%d) %s\n\n%s", s.Name, l.Line, l.Column, limitErr.Error(), highlightSourceAtLocation(s, l)),
+		[]ast.Node{},
+		"",
+		s,
+		[]int{position},
+		limitErr,
+	)
+}
+
 // printCharCode here is slightly different from lexer.printCharCode()
 func printCharCode(code rune) string {
 	// print as ASCII for printable range
@@ -38,6 +66,9 @@ func printLine(str string) string {
 	}
 	return fmt.Sprintf(`%s`, strings.Join(strSlice, ""))
 }
+
+// highlightSourceAtLocation prints the lines of s around l, preceded by
+// "name:line:column" when s is named, such as after the file it was read from.
 func highlightSourceAtLocation(s *source.Source, l location.SourceLocation) string {
 	line := l.Line
 	prevLineNum := fmt.Sprintf("%d", (line - 1))
@@ -46,6 +77,9 @@ func highlightSourceAtLocation(s *source.Source, l location.SourceLocation) stri
 	padLen := len(nextLineNum)
 	lines := regexp.MustCompile("\r\n|[\n\r]").Split(string(s.Body), -1)
 	var highlight string
+	if s.Name != "" && s.Name != source.DefaultName {
+		highlight += fmt.Sprintf("%s:%d:%d\n", s.Name, l.Line, l.Column)
+	}
 	if line >= 2 {
 		highlight += fmt.Sprintf("%s: %s\n", lpad(padLen, prevLineNum), printLine(lines[line-2]))
 	}
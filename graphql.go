@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
 	"github.com/dagger/graphql/language/parser"
 	"github.com/dagger/graphql/language/source"
 )
@@ -31,38 +32,73 @@ type Params struct {
 	// Context may be provided to pass application-specific per-request
 	// information to resolve functions.
 	Context context.Context
+
+	// OperationRegistry, if set, restricts the operations that may be
+	// executed to the ones it contains.
+	OperationRegistry *OperationRegistry
+
+	// Backpressure configures the buffering of the results of Subscribe.
+	Backpressure Backpressure
+
+	// ParseOptions are used to parse the request, e.g. to limit the size of
+	// the documents accepted.
+	ParseOptions parser.ParseOptions
 }
 
 func Do(p Params) *Result {
+	AST, result := parseAndValidate(&p)
+	if result != nil {
+		return result
+	}
+
+	return Execute(ExecuteParams{
+		Schema:        p.Schema,
+		Root:          p.RootObject,
+		AST:           AST,
+		OperationName: p.OperationName,
+		Args:          p.VariableValues,
+		Context:       p.Context,
+	})
+}
+
+// parseAndValidate runs the extensions' init, parse and validation hooks
+// around the parsing and the validation of the request. It returns the
+// result to send back when the request cannot be executed.
+func parseAndValidate(p *Params) (*ast.Document, *Result) {
 	source := source.NewSource(&source.Source{
 		Body: []byte(p.RequestString),
 		Name: "GraphQL request",
 	})
 
 	// run init on the extensions
-	extErrs := handleExtensionsInits(&p)
+	extErrs := handleExtensionsInits(p)
 	if len(extErrs) != 0 {
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
 
-	extErrs, parseFinishFn := handleExtensionsParseDidStart(&p)
+	extErrs, parseFinishFn := handleExtensionsParseDidStart(p)
 	if len(extErrs) != 0 {
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
 
 	// parse the source
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
+	AST, err := parser.Parse(parser.ParseParams{Source: source, Options: p.ParseOptions})
 	if err != nil {
 		// run parseFinishFuncs for extensions
 		extErrs = parseFinishFn(err)
 
-		// merge the errors from extensions and the original error from parser
-		extErrs = append(extErrs, gqlerrors.FormatErrors(err)...)
-		return &Result{
+		// merge the errors from extensions and the original errors from parser,
+		// each of the errors recovered from being reported on its own
+		errs := []error{err}
+		if syntaxErrs, ok := err.(parser.SyntaxErrors); ok {
+			errs = syntaxErrs
+		}
+		extErrs = append(extErrs, gqlerrors.FormatErrors(errs...)...)
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
@@ -70,15 +106,22 @@ func Do(p Params) *Result {
 	// run parseFinish functions for extensions
 	extErrs = parseFinishFn(err)
 	if len(extErrs) != 0 {
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
 
+	// reject operations that are not in the registry
+	if errs := checkOperationRegistry(p, AST); len(errs) != 0 {
+		return nil, &Result{
+			Errors: errs,
+		}
+	}
+
 	// notify extensions about the start of the validation
-	extErrs, validationFinishFn := handleExtensionsValidationDidStart(&p)
+	extErrs, validationFinishFn := handleExtensionsValidationDidStart(p)
 	if len(extErrs) != 0 {
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
@@ -92,7 +135,7 @@ func Do(p Params) *Result {
 
 		// merge the errors from extensions and the original error from parser
 		extErrs = append(extErrs, validationResult.Errors...)
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
@@ -100,17 +143,10 @@ func Do(p Params) *Result {
 	// run the validationFinishFuncs for extensions
 	extErrs = validationFinishFn(validationResult.Errors)
 	if len(extErrs) != 0 {
-		return &Result{
+		return nil, &Result{
 			Errors: extErrs,
 		}
 	}
 
-	return Execute(ExecuteParams{
-		Schema:        p.Schema,
-		Root:          p.RootObject,
-		AST:           AST,
-		OperationName: p.OperationName,
-		Args:          p.VariableValues,
-		Context:       p.Context,
-	})
+	return AST, nil
 }
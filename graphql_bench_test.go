@@ -5,6 +5,7 @@ import (
 
 	"github.com/dagger/graphql"
 	"github.com/dagger/graphql/benchutil"
+	"github.com/dagger/graphql/language/parser"
 )
 
 type B struct {
@@ -122,3 +123,20 @@ func nFieldsyItemsQueryBenchmark(x int, y int) func(b *testing.B) {
 		}
 	}
 }
+
+// Benchmark the validation of a wide query, without executing it.
+func BenchmarkValidateDocument_WideQuery_1K(b *testing.B) {
+	schema := benchutil.WideSchemaWithXFieldsAndYItems(1000, 1)
+	astDoc, err := parser.Parse(parser.ParseParams{Source: benchutil.WideSchemaQuery(1000)})
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if result := graphql.ValidateDocument(&schema, astDoc, nil); !result.IsValid {
+			b.Fatalf("wrong result, unexpected errors: %v", result.Errors)
+		}
+	}
+}
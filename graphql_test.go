@@ -2,10 +2,15 @@ package graphql_test
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/location"
+	"github.com/dagger/graphql/language/parser"
 	"github.com/dagger/graphql/testutil"
 )
 
@@ -268,3 +273,41 @@ func TestEmptyStringIsNotNull(t *testing.T) {
 		t.Errorf("wrong result, query: %v, graphql result diff: %v", query, testutil.Diff(expected, result))
 	}
 }
+
+func TestDoEnforcesParseLimits(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        testutil.StarWarsSchema,
+		RequestString: `{ hero { friends { friends { name } } } }`,
+		ParseOptions:  parser.ParseOptions{MaxDepth: 3},
+	})
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "Document exceeds MaxDepth of 3.") {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	var limitErr *gqlerrors.LimitError
+	if !errors.As(result.Errors[0].OriginalError(), &limitErr) {
+		t.Fatalf("expected a LimitError, got %#v", result.Errors[0].OriginalError())
+	}
+}
+
+func TestDoReportsEachRecoveredSyntaxError(t *testing.T) {
+	result := graphql.Do(graphql.Params{
+		Schema:        testutil.StarWarsSchema,
+		RequestString: `{ a( } { b( }`,
+		ParseOptions:  parser.ParseOptions{RecoverErrors: true},
+	})
+	expectedLocations := [][]location.SourceLocation{
+		{{Line: 1, Column: 6}},
+		{{Line: 1, Column: 13}},
+	}
+	if len(result.Errors) != len(expectedLocations) {
+		t.Fatalf("expected %d errors, got %v", len(expectedLocations), result.Errors)
+	}
+	for i, err := range result.Errors {
+		if !strings.HasPrefix(err.Message, "Syntax Error GraphQL request") || strings.Count(err.Message, "Syntax Error") != 1 {
+			t.Fatalf("unexpected message: %v", err.Message)
+		}
+		if !reflect.DeepEqual(err.Locations, expectedLocations[i]) {
+			t.Fatalf("unexpected locations of %v: %v", err.Message, err.Locations)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+)
+
+// serveBatch executes a batch of operations and writes their results as a
+// JSON array, in the order of the requests. Operations run concurrently, at
+// most h.batchConcurrency at a time, so mutations sent in the same batch are
+// not ordered with respect to each other.
+func (h *Handler) serveBatch(ctx context.Context, w http.ResponseWriter, r *http.Request, contentType string, batch []*RequestOptions) {
+	if h.maxBatchSize == 0 {
+		writeRequestError(w, contentType, newRequestError(http.StatusBadRequest, "Batched requests are not supported."))
+		return
+	}
+	if len(batch) > h.maxBatchSize {
+		writeRequestError(w, contentType, newRequestError(http.StatusRequestEntityTooLarge,
+			"Batch of %d operations exceeds the maximum of %d.", len(batch), h.maxBatchSize))
+		return
+	}
+
+	concurrency := h.batchConcurrency
+	if concurrency <= 0 || concurrency > len(batch) {
+		concurrency = len(batch)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	results := make([]*graphql.Result, len(batch))
+	wg := sync.WaitGroup{}
+	for i, opts := range batch {
+		if opts.Query == "" {
+			results[i] = &graphql.Result{
+				Errors: gqlerrors.FormatErrors(gqlerrors.NewFormattedError("Must provide query string.")),
+			}
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, opts *RequestOptions) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = h.execute(ctx, r, opts)
+		}(i, opts)
+	}
+	wg.Wait()
+
+	writeJSON(w, contentType, http.StatusOK, results)
+}
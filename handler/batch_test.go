@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/handler"
+	"github.com/dagger/graphql/testutil"
+)
+
+func serveBatch(t *testing.T, h http.Handler, body string) (int, []interface{}) {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var results []interface{}
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code, results
+}
+
+func TestHandler_Batch(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema, MaxBatchSize: 10})
+	body := `[
+		{"query": "query Hello($name: String) { hello(name: $name) }", "variables": {"name": "first"}},
+		{"query": "query A { hello } query B { touch: hello(name: \"B\") }", "operationName": "B"},
+		{"query": "mutation { touch }"},
+		{"query": ""}
+	]`
+	code, results := serveBatch(t, h, body)
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"data": map[string]interface{}{"hello": "hello first"}},
+		map[string]interface{}{"data": map[string]interface{}{"touch": "hello B"}},
+		map[string]interface{}{"data": map[string]interface{}{"touch": true}},
+		map[string]interface{}{
+			"data": nil,
+			"errors": []interface{}{
+				map[string]interface{}{"message": "Must provide query string.", "locations": []interface{}{}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected results, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestHandler_BatchDisabled(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	if code, _ := serveBatch(t, h, `[{"query": "{ hello }"}]`); code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", code)
+	}
+}
+
+func TestHandler_BatchTooLarge(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema, MaxBatchSize: 2})
+	body := `[{"query": "{ hello }"}, {"query": "{ hello }"}, {"query": "{ hello }"}]`
+	if code, _ := serveBatch(t, h, body); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", code)
+	}
+}
+
+func TestHandler_BatchInvalid(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema, MaxBatchSize: 2})
+	for _, body := range []string{`[]`, `[1]`, `[null]`, `[{"query": "{ hello }"}`} {
+		if code, _ := serveBatch(t, h, body); code != http.StatusBadRequest {
+			t.Fatalf("expected status 400 for %s, got %d", body, code)
+		}
+	}
+}
+
+func TestHandler_BatchConcurrency(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		running       int
+		maxConcurrent int
+	)
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"slow": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						mu.Lock()
+						running++
+						if running > maxConcurrent {
+							maxConcurrent = running
+						}
+						mu.Unlock()
+
+						time.Sleep(10 * time.Millisecond)
+
+						mu.Lock()
+						running--
+						mu.Unlock()
+						return true, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := handler.New(&handler.Config{Schema: &schema, MaxBatchSize: 10, BatchConcurrency: 2})
+	body := "[" + strings.TrimSuffix(strings.Repeat(`{"query": "{ slow }"},`, 6), ",") + "]"
+	code, results := serveBatch(t, h, body)
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+	if maxConcurrent > 2 {
+		t.Fatalf("expected at most 2 concurrent operations, got %d", maxConcurrent)
+	}
+}
@@ -0,0 +1,443 @@
+// Package handler provides an http.Handler serving a graphql.Schema
+// following the GraphQL-over-HTTP specification.
+//
+// Errors are encoded as specified by GraphQL: the name of the source of their
+// locations, gqlerrors.FormattedError.Sources, is not sent to clients.
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+)
+
+const (
+	// ContentTypeJSON is the legacy media type of GraphQL responses.
+	ContentTypeJSON = "application/json"
+	// ContentTypeGraphQL is the media type of a POST body holding a raw query.
+	ContentTypeGraphQL = "application/graphql"
+	// ContentTypeFormURLEncoded is the media type of form-encoded POST bodies.
+	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
+	// ContentTypeGraphQLResponse is the media type of GraphQL responses
+	// defined by the GraphQL-over-HTTP specification.
+	ContentTypeGraphQLResponse = "application/graphql-response+json"
+)
+
+// DefaultMaxBodySize is the maximum size of a request body read by a Handler
+// when Config.MaxBodySize is not set.
+const DefaultMaxBodySize = 1 << 20
+
+// RequestOptions holds the parameters of a GraphQL request.
+type RequestOptions struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// RequestError is returned by NewRequestOptions when the HTTP request is not
+// a well-formed GraphQL request. StatusCode is the status to respond with.
+type RequestError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+func newRequestError(statusCode int, format string, args ...interface{}) *RequestError {
+	return &RequestError{
+		StatusCode: statusCode,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+// ContextFn returns the context used to execute the request.
+type ContextFn func(r *http.Request) context.Context
+
+// RootObjectFn returns the root object used to execute the request.
+type RootObjectFn func(ctx context.Context, r *http.Request) map[string]interface{}
+
+// Config configures a Handler.
+type Config struct {
+	Schema *graphql.Schema
+
+	// ContextFn, if set, provides the context of each request. The request's
+	// own context is used otherwise.
+	ContextFn ContextFn
+
+	// RootObjectFn, if set, provides the root object of each request.
+	RootObjectFn RootObjectFn
+
+	// MaxBodySize limits the size of request bodies. DefaultMaxBodySize is
+	// used when zero.
+	MaxBodySize int64
+
+	// MaxUploadSize limits the size of multipart/form-data request bodies,
+	// used to upload files. Multipart requests are rejected when zero.
+	//
+	// multipart/form-data requests do not trigger a CORS preflight; servers
+	// accepting them from browsers should protect against CSRF, e.g. by
+	// requiring a custom header in ContextFn or a wrapping middleware.
+	MaxUploadSize int64
+
+	// MaxBatchSize is the maximum number of operations accepted in a batched
+	// request. Batching is disabled when zero.
+	MaxBatchSize int
+
+	// BatchConcurrency limits the number of operations of a batch executed
+	// concurrently. All the operations of a batch run concurrently when zero.
+	BatchConcurrency int
+
+	// ParseOptions are used to parse the requests, e.g. to limit the size of
+	// the documents accepted.
+	ParseOptions parser.ParseOptions
+}
+
+// Handler is an http.Handler executing GraphQL requests against a schema.
+type Handler struct {
+	Schema       *graphql.Schema
+	contextFn    ContextFn
+	rootObjectFn RootObjectFn
+	maxBodySize  int64
+
+	maxUploadSize    int64
+	maxBatchSize     int
+	batchConcurrency int
+
+	parseOptions parser.ParseOptions
+}
+
+// New returns a Handler for the given config.
+func New(p *Config) *Handler {
+	if p == nil {
+		p = &Config{}
+	}
+	if p.Schema == nil {
+		panic("undefined GraphQL schema")
+	}
+	maxBodySize := p.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	return &Handler{
+		Schema:       p.Schema,
+		contextFn:    p.ContextFn,
+		rootObjectFn: p.RootObjectFn,
+		maxBodySize:  maxBodySize,
+
+		maxUploadSize:    p.MaxUploadSize,
+		maxBatchSize:     p.MaxBatchSize,
+		batchConcurrency: p.BatchConcurrency,
+
+		parseOptions: p.ParseOptions,
+	}
+}
+
+// NewRequestOptions decodes the GraphQL request carried by r.
+//
+// GET requests carry the request in the `query`, `variables` and
+// `operationName` query parameters. POST requests carry it in a body of type
+// application/json, application/graphql (the query only, other parameters
+// being read from the URL), application/x-www-form-urlencoded or
+// multipart/form-data, following the GraphQL multipart request specification.
+func NewRequestOptions(r *http.Request) (*RequestOptions, error) {
+	opts, isBatch, err := NewBatchRequestOptions(r)
+	if err != nil {
+		return nil, err
+	}
+	if isBatch {
+		return nil, newRequestError(http.StatusBadRequest, "Batched requests are not supported.")
+	}
+	return opts[0], nil
+}
+
+// NewBatchRequestOptions decodes the GraphQL requests carried by r. It
+// accepts the same requests as NewRequestOptions, as well as application/json
+// POST bodies holding an array of requests, in which case isBatch is true.
+func NewBatchRequestOptions(r *http.Request) (opts []*RequestOptions, isBatch bool, err error) {
+	switch r.Method {
+	case http.MethodGet:
+		opt, err := requestOptionsFromValues(r.URL.Query())
+		if err != nil {
+			return nil, false, err
+		}
+		return []*RequestOptions{opt}, false, nil
+	case http.MethodPost:
+		return requestOptionsFromBody(r)
+	default:
+		return nil, false, newRequestError(http.StatusMethodNotAllowed, "GraphQL only supports GET and POST requests.")
+	}
+}
+
+func requestOptionsFromValues(values url.Values) (*RequestOptions, error) {
+	opts := &RequestOptions{
+		Query:         values.Get("query"),
+		OperationName: values.Get("operationName"),
+	}
+	if variables := values.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &opts.Variables); err != nil {
+			return nil, newRequestError(http.StatusBadRequest, "Variables are invalid JSON.")
+		}
+	}
+	return opts, nil
+}
+
+func requestOptionsFromBody(r *http.Request) ([]*RequestOptions, bool, error) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, false, newRequestError(http.StatusUnsupportedMediaType, "Invalid Content-Type header.")
+	}
+
+	switch contentType {
+	case ContentTypeJSON:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, false, newRequestError(http.StatusBadRequest, "Could not read POST body: %v", err)
+		}
+		return requestOptionsFromJSON(body)
+	case ContentTypeGraphQL:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, false, newRequestError(http.StatusBadRequest, "Could not read POST body: %v", err)
+		}
+		opts, err := requestOptionsFromValues(r.URL.Query())
+		if err != nil {
+			return nil, false, err
+		}
+		opts.Query = string(body)
+		return []*RequestOptions{opts}, false, nil
+	case ContentTypeFormURLEncoded:
+		if err := r.ParseForm(); err != nil {
+			return nil, false, newRequestError(http.StatusBadRequest, "Could not parse form: %v", err)
+		}
+		opts, err := requestOptionsFromValues(r.PostForm)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*RequestOptions{opts}, false, nil
+	case ContentTypeMultipartFormData:
+		return requestOptionsFromMultipart(r)
+	default:
+		return nil, false, newRequestError(http.StatusUnsupportedMediaType, "Unsupported Content-Type %q.", contentType)
+	}
+}
+
+// requestOptionsFromJSON decodes a JSON body holding either a single request
+// object or an array of them.
+func requestOptionsFromJSON(body []byte) ([]*RequestOptions, bool, error) {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		opts := []*RequestOptions{}
+		if err := json.Unmarshal(trimmed, &opts); err != nil {
+			return nil, true, newRequestError(http.StatusBadRequest, "POST body sent invalid JSON.")
+		}
+		if len(opts) == 0 {
+			return nil, true, newRequestError(http.StatusBadRequest, "Received an empty list of requests.")
+		}
+		for i, opt := range opts {
+			if opt == nil {
+				return nil, true, newRequestError(http.StatusBadRequest, "Request %d of the batch is not an object.", i)
+			}
+		}
+		return opts, true, nil
+	}
+	opts := &RequestOptions{}
+	if err := json.Unmarshal(body, opts); err != nil {
+		return nil, false, newRequestError(http.StatusBadRequest, "POST body sent invalid JSON.")
+	}
+	return []*RequestOptions{opts}, false, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.contextFn != nil {
+		ctx = h.contextFn(r)
+	}
+	h.ContextHandler(ctx, w, r)
+}
+
+// ContextHandler serves r using ctx as the execution context.
+func (h *Handler) ContextHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	contentType, ok := negotiateContentType(r)
+	if !ok {
+		writeRequestError(w, ContentTypeJSON, newRequestError(http.StatusNotAcceptable,
+			"Only %s and %s responses are supported.", ContentTypeGraphQLResponse, ContentTypeJSON))
+		return
+	}
+
+	batch, isBatch, release, err := readRequests(w, r, h.maxBodySize, h.maxUploadSize)
+	defer release()
+	if err != nil {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+		}
+		writeRequestError(w, contentType, err)
+		return
+	}
+	if isBatch {
+		h.serveBatch(ctx, w, r, contentType, batch)
+		return
+	}
+	opts := batch[0]
+	if opts.Query == "" {
+		writeRequestError(w, contentType, newRequestError(http.StatusBadRequest, "Must provide query string."))
+		return
+	}
+	if r.Method == http.MethodGet && operationType(opts, h.parseOptions) == ast.OperationTypeMutation {
+		w.Header().Set("Allow", http.MethodPost)
+		writeRequestError(w, contentType, newRequestError(http.StatusMethodNotAllowed,
+			"Can only perform a mutation operation from a POST request."))
+		return
+	}
+
+	result := h.execute(ctx, r, opts)
+	writeResult(w, contentType, result)
+}
+
+func (h *Handler) execute(ctx context.Context, r *http.Request, opts *RequestOptions) *graphql.Result {
+	params := graphql.Params{
+		Schema:         *h.Schema,
+		RequestString:  opts.Query,
+		VariableValues: opts.Variables,
+		OperationName:  opts.OperationName,
+		Context:        ctx,
+		ParseOptions:   h.parseOptions,
+	}
+	if h.rootObjectFn != nil {
+		params.RootObject = h.rootObjectFn(ctx, r)
+	}
+	return graphql.Do(params)
+}
+
+// readRequests decodes the requests carried by r like NewBatchRequestOptions,
+// limiting the size of its body to maxBodySize, or to maxUploadSize for
+// multipart/form-data requests, which are rejected when maxUploadSize is zero.
+// release closes the uploaded files and removes their temporary copies; it
+// must be called once the requests are served, even on errors.
+func readRequests(w http.ResponseWriter, r *http.Request, maxBodySize, maxUploadSize int64) (batch []*RequestOptions, isBatch bool, release func(), err error) {
+	release = func() {}
+	if isMultipart(r) {
+		if maxUploadSize == 0 {
+			return nil, false, release, newRequestError(http.StatusUnsupportedMediaType, "File uploads are not supported.")
+		}
+		maxBodySize = maxUploadSize
+	}
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	}
+	batch, isBatch, err = NewBatchRequestOptions(r)
+	release = func() {
+		closeUploads(batch)
+		if r.MultipartForm != nil {
+			r.MultipartForm.RemoveAll()
+		}
+	}
+	return batch, isBatch, release, err
+}
+
+// isMultipart reports whether r is a multipart/form-data POST request.
+func isMultipart(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return contentType == ContentTypeMultipartFormData
+}
+
+// operationType returns the type of the operation selected by opts, or an
+// empty string when it cannot be found. Documents that do not parse are left
+// for graphql.Do to report.
+func operationType(opts *RequestOptions, parseOptions parser.ParseOptions) string {
+	parseOptions.NoLocation = true
+	AST, err := parser.Parse(parser.ParseParams{
+		Source:  opts.Query,
+		Options: parseOptions,
+	})
+	if err != nil {
+		return ""
+	}
+	for _, definition := range AST.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if opts.OperationName == "" || (operation.Name != nil && operation.Name.Value == opts.OperationName) {
+			return operation.Operation
+		}
+	}
+	return ""
+}
+
+// negotiateContentType picks the response media type from the Accept
+// header. Requests without an Accept header get application/json.
+func negotiateContentType(r *http.Request) (string, bool) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return ContentTypeJSON, true
+	}
+	acceptsJSON := false
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case ContentTypeGraphQLResponse:
+			return ContentTypeGraphQLResponse, true
+		case ContentTypeJSON, "application/*", "*/*":
+			acceptsJSON = true
+		}
+	}
+	return ContentTypeJSON, acceptsJSON
+}
+
+// statusCode returns the HTTP status of result. With the legacy
+// application/json media type every GraphQL response is a 200; with
+// application/graphql-response+json a response without data, e.g. after a
+// parse or validation error, is a 400.
+func statusCode(contentType string, result *graphql.Result) int {
+	if contentType == ContentTypeGraphQLResponse && result.Data == nil && result.HasErrors() {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
+
+func writeResult(w http.ResponseWriter, contentType string, result *graphql.Result) {
+	writeJSON(w, contentType, statusCode(contentType, result), result)
+}
+
+func writeRequestError(w http.ResponseWriter, contentType string, err error) {
+	statusCode := http.StatusBadRequest
+	var requestErr *RequestError
+	if errors.As(err, &requestErr) {
+		statusCode = requestErr.StatusCode
+	}
+	// request errors are reported without a data entry
+	writeJSON(w, contentType, statusCode, map[string]interface{}{
+		"errors": gqlerrors.FormatErrors(err),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, statusCode int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,250 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/handler"
+	"github.com/dagger/graphql/testutil"
+)
+
+type contextKey string
+
+var testSchema graphql.Schema
+
+func init() {
+	var err error
+	testSchema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{
+							Name:         "name",
+							Type:         graphql.String,
+							DefaultValue: "world",
+						},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "hello " + p.Args["name"].(string), nil
+					},
+				},
+				"user": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Context.Value(contextKey("user")), nil
+					},
+				},
+				"root": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Info.RootValue.(map[string]interface{})["root"], nil
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"touch": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return true, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type response struct {
+	StatusCode  int
+	ContentType string
+	Body        map[string]interface{}
+}
+
+func serve(t *testing.T, h http.Handler, req *http.Request) response {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return response{
+		StatusCode:  rec.Code,
+		ContentType: rec.Header().Get("Content-Type"),
+		Body:        body,
+	}
+}
+
+func expectData(t *testing.T, resp response, expected map[string]interface{}) {
+	t.Helper()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %v", resp.StatusCode, resp.Body)
+	}
+	if !reflect.DeepEqual(expected, resp.Body["data"]) {
+		t.Fatalf("Unexpected data, Diff: %v", testutil.Diff(expected, resp.Body["data"]))
+	}
+}
+
+func TestHandler_GET(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	values := url.Values{}
+	values.Set("query", `query Hello($name: String) { hello(name: $name) }`)
+	values.Set("variables", `{"name": "GET"}`)
+	values.Set("operationName", "Hello")
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+
+	resp := serve(t, h, req)
+	expectData(t, resp, map[string]interface{}{"hello": "hello GET"})
+	if resp.ContentType != "application/json; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", resp.ContentType)
+	}
+}
+
+func TestHandler_GETRejectsMutations(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	values := url.Values{}
+	values.Set("query", `query Q { hello } mutation M { touch }`)
+	values.Set("operationName", "M")
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", rec.Code)
+	}
+	if rec.Header().Get("Allow") != http.MethodPost {
+		t.Fatalf("unexpected Allow header %q", rec.Header().Get("Allow"))
+	}
+
+	// the query of the same document is allowed
+	values.Set("operationName", "Q")
+	req = httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+	expectData(t, serve(t, h, req), map[string]interface{}{"hello": "hello world"})
+}
+
+func TestHandler_POSTJSON(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	body := `{"query": "mutation M { touch }", "operationName": "M"}`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	expectData(t, serve(t, h, req), map[string]interface{}{"touch": true})
+}
+
+func TestHandler_POSTGraphQL(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	req := httptest.NewRequest(http.MethodPost, `/graphql?variables={"name":"raw"}`,
+		strings.NewReader(`query Hello($name: String) { hello(name: $name) }`))
+	req.Header.Set("Content-Type", "application/graphql")
+
+	expectData(t, serve(t, h, req), map[string]interface{}{"hello": "hello raw"})
+}
+
+func TestHandler_POSTForm(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	values := url.Values{}
+	values.Set("query", `query Hello($name: String) { hello(name: $name) }`)
+	values.Set("variables", `{"name": "form"}`)
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	expectData(t, serve(t, h, req), map[string]interface{}{"hello": "hello form"})
+}
+
+func TestHandler_RequestErrors(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		statusCode  int
+	}{
+		{"invalid JSON", http.MethodPost, "application/json", `{`, http.StatusBadRequest},
+		{"missing query", http.MethodPost, "application/json", `{}`, http.StatusBadRequest},
+		{"unsupported content type", http.MethodPost, "text/plain", `{ hello }`, http.StatusUnsupportedMediaType},
+		{"unsupported method", http.MethodPut, "application/json", `{"query": "{ hello }"}`, http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, "/graphql", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			resp := serve(t, h, req)
+			if resp.StatusCode != test.statusCode {
+				t.Fatalf("expected status %d, got %d", test.statusCode, resp.StatusCode)
+			}
+			if _, ok := resp.Body["data"]; ok {
+				t.Fatalf("expected no data entry, got %v", resp.Body)
+			}
+			if errs, ok := resp.Body["errors"].([]interface{}); !ok || len(errs) != 1 {
+				t.Fatalf("expected one error, got %v", resp.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_GraphQLResponseContentType(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &testSchema})
+
+	// validation errors are reported with a 400
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ unknown }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json;q=0.9")
+	resp := serve(t, h, req)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+	if resp.ContentType != "application/graphql-response+json; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", resp.ContentType)
+	}
+
+	// the same request is a 200 with application/json
+	req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ unknown }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if resp := serve(t, h, req); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// other media types are not acceptable
+	req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ hello }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/html")
+	if resp := serve(t, h, req); resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected status 406, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_ContextAndRootObject(t *testing.T) {
+	h := handler.New(&handler.Config{
+		Schema: &testSchema,
+		ContextFn: func(r *http.Request) context.Context {
+			return context.WithValue(r.Context(), contextKey("user"), r.Header.Get("X-User"))
+		},
+		RootObjectFn: func(ctx context.Context, r *http.Request) map[string]interface{} {
+			return map[string]interface{}{"root": ctx.Value(contextKey("user")).(string) + "'s root"}
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ user root }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", "alice")
+
+	expectData(t, serve(t, h, req), map[string]interface{}{
+		"user": "alice",
+		"root": "alice's root",
+	})
+}
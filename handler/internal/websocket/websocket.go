@@ -0,0 +1,474 @@
+// Package websocket implements the subset of the WebSocket protocol (RFC 6455)
+// needed by the GraphQL WebSocket transport: the opening handshake, text
+// messages, fragmentation and control frames. Extensions are not supported.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types, as defined by the frame opcodes.
+const (
+	continuationMessage = 0
+	TextMessage         = 1
+	BinaryMessage       = 2
+	CloseMessage        = 8
+	PingMessage         = 9
+	PongMessage         = 10
+)
+
+// Close codes defined by RFC 6455.
+const (
+	CloseNormalClosure = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseNoStatus      = 1005
+	CloseMessageTooBig = 1009
+	CloseInternalError = 1011
+)
+
+// DefaultMaxMessageSize is the maximum size of a message read by a Conn when
+// MaxMessageSize is not set.
+const DefaultMaxMessageSize = 1 << 20
+
+// closeTimeout bounds the time spent sending the close frame of a
+// connection.
+const closeTimeout = time.Second
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrMessageTooBig is returned by ReadMessage when a message exceeds the
+// maximum message size of the connection.
+var ErrMessageTooBig = errors.New("websocket: message too big")
+
+// CloseError is returned by ReadMessage when the peer closed the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: close %d %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket connection. Writes are safe for concurrent use; reads
+// must happen from a single goroutine.
+type Conn struct {
+	// MaxMessageSize limits the size of the messages read from the peer.
+	MaxMessageSize int64
+	// WriteTimeout, if positive, is the time given to each frame to be
+	// written to the peer, so that a peer not reading its messages cannot
+	// block the writers forever.
+	WriteTimeout time.Duration
+
+	conn        net.Conn
+	br          *bufio.Reader
+	isClient    bool
+	subprotocol string
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// Subprotocol returns the subprotocol negotiated during the handshake.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func computeAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subprotocols returns the subprotocols requested by the client.
+func Subprotocols(r *http.Request) []string {
+	protocols := []string{}
+	for _, value := range r.Header["Sec-Websocket-Protocol"] {
+		for _, protocol := range strings.Split(value, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				protocols = append(protocols, protocol)
+			}
+		}
+	}
+	return protocols
+}
+
+// IsWebSocketUpgrade reports whether r requests a WebSocket upgrade.
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// SameOrigin reports whether r has no Origin header, or an Origin header
+// whose host is the host of r.
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// Upgrade performs the server side of the opening handshake. subprotocol is
+// sent back to the client when not empty. checkOrigin reports whether the
+// Origin of the request is accepted, SameOrigin is used when nil. On failure
+// an HTTP error has already been written to w.
+func Upgrade(w http.ResponseWriter, r *http.Request, subprotocol string, checkOrigin func(r *http.Request) bool) (*Conn, error) {
+	if checkOrigin == nil {
+		checkOrigin = SameOrigin
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "websocket: method not allowed", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: method not allowed")
+	}
+	if !IsWebSocketUpgrade(r) {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-Websocket-Version") != "13" {
+		w.Header().Set("Sec-Websocket-Version", "13")
+		http.Error(w, "websocket: unsupported version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-Websocket-Key")
+	if key == "" {
+		http.Error(w, "websocket: missing key", http.StatusBadRequest)
+		return nil, errors.New("websocket: missing key")
+	}
+	if !checkOrigin(r) {
+		http.Error(w, "websocket: origin not allowed", http.StatusForbidden)
+		return nil, errors.New("websocket: origin not allowed")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: response does not implement http.Hijacker", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not implement http.Hijacker")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	if brw.Reader.Buffered() > 0 {
+		netConn.Close()
+		return nil, errors.New("websocket: client sent data before handshake completed")
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + computeAcceptKey(key) + "\r\n"
+	if subprotocol != "" {
+		response += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+	response += "\r\n"
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		conn:           netConn,
+		br:             brw.Reader,
+		subprotocol:    subprotocol,
+	}, nil
+}
+
+// Dial opens a client connection to the ws:// or http:// URL rawurl,
+// requesting the given subprotocols.
+func Dial(rawurl string, header http.Header, subprotocols ...string) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "http":
+	default:
+		return nil, nil, fmt.Errorf("websocket: unsupported scheme %q", u.Scheme)
+	}
+
+	netConn, err := net.Dial("tcp", u.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, keyBytes); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if len(subprotocols) > 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(subprotocols, ", "))
+	}
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-Websocket-Accept") != computeAcceptKey(key) {
+		netConn.Close()
+		return nil, resp, fmt.Errorf("websocket: bad handshake: %s", resp.Status)
+	}
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		conn:           netConn,
+		br:             br,
+		isClient:       true,
+		subprotocol:    resp.Header.Get("Sec-Websocket-Protocol"),
+	}, resp, nil
+}
+
+// SetReadDeadline sets the deadline of the next reads of the connection.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// ReadMessage reads the next text or binary message. Ping frames are
+// answered and pong frames ignored. When the peer closes the connection, the
+// close frame is echoed and a *CloseError is returned.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	messageType = -1
+	for {
+		final, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return -1, nil, err
+		}
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteMessage(PongMessage, payload); err != nil {
+				return -1, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Text = string(payload[2:])
+			}
+			c.Close(closeErr.Code, "")
+			return -1, nil, closeErr
+		case TextMessage, BinaryMessage:
+			if messageType != -1 {
+				return -1, nil, c.failProtocol("unexpected data frame in fragmented message")
+			}
+			messageType = opcode
+		case continuationMessage:
+			if messageType == -1 {
+				return -1, nil, c.failProtocol("unexpected continuation frame")
+			}
+		default:
+			return -1, nil, c.failProtocol(fmt.Sprintf("unknown opcode %d", opcode))
+		}
+
+		if int64(len(data)+len(payload)) > c.MaxMessageSize {
+			c.Close(CloseMessageTooBig, "")
+			return -1, nil, ErrMessageTooBig
+		}
+		data = append(data, payload...)
+		if final {
+			return messageType, data, nil
+		}
+	}
+}
+
+func (c *Conn) failProtocol(reason string) error {
+	c.Close(CloseProtocolError, reason)
+	return errors.New("websocket: " + reason)
+}
+
+func (c *Conn) readFrame() (final bool, opcode int, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.br, header); err != nil {
+		return false, 0, nil, err
+	}
+	final = header[0]&0x80 != 0
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.failProtocol("unexpected reserved bits")
+	}
+	opcode = int(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7f)
+
+	if masked == c.isClient {
+		return false, 0, nil, c.failProtocol("invalid frame masking")
+	}
+	if opcode >= CloseMessage && (length > 125 || !final) {
+		return false, 0, nil, c.failProtocol("invalid control frame")
+	}
+
+	switch length {
+	case 126:
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+		if _, err := io.ReadFull(c.br, b); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(b))
+	}
+	if length < 0 || length > c.MaxMessageSize {
+		c.Close(CloseMessageTooBig, "")
+		return false, 0, nil, ErrMessageTooBig
+	}
+
+	var maskKey []byte
+	if masked {
+		maskKey = make([]byte, 4)
+		if _, err := io.ReadFull(c.br, maskKey); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		if masked {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+	return final, opcode, payload, nil
+}
+
+// WriteMessage writes a single frame message.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return errors.New("websocket: connection closed")
+	}
+	if c.WriteTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.WriteTimeout))
+	}
+	return c.writeFrame(messageType, data)
+}
+
+func (c *Conn) writeFrame(opcode int, data []byte) error {
+	frame := []byte{0x80 | byte(opcode)}
+	maskBit := byte(0)
+	if c.isClient {
+		maskBit = 0x80
+	}
+	switch length := len(data); {
+	case length <= 125:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	if c.isClient {
+		maskKey := make([]byte, 4)
+		if _, err := io.ReadFull(rand.Reader, maskKey); err != nil {
+			return err
+		}
+		frame = append(frame, maskKey...)
+		masked := make([]byte, len(data))
+		for i := range data {
+			masked[i] = data[i] ^ maskKey[i%4]
+		}
+		data = masked
+	}
+	frame = append(frame, data...)
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *Conn) writeClose(code int, reason string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	payload := []byte{}
+	if code != CloseNoStatus {
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	return c.writeFrame(CloseMessage, payload)
+}
+
+// Close sends a close frame with the given code and reason and closes the
+// underlying connection. The close frame is given up when it cannot be
+// written in time, e.g. because another write is blocked on a peer that does
+// not read its messages: closing the connection then unblocks that write.
+func (c *Conn) Close(code int, reason string) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.writeClose(code, reason)
+	}()
+	var err error
+	timer := time.NewTimer(closeTimeout)
+	select {
+	case err = <-done:
+		timer.Stop()
+	case <-timer.C:
+		err = errors.New("websocket: close frame timed out")
+	}
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newEchoServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r, "echo", nil)
+		if err != nil {
+			return
+		}
+		conn.MaxMessageSize = 1 << 17
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestConn_Echo(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn, _, err := Dial(server.URL, nil, "other", "echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(CloseNormalClosure, "")
+	if conn.Subprotocol() != "echo" {
+		t.Fatalf("unexpected subprotocol %q", conn.Subprotocol())
+	}
+
+	for _, size := range []int{0, 125, 126, 0xffff, 0x10000} {
+		msg := bytes.Repeat([]byte("a"), size)
+		if err := conn.WriteMessage(TextMessage, msg); err != nil {
+			t.Fatal(err)
+		}
+		// ping frames are answered transparently
+		if err := conn.WriteMessage(PingMessage, []byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if messageType != TextMessage || !bytes.Equal(msg, data) {
+			t.Fatalf("unexpected echo of %d bytes: %d bytes", size, len(data))
+		}
+	}
+}
+
+func TestConn_Fragmented(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn, _, err := Dial(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(CloseNormalClosure, "")
+
+	conn.writeMu.Lock()
+	// first fragment without the FIN bit
+	frame := []byte{TextMessage, 0x80 | 3, 0, 0, 0, 0, 'a', 'b', 'c'}
+	conn.conn.Write(frame)
+	// interleaved control frame
+	conn.writeFrame(PingMessage, nil)
+	conn.writeFrame(continuationMessage, []byte("def"))
+	conn.writeMu.Unlock()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abcdef" {
+		t.Fatalf("unexpected message %q", data)
+	}
+}
+
+func TestConn_MessageTooBig(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn, _, err := Dial(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(CloseNormalClosure, "")
+
+	if err := conn.WriteMessage(TextMessage, make([]byte, 1<<17+1)); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = conn.ReadMessage()
+	var closeErr *CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != CloseMessageTooBig {
+		t.Fatalf("expected close %d, got %v", CloseMessageTooBig, err)
+	}
+}
+
+func TestUpgrade_RejectsPlainRequests(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestUpgrade_ChecksOrigin(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	_, resp, err := Dial(server.URL, http.Header{"Origin": {"http://example.com"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %v", err)
+	}
+
+	conn, _, err := Dial(server.URL, http.Header{"Origin": {server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close(CloseNormalClosure, "")
+}
+
+// newPipeConn returns a server Conn whose peer never reads.
+func newPipeConn() (*Conn, net.Conn) {
+	serverConn, clientConn := net.Pipe()
+	return &Conn{
+		MaxMessageSize: DefaultMaxMessageSize,
+		conn:           serverConn,
+		br:             bufio.NewReader(serverConn),
+	}, clientConn
+}
+
+func TestConn_WriteTimeout(t *testing.T) {
+	conn, clientConn := newPipeConn()
+	defer clientConn.Close()
+	conn.WriteTimeout = 10 * time.Millisecond
+
+	err := conn.WriteMessage(TextMessage, []byte("a"))
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestConn_CloseDoesNotWaitForBlockedWrites(t *testing.T) {
+	conn, clientConn := newPipeConn()
+	defer clientConn.Close()
+
+	written := make(chan error)
+	go func() {
+		written <- conn.WriteMessage(TextMessage, []byte("a"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		conn.Close(CloseNormalClosure, "")
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * closeTimeout):
+		t.Fatal("Close blocked behind a write")
+	}
+	if err := <-written; err == nil {
+		t.Fatal("expected the blocked write to fail")
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+)
+
+// ContentTypeEventStream is the media type of Server-Sent Events responses.
+const ContentTypeEventStream = "text/event-stream"
+
+// DefaultSSEHeartbeat is the interval at which heartbeat comments are sent
+// when SSEConfig.Heartbeat is not set.
+const DefaultSSEHeartbeat = 12 * time.Second
+
+// SSEConfig configures an SSEHandler.
+type SSEConfig struct {
+	Schema *graphql.Schema
+
+	// ContextFn, if set, provides the context of each request. The request's
+	// own context is used otherwise. Either way, the context is cancelled when
+	// the client disconnects.
+	ContextFn ContextFn
+
+	// RootObjectFn, if set, provides the root object of each request.
+	RootObjectFn RootObjectFn
+
+	// Heartbeat is the interval at which heartbeat comments are sent to keep
+	// the connection alive. DefaultSSEHeartbeat is used when zero, heartbeats
+	// are disabled when negative.
+	Heartbeat time.Duration
+
+	// MaxBodySize limits the size of request bodies. DefaultMaxBodySize is
+	// used when zero.
+	MaxBodySize int64
+
+	// ParseOptions are used to parse the requests, e.g. to limit the size of
+	// the documents accepted.
+	ParseOptions parser.ParseOptions
+}
+
+// SSEHandler is an http.Handler streaming the results of GraphQL operations,
+// including subscriptions, as Server-Sent Events following the "distinct
+// connections" mode of the GraphQL over SSE protocol
+// (https://github.com/enisdenjo/graphql-sse/blob/master/PROTOCOL.md).
+//
+// Requests are decoded like the ones served by Handler, batches and file
+// uploads excepted. Each result is sent as a `next` event, and a `complete`
+// event ends the stream.
+type SSEHandler struct {
+	Schema       *graphql.Schema
+	contextFn    ContextFn
+	rootObjectFn RootObjectFn
+	heartbeat    time.Duration
+	maxBodySize  int64
+	parseOptions parser.ParseOptions
+}
+
+// NewSSEHandler returns an SSEHandler for the given config.
+func NewSSEHandler(p *SSEConfig) *SSEHandler {
+	if p == nil {
+		p = &SSEConfig{}
+	}
+	if p.Schema == nil {
+		panic("undefined GraphQL schema")
+	}
+	heartbeat := p.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = DefaultSSEHeartbeat
+	}
+	maxBodySize := p.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	return &SSEHandler{
+		Schema:       p.Schema,
+		contextFn:    p.ContextFn,
+		rootObjectFn: p.RootObjectFn,
+		heartbeat:    heartbeat,
+		maxBodySize:  maxBodySize,
+		parseOptions: p.ParseOptions,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeRequestError(w, ContentTypeJSON, newRequestError(http.StatusInternalServerError, "Streaming is not supported."))
+		return
+	}
+
+	// file uploads are not supported
+	batch, isBatch, release, err := readRequests(w, r, h.maxBodySize, 0)
+	defer release()
+	if err == nil && isBatch {
+		err = newRequestError(http.StatusBadRequest, "Batched requests are not supported.")
+	}
+	if err != nil {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+		}
+		writeRequestError(w, ContentTypeJSON, err)
+		return
+	}
+	opts := batch[0]
+	if opts.Query == "" {
+		writeRequestError(w, ContentTypeJSON, newRequestError(http.StatusBadRequest, "Must provide query string."))
+		return
+	}
+	opType := operationType(opts, h.parseOptions)
+	if r.Method == http.MethodGet && opType == ast.OperationTypeMutation {
+		w.Header().Set("Allow", http.MethodPost)
+		writeRequestError(w, ContentTypeJSON, newRequestError(http.StatusMethodNotAllowed,
+			"Can only perform a mutation operation from a POST request."))
+		return
+	}
+
+	ctx := r.Context()
+	if h.contextFn != nil {
+		ctx = h.contextFn(r)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// the context returned by ContextFn may not derive from the request's
+	go func() {
+		select {
+		case <-r.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	params := graphql.Params{
+		Schema:         *h.Schema,
+		RequestString:  opts.Query,
+		VariableValues: opts.Variables,
+		OperationName:  opts.OperationName,
+		Context:        ctx,
+		ParseOptions:   h.parseOptions,
+	}
+	if h.rootObjectFn != nil {
+		params.RootObject = h.rootObjectFn(ctx, r)
+	}
+
+	var results chan *graphql.Result
+	if opType == ast.OperationTypeSubscription {
+		results = graphql.Subscribe(params)
+	} else {
+		results = make(chan *graphql.Result, 1)
+		go func() {
+			results <- graphql.Do(params)
+			close(results)
+		}()
+	}
+	w.Header().Set("Content-Type", ContentTypeEventStream+"; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ":\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case result, ok := <-results:
+			if !ok {
+				writeEvent(w, "complete", nil)
+				flusher.Flush()
+				return
+			}
+			if err := writeEvent(w, "next", result); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes a Server-Sent Event with the JSON encoding of data.
+func writeEvent(w io.Writer, event string, data interface{}) error {
+	payload := []byte{}
+	if data != nil {
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			payload, _ = json.Marshal(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagger/graphql/handler"
+	"github.com/dagger/graphql/testutil"
+)
+
+// readEvents reads the events of an SSE stream until it ends, returning
+// them as "event: data" strings. Heartbeat comments are returned as ":".
+func readEvents(t *testing.T, resp *http.Response, max int) []string {
+	events := []string{}
+	scanner := bufio.NewScanner(resp.Body)
+	event := ""
+	for scanner.Scan() && len(events) < max {
+		line := scanner.Text()
+		switch {
+		case line == ":":
+			events = append(events, ":")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, event+": "+strings.TrimPrefix(line, "data: "))
+		}
+	}
+	return events
+}
+
+func TestSSEHandler_Subscription(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	server := httptest.NewServer(handler.NewSSEHandler(&handler.SSEConfig{Schema: &schema}))
+	defer server.Close()
+
+	body := `{"query": "subscription ($to: Int) { count(to: $to) }", "variables": {"to": 2}}`
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != "text/event-stream; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	expected := []string{
+		`next: {"data":{"count":1}}`,
+		`next: {"data":{"count":2}}`,
+		`complete: `,
+	}
+	events := readEvents(t, resp, 10)
+	if !reflect.DeepEqual(expected, events) {
+		t.Fatalf("Unexpected events, Diff: %v", testutil.Diff(expected, events))
+	}
+}
+
+func TestSSEHandler_Query(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	server := httptest.NewServer(handler.NewSSEHandler(&handler.SSEConfig{Schema: &schema}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?query=" + url.QueryEscape("{ user }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	expected := []string{
+		`next: {"data":{"user":null}}`,
+		`complete: `,
+	}
+	events := readEvents(t, resp, 10)
+	if !reflect.DeepEqual(expected, events) {
+		t.Fatalf("Unexpected events, Diff: %v", testutil.Diff(expected, events))
+	}
+}
+
+func TestSSEHandler_Heartbeat(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	server := httptest.NewServer(handler.NewSSEHandler(&handler.SSEConfig{
+		Schema:    &schema,
+		Heartbeat: 10 * time.Millisecond,
+	}))
+	defer server.Close()
+
+	query := url.QueryEscape("subscription { count(to: 0, wait: true) }")
+	resp, err := http.Get(server.URL + "?query=" + query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if events := readEvents(t, resp, 2); !reflect.DeepEqual([]string{":", ":"}, events) {
+		t.Fatalf("expected heartbeats, got %v", events)
+	}
+}
+
+func TestSSEHandler_DisconnectCancelsSubscription(t *testing.T) {
+	done := make(chan struct{})
+	schema := newSubscriptionSchema(t, done)
+	server := httptest.NewServer(handler.NewSSEHandler(&handler.SSEConfig{Schema: &schema}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	query := url.QueryEscape("subscription { count(to: 1, wait: true) }")
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?query="+query, nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if events := readEvents(t, resp, 1); !reflect.DeepEqual([]string{`next: {"data":{"count":1}}`}, events) {
+		t.Fatalf("unexpected events %v", events)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription context was not cancelled")
+	}
+}
+
+func TestSSEHandler_RequestErrors(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewSSEHandler(&handler.SSEConfig{Schema: &schema})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	req = newUploadRequest(t,
+		`{"query": "{ user }"}`,
+		`{"0": ["variables.file"]}`,
+		uploadPart{"0", "a.txt", "hello upload"},
+	)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dagger/graphql"
+)
+
+// ContentTypeMultipartFormData is the media type of GraphQL multipart
+// requests, used to upload files.
+const ContentTypeMultipartFormData = "multipart/form-data"
+
+// maxUploadMemory is the part of a multipart body kept in memory, the rest
+// of the files being stored on disk until the request is served.
+const maxUploadMemory = 32 << 20
+
+// requestOptionsFromMultipart decodes a request following the GraphQL
+// multipart request specification
+// (https://github.com/jaydenseric/graphql-multipart-request-spec).
+//
+// The `operations` field holds the JSON encoded request or batch of requests,
+// the `map` field maps each file field to the variable paths it is used at,
+// e.g. `{"0": ["variables.file"]}` or `{"0": ["1.variables.files.0"]}` for a
+// batch. Each file replaces the value found at its paths with an
+// *graphql.UploadedFile.
+func requestOptionsFromMultipart(r *http.Request) ([]*RequestOptions, bool, error) {
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		return nil, false, newRequestError(http.StatusBadRequest, "Could not parse multipart form: %v", err)
+	}
+
+	operations := r.MultipartForm.Value["operations"]
+	if len(operations) != 1 {
+		return nil, false, newRequestError(http.StatusBadRequest, "Multipart form must have exactly one `operations` field.")
+	}
+	opts, isBatch, err := requestOptionsFromJSON([]byte(operations[0]))
+	if err != nil {
+		return nil, false, err
+	}
+
+	fileMap := map[string][]string{}
+	if values := r.MultipartForm.Value["map"]; len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &fileMap); err != nil {
+			return nil, false, newRequestError(http.StatusBadRequest, "Multipart form `map` field is invalid JSON.")
+		}
+	}
+
+	for key, paths := range fileMap {
+		headers := r.MultipartForm.File[key]
+		if len(headers) != 1 {
+			closeUploads(opts)
+			return nil, false, newRequestError(http.StatusBadRequest, "Multipart form is missing the file %q.", key)
+		}
+		for _, path := range paths {
+			header := headers[0]
+			file, err := header.Open()
+			if err != nil {
+				closeUploads(opts)
+				return nil, false, newRequestError(http.StatusBadRequest, "Could not open the file %q: %v", key, err)
+			}
+			upload := &graphql.UploadedFile{
+				Filename:    header.Filename,
+				Size:        header.Size,
+				ContentType: header.Header.Get("Content-Type"),
+				File:        file,
+			}
+			if err := injectUpload(opts, isBatch, path, upload); err != nil {
+				file.Close()
+				closeUploads(opts)
+				return nil, false, err
+			}
+		}
+	}
+	return opts, isBatch, nil
+}
+
+// injectUpload sets upload at the given object path of the request options.
+func injectUpload(opts []*RequestOptions, isBatch bool, path string, upload *graphql.UploadedFile) error {
+	invalidPath := newRequestError(http.StatusBadRequest, "Invalid file path %q in multipart form `map` field.", path)
+
+	segments := strings.Split(path, ".")
+	index := 0
+	if isBatch {
+		i, err := strconv.Atoi(segments[0])
+		if err != nil || i < 0 || i >= len(opts) {
+			return invalidPath
+		}
+		index = i
+		segments = segments[1:]
+	}
+	if len(segments) < 2 || segments[0] != "variables" {
+		return invalidPath
+	}
+	if opts[index].Variables == nil {
+		return invalidPath
+	}
+
+	var container interface{} = opts[index].Variables
+	segments = segments[1:]
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[segment]; !ok {
+				return invalidPath
+			}
+			if last {
+				c[segment] = upload
+				return nil
+			}
+			container = c[segment]
+		case []interface{}:
+			j, err := strconv.Atoi(segment)
+			if err != nil || j < 0 || j >= len(c) {
+				return invalidPath
+			}
+			if last {
+				c[j] = upload
+				return nil
+			}
+			container = c[j]
+		default:
+			return invalidPath
+		}
+	}
+	return invalidPath
+}
+
+// closeUploads closes the files of the uploads held by the variables of opts.
+func closeUploads(opts []*RequestOptions) {
+	for _, opt := range opts {
+		if opt != nil {
+			closeUploadsIn(opt.Variables)
+		}
+	}
+}
+
+func closeUploadsIn(value interface{}) {
+	switch value := value.(type) {
+	case *graphql.UploadedFile:
+		if closer, ok := value.File.(io.Closer); ok {
+			closer.Close()
+		}
+	case map[string]interface{}:
+		for _, v := range value {
+			closeUploadsIn(v)
+		}
+	case []interface{}:
+		for _, v := range value {
+			closeUploadsIn(v)
+		}
+	}
+}
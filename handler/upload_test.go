@@ -0,0 +1,231 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/handler"
+	"github.com/dagger/graphql/testutil"
+)
+
+var uploadSchema graphql.Schema
+
+func init() {
+	fileType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "File",
+		Fields: graphql.Fields{
+			"filename": &graphql.Field{
+				Type: graphql.String,
+			},
+			"size": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"contentType": &graphql.Field{
+				Type: graphql.String,
+			},
+			"content": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					content, err := ioutil.ReadAll(p.Source.(*graphql.UploadedFile).File)
+					return string(content), err
+				},
+			},
+		},
+	})
+	var err error
+	uploadSchema, err = graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"ok": &graphql.Field{
+					Type: graphql.Boolean,
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"upload": &graphql.Field{
+					Type: fileType,
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{
+							Name: "file",
+							Type: graphql.NewNonNull(graphql.Upload),
+						},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Args["file"], nil
+					},
+				},
+				"uploadMany": &graphql.Field{
+					Type: graphql.NewList(fileType),
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{
+							Name: "files",
+							Type: graphql.NewList(graphql.Upload),
+						},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Args["files"], nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type uploadPart struct {
+	field    string
+	filename string
+	content  string
+}
+
+func newUploadRequest(t *testing.T, operations, fileMap string, files ...uploadPart) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("operations", operations); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.WriteField("map", fileMap); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(file.content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/graphql", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestHandler_Upload(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20})
+	req := newUploadRequest(t,
+		`{"query": "mutation ($file: Upload!) { upload(file: $file) { filename size contentType content } }", "variables": {"file": null}}`,
+		`{"0": ["variables.file"]}`,
+		uploadPart{"0", "a.txt", "hello upload"},
+	)
+	expectData(t, serve(t, h, req), map[string]interface{}{
+		"upload": map[string]interface{}{
+			"filename":    "a.txt",
+			"size":        float64(len("hello upload")),
+			"contentType": "application/octet-stream",
+			"content":     "hello upload",
+		},
+	})
+}
+
+func TestHandler_UploadList(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20})
+	req := newUploadRequest(t,
+		`{"query": "mutation ($files: [Upload]) { uploadMany(files: $files) { filename content } }", "variables": {"files": [null, null]}}`,
+		`{"a": ["variables.files.0"], "b": ["variables.files.1"]}`,
+		uploadPart{"a", "a.txt", "first"},
+		uploadPart{"b", "b.txt", "second"},
+	)
+	expectData(t, serve(t, h, req), map[string]interface{}{
+		"uploadMany": []interface{}{
+			map[string]interface{}{"filename": "a.txt", "content": "first"},
+			map[string]interface{}{"filename": "b.txt", "content": "second"},
+		},
+	})
+}
+
+func TestHandler_UploadBatch(t *testing.T) {
+	h := handler.New(&handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20, MaxBatchSize: 2})
+	query := `mutation ($file: Upload!) { upload(file: $file) { content } }`
+	operations, _ := json.Marshal([]map[string]interface{}{
+		{"query": query, "variables": map[string]interface{}{"file": nil}},
+		{"query": query, "variables": map[string]interface{}{"file": nil}},
+	})
+	req := newUploadRequest(t, string(operations),
+		`{"0": ["0.variables.file", "1.variables.file"]}`,
+		uploadPart{"0", "a.txt", "shared"},
+	)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var results []interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	result := map[string]interface{}{
+		"data": map[string]interface{}{"upload": map[string]interface{}{"content": "shared"}},
+	}
+	expected := []interface{}{result, result}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected results, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestHandler_UploadErrors(t *testing.T) {
+	operations := `{"query": "mutation ($file: Upload!) { upload(file: $file) { filename } }", "variables": {"file": null}}`
+	tests := []struct {
+		name       string
+		config     *handler.Config
+		fileMap    string
+		files      []uploadPart
+		statusCode int
+	}{
+		{
+			name:       "uploads disabled",
+			config:     &handler.Config{Schema: &uploadSchema},
+			fileMap:    `{"0": ["variables.file"]}`,
+			files:      []uploadPart{{"0", "a.txt", "a"}},
+			statusCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "missing file",
+			config:     &handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20},
+			fileMap:    `{"0": ["variables.file"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown variable",
+			config:     &handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20},
+			fileMap:    `{"0": ["variables.other"]}`,
+			files:      []uploadPart{{"0", "a.txt", "a"}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid map",
+			config:     &handler.Config{Schema: &uploadSchema, MaxUploadSize: 1 << 20},
+			fileMap:    `{"0": "variables.file"}`,
+			files:      []uploadPart{{"0", "a.txt", "a"}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "body too large",
+			config:     &handler.Config{Schema: &uploadSchema, MaxUploadSize: 64},
+			fileMap:    `{"0": ["variables.file"]}`,
+			files:      []uploadPart{{"0", "a.txt", "a"}},
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := handler.New(test.config)
+			req := newUploadRequest(t, operations, test.fileMap, test.files...)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
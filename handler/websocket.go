@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/handler/internal/websocket"
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+)
+
+// GraphQLTransportWSProtocol is the WebSocket subprotocol implemented by
+// WebSocketHandler, see
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md.
+const GraphQLTransportWSProtocol = "graphql-transport-ws"
+
+// DefaultConnectionInitWaitTimeout is the time given to clients to send the
+// connection_init message when WebSocketConfig.ConnectionInitWaitTimeout is
+// not set.
+const DefaultConnectionInitWaitTimeout = 3 * time.Second
+
+// DefaultWriteTimeout is the time given to each message to be written to the
+// client when WebSocketConfig.WriteTimeout is not set.
+const DefaultWriteTimeout = 10 * time.Second
+
+// graphql-transport-ws message types.
+const (
+	wsMessageConnectionInit = "connection_init"
+	wsMessageConnectionAck  = "connection_ack"
+	wsMessagePing           = "ping"
+	wsMessagePong           = "pong"
+	wsMessageSubscribe      = "subscribe"
+	wsMessageNext           = "next"
+	wsMessageError          = "error"
+	wsMessageComplete       = "complete"
+)
+
+// graphql-transport-ws close codes.
+const (
+	wsCloseInvalidMessage         = 4400
+	wsCloseUnauthorized           = 4401
+	wsCloseForbidden              = 4403
+	wsCloseSubprotocolNotAccepted = 4406
+	wsCloseInitTimeout            = 4408
+	wsCloseSubscriberExists       = 4409
+	wsCloseTooManyInitRequests    = 4429
+)
+
+// OnConnectFn is called with the request that opened a WebSocket connection
+// and the payload of its connection_init message. The returned context is
+// used to execute all the operations of the connection. Returning an error
+// closes the connection with the 4403 Forbidden code.
+type OnConnectFn func(ctx context.Context, r *http.Request, payload map[string]interface{}) (context.Context, error)
+
+// WebSocketConfig configures a WebSocketHandler.
+type WebSocketConfig struct {
+	Schema *graphql.Schema
+
+	// RootObjectFn, if set, provides the root object of each operation. The
+	// request is the one that opened the connection.
+	RootObjectFn RootObjectFn
+
+	// OnConnect, if set, authorizes new connections.
+	OnConnect OnConnectFn
+
+	// CheckOrigin, if set, reports whether the Origin of a request opening a
+	// connection is accepted. When nil, only the requests without an Origin
+	// or from the same host are accepted. Rejected requests get a 403
+	// Forbidden response.
+	CheckOrigin func(r *http.Request) bool
+
+	// ConnectionInitWaitTimeout is the time given to clients to send the
+	// connection_init message. DefaultConnectionInitWaitTimeout is used when
+	// zero.
+	ConnectionInitWaitTimeout time.Duration
+
+	// KeepAlive is the interval at which ping messages are sent to the
+	// client. Keepalives are disabled when zero.
+	KeepAlive time.Duration
+
+	// WriteTimeout is the time given to each message to be written to the
+	// client, after which the connection is considered broken.
+	// DefaultWriteTimeout is used when zero.
+	WriteTimeout time.Duration
+
+	// MaxMessageSize limits the size of the messages sent by clients.
+	// DefaultMaxBodySize is used when zero.
+	MaxMessageSize int64
+
+	// ParseOptions are used to parse the requests, e.g. to limit the size of
+	// the documents accepted.
+	ParseOptions parser.ParseOptions
+}
+
+// WebSocketHandler is an http.Handler serving GraphQL operations, including
+// subscriptions, over WebSocket connections using the graphql-transport-ws
+// protocol.
+type WebSocketHandler struct {
+	Schema                    *graphql.Schema
+	rootObjectFn              RootObjectFn
+	onConnect                 OnConnectFn
+	checkOrigin               func(r *http.Request) bool
+	connectionInitWaitTimeout time.Duration
+	keepAlive                 time.Duration
+	writeTimeout              time.Duration
+	maxMessageSize            int64
+	parseOptions              parser.ParseOptions
+}
+
+// NewWebSocketHandler returns a WebSocketHandler for the given config.
+func NewWebSocketHandler(p *WebSocketConfig) *WebSocketHandler {
+	if p == nil {
+		p = &WebSocketConfig{}
+	}
+	if p.Schema == nil {
+		panic("undefined GraphQL schema")
+	}
+	connectionInitWaitTimeout := p.ConnectionInitWaitTimeout
+	if connectionInitWaitTimeout == 0 {
+		connectionInitWaitTimeout = DefaultConnectionInitWaitTimeout
+	}
+	writeTimeout := p.WriteTimeout
+	if writeTimeout == 0 {
+		writeTimeout = DefaultWriteTimeout
+	}
+	maxMessageSize := p.MaxMessageSize
+	if maxMessageSize == 0 {
+		maxMessageSize = DefaultMaxBodySize
+	}
+	return &WebSocketHandler{
+		Schema:                    p.Schema,
+		rootObjectFn:              p.RootObjectFn,
+		onConnect:                 p.OnConnect,
+		checkOrigin:               p.CheckOrigin,
+		connectionInitWaitTimeout: connectionInitWaitTimeout,
+		keepAlive:                 p.KeepAlive,
+		writeTimeout:              writeTimeout,
+		maxMessageSize:            maxMessageSize,
+		parseOptions:              p.ParseOptions,
+	}
+}
+
+type wsIncomingMessage struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type wsOutgoingMessage struct {
+	ID      string      `json:"id,omitempty"`
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// wsOperation is an operation running on a connection.
+type wsOperation struct {
+	cancel context.CancelFunc
+}
+
+// wsConnection holds the state of a single WebSocket connection.
+type wsConnection struct {
+	handler *WebSocketHandler
+	conn    *websocket.Conn
+	request *http.Request
+
+	// ctx is the context of the connection, as returned by OnConnect.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu           sync.Mutex
+	initReceived bool
+	acknowledged bool
+	operations   map[string]*wsOperation
+	wg           sync.WaitGroup
+}
+
+// ServeHTTP implements http.Handler.
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subprotocol := ""
+	for _, protocol := range websocket.Subprotocols(r) {
+		if protocol == GraphQLTransportWSProtocol {
+			subprotocol = protocol
+		}
+	}
+	conn, err := websocket.Upgrade(w, r, subprotocol, h.checkOrigin)
+	if err != nil {
+		return
+	}
+	conn.MaxMessageSize = h.maxMessageSize
+	conn.WriteTimeout = h.writeTimeout
+	if subprotocol == "" {
+		conn.Close(wsCloseSubprotocolNotAccepted, "Subprotocol not acceptable")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	c := &wsConnection{
+		handler:    h,
+		conn:       conn,
+		request:    r,
+		ctx:        ctx,
+		cancel:     cancel,
+		operations: map[string]*wsOperation{},
+	}
+	c.serve()
+}
+
+func (c *wsConnection) serve() {
+	defer func() {
+		c.cancel()
+		c.wg.Wait()
+		c.conn.Close(websocket.CloseNormalClosure, "")
+	}()
+
+	initTimer := time.AfterFunc(c.handler.connectionInitWaitTimeout, func() {
+		c.mu.Lock()
+		initReceived := c.initReceived
+		c.mu.Unlock()
+		if !initReceived {
+			c.conn.Close(wsCloseInitTimeout, "Connection initialisation timeout")
+		}
+	})
+	defer initTimer.Stop()
+
+	if c.handler.keepAlive > 0 {
+		c.wg.Add(1)
+		go c.keepAlive(c.ctx)
+	}
+
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.TextMessage {
+			c.conn.Close(wsCloseInvalidMessage, "Invalid message received")
+			return
+		}
+		msg := wsIncomingMessage{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.conn.Close(wsCloseInvalidMessage, "Invalid message received")
+			return
+		}
+		if !c.handleMessage(msg) {
+			return
+		}
+	}
+}
+
+func (c *wsConnection) keepAlive(ctx context.Context) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.handler.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.send(wsOutgoingMessage{Type: wsMessagePing}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleMessage handles a message from the client and reports whether the
+// connection is still open.
+func (c *wsConnection) handleMessage(msg wsIncomingMessage) bool {
+	switch msg.Type {
+	case wsMessageConnectionInit:
+		return c.handleConnectionInit(msg)
+	case wsMessagePing:
+		return c.send(wsOutgoingMessage{Type: wsMessagePong}) == nil
+	case wsMessagePong:
+		return true
+	case wsMessageSubscribe:
+		return c.handleSubscribe(msg)
+	case wsMessageComplete:
+		c.mu.Lock()
+		if op, ok := c.operations[msg.ID]; ok {
+			op.cancel()
+			delete(c.operations, msg.ID)
+		}
+		c.mu.Unlock()
+		return true
+	default:
+		c.conn.Close(wsCloseInvalidMessage, fmt.Sprintf("Invalid message type %q", msg.Type))
+		return false
+	}
+}
+
+func (c *wsConnection) handleConnectionInit(msg wsIncomingMessage) bool {
+	c.mu.Lock()
+	if c.initReceived {
+		c.mu.Unlock()
+		c.conn.Close(wsCloseTooManyInitRequests, "Too many initialisation requests")
+		return false
+	}
+	c.initReceived = true
+	c.mu.Unlock()
+
+	payload := map[string]interface{}{}
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			c.conn.Close(wsCloseInvalidMessage, "Invalid connection_init payload")
+			return false
+		}
+	}
+	if c.handler.onConnect != nil {
+		ctx, err := c.handler.onConnect(c.ctx, c.request, payload)
+		if err != nil {
+			c.conn.Close(wsCloseForbidden, "Forbidden")
+			return false
+		}
+		if ctx != nil {
+			// keep the connection cancellable from serve
+			c.ctx, c.cancel = withCancel(ctx, c.cancel)
+		}
+	}
+
+	if err := c.send(wsOutgoingMessage{Type: wsMessageConnectionAck}); err != nil {
+		return false
+	}
+	c.mu.Lock()
+	c.acknowledged = true
+	c.mu.Unlock()
+	return true
+}
+
+// withCancel returns a cancellable context derived from ctx, and a cancel
+// function cancelling both it and the previous context of the connection.
+func withCancel(ctx context.Context, cancelPrevious context.CancelFunc) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, func() {
+		cancel()
+		cancelPrevious()
+	}
+}
+
+func (c *wsConnection) handleSubscribe(msg wsIncomingMessage) bool {
+	c.mu.Lock()
+	acknowledged := c.acknowledged
+	c.mu.Unlock()
+	if !acknowledged {
+		c.conn.Close(wsCloseUnauthorized, "Unauthorized")
+		return false
+	}
+
+	opts := &RequestOptions{}
+	if msg.ID == "" || len(msg.Payload) == 0 || json.Unmarshal(msg.Payload, opts) != nil || opts.Query == "" {
+		c.conn.Close(wsCloseInvalidMessage, "Invalid subscribe message")
+		return false
+	}
+
+	c.mu.Lock()
+	if _, ok := c.operations[msg.ID]; ok {
+		c.mu.Unlock()
+		c.conn.Close(wsCloseSubscriberExists, fmt.Sprintf("Subscriber for %s already exists", msg.ID))
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	op := &wsOperation{cancel: cancel}
+	c.operations[msg.ID] = op
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+		c.execute(ctx, msg.ID, op, opts)
+	}()
+	return true
+}
+
+// execute runs an operation, sending its results to the client until it
+// completes or the client sends complete.
+func (c *wsConnection) execute(ctx context.Context, id string, op *wsOperation, opts *RequestOptions) {
+	params := graphql.Params{
+		Schema:         *c.handler.Schema,
+		RequestString:  opts.Query,
+		VariableValues: opts.Variables,
+		OperationName:  opts.OperationName,
+		Context:        ctx,
+		ParseOptions:   c.handler.parseOptions,
+	}
+	if c.handler.rootObjectFn != nil {
+		params.RootObject = c.handler.rootObjectFn(ctx, c.request)
+	}
+
+	var results chan *graphql.Result
+	if operationType(opts, c.handler.parseOptions) == ast.OperationTypeSubscription {
+		results = graphql.Subscribe(params)
+	} else {
+		results = make(chan *graphql.Result, 1)
+		results <- graphql.Do(params)
+		close(results)
+	}
+
+	first := true
+	for result := range results {
+		// the subscription closes results once its context is done, skip the
+		// results sent in the meantime
+		if ctx.Err() != nil {
+			continue
+		}
+		if first && result.Data == nil && result.HasErrors() {
+			// the operation failed before execution, e.g. on validation
+			if c.finish(id, op) {
+				c.send(wsOutgoingMessage{ID: id, Type: wsMessageError, Payload: result.Errors})
+			}
+			op.cancel()
+			continue
+		}
+		first = false
+		c.send(wsOutgoingMessage{ID: id, Type: wsMessageNext, Payload: result})
+	}
+
+	if c.finish(id, op) {
+		c.send(wsOutgoingMessage{ID: id, Type: wsMessageComplete})
+	}
+}
+
+// finish unregisters op and reports whether it was still running, i.e. the
+// client did not complete it.
+func (c *wsConnection) finish(id string, op *wsOperation) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.operations[id] != op {
+		return false
+	}
+	delete(c.operations, id)
+	return true
+}
+
+func (c *wsConnection) send(msg wsOutgoingMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		data, _ = json.Marshal(wsOutgoingMessage{
+			ID:      msg.ID,
+			Type:    wsMessageError,
+			Payload: gqlerrors.FormatErrors(err),
+		})
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -0,0 +1,373 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/handler"
+	"github.com/dagger/graphql/handler/internal/websocket"
+	"github.com/dagger/graphql/testutil"
+)
+
+// newSubscriptionSchema returns a schema whose `count` subscription emits the
+// integers up to `to`, and then waits for its context to be done if `wait` is
+// set. The done channel is closed when the context of a subscription is done.
+func newSubscriptionSchema(t *testing.T, done chan struct{}) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"user": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Context.Value(contextKey("user")), nil
+					},
+				},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"count": &graphql.Field{
+					Type: graphql.Int,
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{
+							Name: "to",
+							Type: graphql.Int,
+						},
+						&graphql.ArgumentConfig{
+							Name:         "wait",
+							Type:         graphql.Boolean,
+							DefaultValue: false,
+						},
+					},
+					Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+						c := make(chan interface{})
+						go func() {
+							defer close(c)
+							for i := 1; i <= p.Args["to"].(int); i++ {
+								select {
+								case c <- i:
+								case <-p.Context.Done():
+									close(done)
+									return
+								}
+							}
+							if p.Args["wait"].(bool) {
+								<-p.Context.Done()
+								close(done)
+							}
+						}()
+						return c, nil
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+type wsClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dialWebSocket(t *testing.T, h *handler.WebSocketHandler, subprotocols ...string) (*wsClient, func()) {
+	server := httptest.NewServer(h)
+	if subprotocols == nil {
+		subprotocols = []string{handler.GraphQLTransportWSProtocol}
+	}
+	conn, _, err := websocket.Dial(server.URL, nil, subprotocols...)
+	if err != nil {
+		server.Close()
+		t.Fatalf("dial: %v", err)
+	}
+	return &wsClient{t: t, conn: conn}, func() {
+		conn.Close(websocket.CloseNormalClosure, "")
+		server.Close()
+	}
+}
+
+func (c *wsClient) send(msg string) {
+	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *wsClient) read() (map[string]interface{}, error) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	msg := map[string]interface{}{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.t.Fatalf("invalid message %q: %v", data, err)
+	}
+	return msg, nil
+}
+
+func (c *wsClient) expect(expected string) {
+	c.t.Helper()
+	msg, err := c.read()
+	if err != nil {
+		c.t.Fatalf("expected %s, got error: %v", expected, err)
+	}
+	want := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		c.t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, msg) {
+		c.t.Fatalf("Unexpected message, Diff: %v", testutil.Diff(want, msg))
+	}
+}
+
+func (c *wsClient) expectClose(code int) {
+	c.t.Helper()
+	for {
+		msg, err := c.read()
+		if err == nil {
+			c.t.Logf("ignoring message %v", msg)
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			c.t.Fatalf("expected close %d, got %v", code, err)
+		}
+		if closeErr.Code != code {
+			c.t.Fatalf("expected close %d, got %d %q", code, closeErr.Code, closeErr.Text)
+		}
+		return
+	}
+}
+
+func TestWebSocketHandler_Subscription(t *testing.T) {
+	done := make(chan struct{})
+	schema := newSubscriptionSchema(t, done)
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{Schema: &schema})
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "1", "type": "subscribe", "payload": {"query": "subscription ($to: Int) { count(to: $to) }", "variables": {"to": 2}}}`)
+	c.expect(`{"id": "1", "type": "next", "payload": {"data": {"count": 1}}}`)
+	c.expect(`{"id": "1", "type": "next", "payload": {"data": {"count": 2}}}`)
+	c.expect(`{"id": "1", "type": "complete"}`)
+}
+
+func TestWebSocketHandler_ClientComplete(t *testing.T) {
+	done := make(chan struct{})
+	schema := newSubscriptionSchema(t, done)
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{Schema: &schema})
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "1", "type": "subscribe", "payload": {"query": "subscription { count(to: 1, wait: true) }"}}`)
+	c.expect(`{"id": "1", "type": "next", "payload": {"data": {"count": 1}}}`)
+	c.send(`{"id": "1", "type": "complete"}`)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription context was not cancelled")
+	}
+
+	// the id can be reused once completed
+	c.send(`{"id": "1", "type": "subscribe", "payload": {"query": "{ user }"}}`)
+	c.expect(`{"id": "1", "type": "next", "payload": {"data": {"user": null}}}`)
+	c.expect(`{"id": "1", "type": "complete"}`)
+}
+
+func TestWebSocketHandler_ConnectionCloseCancelsSubscriptions(t *testing.T) {
+	done := make(chan struct{})
+	schema := newSubscriptionSchema(t, done)
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{Schema: &schema})
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "1", "type": "subscribe", "payload": {"query": "subscription { count(to: 1, wait: true) }"}}`)
+	c.expect(`{"id": "1", "type": "next", "payload": {"data": {"count": 1}}}`)
+	c.conn.Close(websocket.CloseNormalClosure, "")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription context was not cancelled")
+	}
+}
+
+func TestWebSocketHandler_OnConnect(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{
+		Schema: &schema,
+		OnConnect: func(ctx context.Context, r *http.Request, payload map[string]interface{}) (context.Context, error) {
+			token, _ := payload["token"].(string)
+			if token == "" {
+				return nil, errors.New("missing token")
+			}
+			return context.WithValue(ctx, contextKey("user"), strings.TrimPrefix(token, "user:")), nil
+		},
+	})
+
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+	c.send(`{"type": "connection_init", "payload": {"token": "user:alice"}}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "a", "type": "subscribe", "payload": {"query": "{ user }"}}`)
+	c.expect(`{"id": "a", "type": "next", "payload": {"data": {"user": "alice"}}}`)
+	c.expect(`{"id": "a", "type": "complete"}`)
+
+	forbidden, closeForbidden := dialWebSocket(t, h)
+	defer closeForbidden()
+	forbidden.send(`{"type": "connection_init"}`)
+	forbidden.expectClose(4403)
+}
+
+func TestWebSocketHandler_OnConnectReceivesTheRequest(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{
+		Schema: &schema,
+		OnConnect: func(ctx context.Context, r *http.Request, payload map[string]interface{}) (context.Context, error) {
+			return context.WithValue(ctx, contextKey("user"), r.Header.Get("X-User")), nil
+		},
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+	conn, _, err := websocket.Dial(server.URL, http.Header{"X-User": {"alice"}}, handler.GraphQLTransportWSProtocol)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close(websocket.CloseNormalClosure, "")
+
+	c := &wsClient{t: t, conn: conn}
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "a", "type": "subscribe", "payload": {"query": "{ user }"}}`)
+	c.expect(`{"id": "a", "type": "next", "payload": {"data": {"user": "alice"}}}`)
+	c.expect(`{"id": "a", "type": "complete"}`)
+}
+
+func TestWebSocketHandler_CheckOrigin(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	tests := []struct {
+		checkOrigin func(r *http.Request) bool
+		origin      string
+		status      int
+	}{
+		{nil, "", http.StatusSwitchingProtocols},
+		{nil, "http://example.com", http.StatusForbidden},
+		{func(r *http.Request) bool { return r.Header.Get("Origin") == "http://example.com" }, "http://example.com", http.StatusSwitchingProtocols},
+		{func(r *http.Request) bool { return false }, "", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(handler.NewWebSocketHandler(&handler.WebSocketConfig{
+			Schema:      &schema,
+			CheckOrigin: test.checkOrigin,
+		}))
+		header := http.Header{}
+		if test.origin != "" {
+			header.Set("Origin", test.origin)
+		}
+		conn, resp, err := websocket.Dial(server.URL, header, handler.GraphQLTransportWSProtocol)
+		if err == nil {
+			conn.Close(websocket.CloseNormalClosure, "")
+		}
+		if resp == nil || resp.StatusCode != test.status {
+			t.Fatalf("expected status %d for origin %q, got %v, %v", test.status, test.origin, resp, err)
+		}
+		server.Close()
+	}
+}
+
+func TestWebSocketHandler_ValidationError(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{Schema: &schema})
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.send(`{"id": "1", "type": "subscribe", "payload": {"query": "subscription { unknown }"}}`)
+	c.expect(`{"id": "1", "type": "error", "payload": [{
+		"message": "Cannot query field \"unknown\" on type \"Subscription\".",
+		"locations": [{"line": 1, "column": 16}]
+	}]}`)
+
+	// the connection is still usable
+	c.send(`{"type": "ping"}`)
+	c.expect(`{"type": "pong"}`)
+}
+
+func TestWebSocketHandler_ProtocolErrors(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{
+		Schema:                    &schema,
+		ConnectionInitWaitTimeout: 50 * time.Millisecond,
+	})
+	subscribe := `{"id": "1", "type": "subscribe", "payload": {"query": "subscription { count(to: 1, wait: true) }"}}`
+
+	tests := []struct {
+		name     string
+		messages []string
+		code     int
+	}{
+		{"subscribe before init", []string{subscribe}, 4401},
+		{"init timeout", nil, 4408},
+		{"duplicate init", []string{`{"type": "connection_init"}`, `{"type": "connection_init"}`}, 4429},
+		{"duplicate id", []string{`{"type": "connection_init"}`, subscribe, subscribe}, 4409},
+		{"invalid JSON", []string{`{`}, 4400},
+		{"unknown type", []string{`{"type": "unknown"}`}, 4400},
+		{"missing query", []string{`{"type": "connection_init"}`, `{"id": "1", "type": "subscribe", "payload": {}}`}, 4400},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, closeFn := dialWebSocket(t, h)
+			defer closeFn()
+			for _, msg := range test.messages {
+				c.send(msg)
+			}
+			c.expectClose(test.code)
+		})
+	}
+}
+
+func TestWebSocketHandler_Subprotocol(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{Schema: &schema})
+	c, closeFn := dialWebSocket(t, h, "graphql-ws")
+	defer closeFn()
+	c.expectClose(4406)
+}
+
+func TestWebSocketHandler_KeepAlive(t *testing.T) {
+	schema := newSubscriptionSchema(t, make(chan struct{}))
+	h := handler.NewWebSocketHandler(&handler.WebSocketConfig{
+		Schema:    &schema,
+		KeepAlive: 10 * time.Millisecond,
+	})
+	c, closeFn := dialWebSocket(t, h)
+	defer closeFn()
+
+	c.send(`{"type": "connection_init"}`)
+	c.expect(`{"type": "connection_ack"}`)
+	c.expect(`{"type": "ping"}`)
+}
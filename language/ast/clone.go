@@ -0,0 +1,113 @@
+package ast
+
+import (
+	"reflect"
+
+	"github.com/dagger/graphql/language/source"
+)
+
+var (
+	nodeType     = reflect.TypeOf((*Node)(nil)).Elem()
+	locationType = reflect.TypeOf((*Location)(nil))
+	sourceType   = reflect.TypeOf((*source.Source)(nil))
+)
+
+// Clone returns a deep copy of node: editing the copy does not affect node.
+// The sources of the locations are shared with node.
+func Clone(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(node)).Interface().(Node)
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type() == sourceType {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			c.Field(i).Set(cloneValue(v.Field(i)))
+		}
+		return c
+	}
+	return v
+}
+
+// Equal reports whether the nodes a and b have the same structure and values,
+// comments included. Locations are equal if they have the same offsets, and
+// are not compared at all if ignoreLocations is set. A nil list is equal to
+// an empty one.
+func Equal(a, b Node, ignoreLocations bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b), ignoreLocations)
+}
+
+func equalValues(a, b reflect.Value, ignoreLocations bool) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.Type() == locationType && ignoreLocations || a.Type() == sourceType {
+			return true
+		}
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem(), ignoreLocations)
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equalValues(a.Elem(), b.Elem(), ignoreLocations)
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i), ignoreLocations) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalValues(a.Field(i), b.Field(i), ignoreLocations) {
+				return false
+			}
+		}
+		return true
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int:
+		return a.Int() == b.Int()
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package ast_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/testutil"
+)
+
+func parse(t *testing.T, query string, opts parser.ParseOptions) *ast.Document {
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source:  query,
+		Options: opts,
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return astDoc
+}
+
+func loadFile(t *testing.T, name string) string {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unable to load %v", name)
+	}
+	return string(b)
+}
+
+func TestClone_CopiesTheWholeTree(t *testing.T) {
+	for _, file := range []string{"../../kitchen-sink.graphql", "../../schema-kitchen-sink.graphql"} {
+		astDoc := parse(t, loadFile(t, file), parser.ParseOptions{KeepComments: true})
+		before := printer.Print(astDoc)
+
+		clone := ast.Clone(astDoc).(*ast.Document)
+		if clone == astDoc {
+			t.Fatalf("expected a copy of the document")
+		}
+		if !ast.Equal(astDoc, clone, false) {
+			t.Fatalf("expected the copy of %v to be equal to the document", file)
+		}
+		if clone.Loc.Source != astDoc.Loc.Source {
+			t.Fatalf("expected the copy to share the source of the document")
+		}
+
+		// editing the copy does not affect the document
+		clone.Definitions[0] = ast.NewOperationDefinition(&ast.OperationDefinition{
+			Operation: ast.OperationTypeQuery,
+			SelectionSet: ast.NewSelectionSet(&ast.SelectionSet{
+				Selections: []ast.Selection{
+					ast.NewField(&ast.Field{Name: ast.NewName(&ast.Name{Value: "a"})}),
+				},
+			}),
+		})
+		for _, definition := range clone.Definitions[1:] {
+			if definition, ok := definition.(ast.DescribableNode); ok && definition.GetDescription() != nil {
+				definition.GetDescription().Value = "changed"
+			}
+		}
+		if after := printer.Print(astDoc); after != before {
+			t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(before, after))
+		}
+		if ast.Equal(astDoc, clone, false) {
+			t.Fatalf("expected the edited copy of %v to differ from the document", file)
+		}
+	}
+}
+
+func TestClone_ReturnsNilForNil(t *testing.T) {
+	if clone := ast.Clone(nil); clone != nil {
+		t.Fatalf("expected nil, got %v", clone)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b            string
+		ignoreLocations bool
+		expected        bool
+	}{
+		{`{ a(x: 1) { b } }`, `{ a(x: 1) { b } }`, false, true},
+		{`{ a(x: 1) { b } }`, `{a(x:1){b}}`, false, false},
+		{`{ a(x: 1) { b } }`, `{a(x:1){b}}`, true, true},
+		{`{ a(x: 1) { b } }`, `{ a(x: 2) { b } }`, true, false},
+		{`{ a(x: 1) { b } }`, `{ a(x: "1") { b } }`, true, false},
+		{`{ a(x: 1) { b } }`, `{ a(x: 1) { b c } }`, true, false},
+		{`{ a(x: 1) { b } }`, `query { a(x: 1) { b } }`, true, true},
+		{`{ a(x: 1) { b } }`, `query Q { a(x: 1) { b } }`, true, false},
+		{`type A { a: [B!] }`, `type A { a: [B]! }`, true, false},
+	}
+	for _, test := range tests {
+		a := parse(t, test.a, parser.ParseOptions{})
+		b := parse(t, test.b, parser.ParseOptions{})
+		if ast.Equal(a, b, test.ignoreLocations) != test.expected {
+			t.Fatalf("expected Equal(%q, %q, %v) to be %v", test.a, test.b, test.ignoreLocations, test.expected)
+		}
+	}
+
+	// nil and empty lists are equal
+	a := ast.NewField(&ast.Field{Name: ast.NewName(&ast.Name{Value: "a"})})
+	b := ast.NewField(&ast.Field{Name: ast.NewName(&ast.Name{Value: "a"}), Arguments: []*ast.Argument{}})
+	if !ast.Equal(a, b, false) {
+		t.Fatalf("expected nil and empty lists to be equal")
+	}
+	if ast.Equal(a, nil, false) || !ast.Equal(nil, nil, false) {
+		t.Fatalf("expected only nil to be equal to nil")
+	}
+}
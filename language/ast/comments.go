@@ -0,0 +1,52 @@
+package ast
+
+// Comment is a "#" comment of the source, kept when parsing with
+// ParseOptions.KeepComments. Value is the text following the "#".
+type Comment struct {
+	Loc   *Location
+	Value string
+}
+
+// CommentGroup holds the comments attached to a node.
+type CommentGroup struct {
+	// Leading are the comments preceding the node, or its description.
+	Leading []*Comment
+	// AfterDescription are the comments between the description of the node
+	// and the node.
+	AfterDescription []*Comment
+	// Trailing is the comment following the node on its last line.
+	Trailing *Comment
+	// Dangling are the comments following the last node of a block, a list
+	// of arguments or variable definitions or a document, before its end.
+	Dangling []*Comment
+}
+
+// CommentedNode are nodes that can have comments attached to them.
+type CommentedNode interface {
+	Node
+	GetComments() *CommentGroup
+}
+
+// Ensure that all commented node types implements CommentedNode interface
+var _ CommentedNode = (*Document)(nil)
+var _ CommentedNode = (*OperationDefinition)(nil)
+var _ CommentedNode = (*VariableDefinition)(nil)
+var _ CommentedNode = (*FragmentDefinition)(nil)
+var _ CommentedNode = (*Field)(nil)
+var _ CommentedNode = (*Argument)(nil)
+var _ CommentedNode = (*FragmentSpread)(nil)
+var _ CommentedNode = (*InlineFragment)(nil)
+var _ CommentedNode = (*SchemaDefinition)(nil)
+var _ CommentedNode = (*OperationTypeDefinition)(nil)
+var _ CommentedNode = (*ScalarDefinition)(nil)
+var _ CommentedNode = (*ObjectDefinition)(nil)
+var _ CommentedNode = (*FieldDefinition)(nil)
+var _ CommentedNode = (*InputValueDefinition)(nil)
+var _ CommentedNode = (*InterfaceDefinition)(nil)
+var _ CommentedNode = (*UnionDefinition)(nil)
+var _ CommentedNode = (*EnumDefinition)(nil)
+var _ CommentedNode = (*EnumValueDefinition)(nil)
+var _ CommentedNode = (*InputObjectDefinition)(nil)
+var _ CommentedNode = (*TypeExtensionDefinition)(nil)
+var _ CommentedNode = (*SchemaExtensionDefinition)(nil)
+var _ CommentedNode = (*DirectiveDefinition)(nil)
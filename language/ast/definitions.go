@@ -33,6 +33,7 @@ type OperationDefinition struct {
 	VariableDefinitions []*VariableDefinition
 	Directives          []*Directive
 	SelectionSet        *SelectionSet
+	Comments            *CommentGroup
 }
 
 func NewOperationDefinition(op *OperationDefinition) *OperationDefinition {
@@ -51,6 +52,10 @@ func (op *OperationDefinition) GetLoc() *Location {
 	return op.Loc
 }
 
+func (op *OperationDefinition) GetComments() *CommentGroup {
+	return op.Comments
+}
+
 func (op *OperationDefinition) GetOperation() string {
 	return op.Operation
 }
@@ -81,6 +86,7 @@ type FragmentDefinition struct {
 	TypeCondition       *Named
 	Directives          []*Directive
 	SelectionSet        *SelectionSet
+	Comments            *CommentGroup
 }
 
 func NewFragmentDefinition(fd *FragmentDefinition) *FragmentDefinition {
@@ -96,6 +102,7 @@ func NewFragmentDefinition(fd *FragmentDefinition) *FragmentDefinition {
 		TypeCondition:       fd.TypeCondition,
 		Directives:          fd.Directives,
 		SelectionSet:        fd.SelectionSet,
+		Comments:            fd.Comments,
 	}
 }
 
@@ -107,6 +114,10 @@ func (fd *FragmentDefinition) GetLoc() *Location {
 	return fd.Loc
 }
 
+func (fd *FragmentDefinition) GetComments() *CommentGroup {
+	return fd.Comments
+}
+
 func (fd *FragmentDefinition) GetOperation() string {
 	return fd.Operation
 }
@@ -130,6 +141,8 @@ type VariableDefinition struct {
 	Variable     *Variable
 	Type         Type
 	DefaultValue Value
+	Directives   []*Directive
+	Comments     *CommentGroup
 }
 
 func NewVariableDefinition(vd *VariableDefinition) *VariableDefinition {
@@ -148,11 +161,16 @@ func (vd *VariableDefinition) GetLoc() *Location {
 	return vd.Loc
 }
 
+func (vd *VariableDefinition) GetComments() *CommentGroup {
+	return vd.Comments
+}
+
 // TypeExtensionDefinition implements Node, Definition
 type TypeExtensionDefinition struct {
 	Kind       string
 	Loc        *Location
 	Definition *ObjectDefinition
+	Comments   *CommentGroup
 }
 
 func NewTypeExtensionDefinition(def *TypeExtensionDefinition) *TypeExtensionDefinition {
@@ -163,6 +181,7 @@ func NewTypeExtensionDefinition(def *TypeExtensionDefinition) *TypeExtensionDefi
 		Kind:       kinds.TypeExtensionDefinition,
 		Loc:        def.Loc,
 		Definition: def.Definition,
+		Comments:   def.Comments,
 	}
 }
 
@@ -174,6 +193,10 @@ func (def *TypeExtensionDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *TypeExtensionDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *TypeExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
@@ -186,6 +209,52 @@ func (def *TypeExtensionDefinition) GetOperation() string {
 	return ""
 }
 
+// SchemaExtensionDefinition implements Node, Definition
+type SchemaExtensionDefinition struct {
+	Kind           string
+	Loc            *Location
+	Directives     []*Directive
+	OperationTypes []*OperationTypeDefinition
+	Comments       *CommentGroup
+}
+
+func NewSchemaExtensionDefinition(def *SchemaExtensionDefinition) *SchemaExtensionDefinition {
+	if def == nil {
+		def = &SchemaExtensionDefinition{}
+	}
+	return &SchemaExtensionDefinition{
+		Kind:           kinds.SchemaExtensionDefinition,
+		Loc:            def.Loc,
+		Directives:     def.Directives,
+		OperationTypes: def.OperationTypes,
+		Comments:       def.Comments,
+	}
+}
+
+func (def *SchemaExtensionDefinition) GetKind() string {
+	return def.Kind
+}
+
+func (def *SchemaExtensionDefinition) GetLoc() *Location {
+	return def.Loc
+}
+
+func (def *SchemaExtensionDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
+func (def *SchemaExtensionDefinition) GetVariableDefinitions() []*VariableDefinition {
+	return []*VariableDefinition{}
+}
+
+func (def *SchemaExtensionDefinition) GetSelectionSet() *SelectionSet {
+	return &SelectionSet{}
+}
+
+func (def *SchemaExtensionDefinition) GetOperation() string {
+	return ""
+}
+
 // DirectiveDefinition implements Node, Definition
 type DirectiveDefinition struct {
 	Kind        string
@@ -194,6 +263,7 @@ type DirectiveDefinition struct {
 	Description *StringValue
 	Arguments   []*InputValueDefinition
 	Locations   []*Name
+	Comments    *CommentGroup
 }
 
 func NewDirectiveDefinition(def *DirectiveDefinition) *DirectiveDefinition {
@@ -207,6 +277,7 @@ func NewDirectiveDefinition(def *DirectiveDefinition) *DirectiveDefinition {
 		Description: def.Description,
 		Arguments:   def.Arguments,
 		Locations:   def.Locations,
+		Comments:    def.Comments,
 	}
 }
 
@@ -218,6 +289,10 @@ func (def *DirectiveDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *DirectiveDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *DirectiveDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
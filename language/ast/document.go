@@ -9,6 +9,7 @@ type Document struct {
 	Kind        string
 	Loc         *Location
 	Definitions []Node
+	Comments    *CommentGroup
 }
 
 func NewDocument(d *Document) *Document {
@@ -19,6 +20,7 @@ func NewDocument(d *Document) *Document {
 		Kind:        kinds.Document,
 		Loc:         d.Loc,
 		Definitions: d.Definitions,
+		Comments:    d.Comments,
 	}
 }
 
@@ -29,3 +31,7 @@ func (node *Document) GetKind() string {
 func (node *Document) GetLoc() *Location {
 	return node.Loc
 }
+
+func (node *Document) GetComments() *CommentGroup {
+	return node.Comments
+}
@@ -0,0 +1,202 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dagger/graphql/language/kinds"
+)
+
+// jsonKinds maps the kinds of the nodes to the kinds of graphql-js, for those
+// which differ.
+var jsonKinds = map[string]string{
+	kinds.Named:                     "NamedType",
+	kinds.List:                      "ListType",
+	kinds.NonNull:                   "NonNullType",
+	kinds.ScalarDefinition:          "ScalarTypeDefinition",
+	kinds.ObjectDefinition:          "ObjectTypeDefinition",
+	kinds.InterfaceDefinition:       "InterfaceTypeDefinition",
+	kinds.UnionDefinition:           "UnionTypeDefinition",
+	kinds.EnumDefinition:            "EnumTypeDefinition",
+	kinds.InputObjectDefinition:     "InputObjectTypeDefinition",
+	kinds.SchemaExtensionDefinition: "SchemaExtension",
+}
+
+var (
+	// nodeKinds maps the kinds of graphql-js, and the kinds of the nodes, to
+	// the kinds of the nodes.
+	nodeKinds = map[string]string{}
+	// nodeTypes maps the kinds of the nodes to their types.
+	nodeTypes = map[string]reflect.Type{}
+)
+
+func init() {
+	for _, node := range []Node{
+		NewName(nil), NewDocument(nil), NewOperationDefinition(nil), NewVariableDefinition(nil),
+		NewVariable(nil), NewSelectionSet(nil), NewField(nil), NewArgument(nil),
+		NewFragmentSpread(nil), NewInlineFragment(nil), NewFragmentDefinition(nil),
+		NewIntValue(nil), NewFloatValue(nil), NewStringValue(nil), NewBooleanValue(nil),
+		NewEnumValue(nil), NewListValue(nil), NewObjectValue(nil), NewObjectField(nil),
+		NewDirective(nil), NewNamed(nil), NewList(nil), NewNonNull(nil),
+		NewRequiredDesignator(nil), NewOptionalDesignator(nil), NewListNullability(nil),
+		NewSchemaDefinition(nil), NewOperationTypeDefinition(nil), NewScalarDefinition(nil),
+		NewObjectDefinition(nil), NewFieldDefinition(nil), NewInputValueDefinition(nil),
+		NewInterfaceDefinition(nil), NewUnionDefinition(nil), NewEnumDefinition(nil),
+		NewEnumValueDefinition(nil), NewInputObjectDefinition(nil),
+		NewTypeExtensionDefinition(nil), NewSchemaExtensionDefinition(nil), NewDirectiveDefinition(nil),
+	} {
+		kind := node.GetKind()
+		nodeKinds[kind] = kind
+		if jsonKind, ok := jsonKinds[kind]; ok {
+			nodeKinds[jsonKind] = kind
+		}
+		nodeTypes[kind] = reflect.TypeOf(node).Elem()
+	}
+}
+
+// MarshalJSON encodes the document as the AST JSON of graphql-js: the nodes
+// are objects with a "kind" discriminator and lower camel case fields, and
+// locations are objects with "start" and "end" offsets. Comments are kept
+// in "comments" fields.
+func (node *Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marshalValue(reflect.ValueOf(node)))
+}
+
+// UnmarshalJSON decodes a document encoded by MarshalJSON, or by graphql-js.
+// The locations of the decoded nodes have no Source.
+func (node *Document) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalNode(data)
+	if err != nil {
+		return err
+	}
+	doc, ok := decoded.(*Document)
+	if !ok {
+		return fmt.Errorf("ast: expected a Document, got a %v", decoded.GetKind())
+	}
+	*node = *doc
+	return nil
+}
+
+// jsonName returns the JSON name of the struct field name.
+func jsonName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// marshalValue returns the value to encode for v, nil if it is to be omitted.
+func marshalValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() || v.Type() == sourceType {
+			return nil
+		}
+		return marshalValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = marshalValue(v.Index(i))
+		}
+		return items
+	case reflect.Struct:
+		object := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Name
+			if name == "Kind" {
+				kind := v.Field(i).String()
+				if jsonKind, ok := jsonKinds[kind]; ok {
+					kind = jsonKind
+				}
+				object["kind"] = kind
+			} else if value := marshalValue(v.Field(i)); value != nil {
+				object[jsonName(name)] = value
+			}
+		}
+		return object
+	}
+	return v.Interface()
+}
+
+func unmarshalNode(data []byte) (Node, error) {
+	var object struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	kind, ok := nodeKinds[object.Kind]
+	if !ok {
+		return nil, fmt.Errorf("ast: unknown node kind %q", object.Kind)
+	}
+	v := reflect.New(nodeTypes[kind])
+	if err := unmarshalStruct(data, v.Elem()); err != nil {
+		return nil, err
+	}
+	v.Elem().FieldByName("Kind").SetString(kind)
+	return v.Interface().(Node), nil
+}
+
+func unmarshalStruct(data []byte, v reflect.Value) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		value, ok := object[jsonName(name)]
+		if !ok || name == "Kind" || v.Field(i).Type() == sourceType {
+			continue
+		}
+		if err := unmarshalValue(value, v.Field(i)); err != nil {
+			return fmt.Errorf("%v.%v: %v", v.Type().Name(), name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalValue(data json.RawMessage, v reflect.Value) error {
+	if string(data) == "null" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !reflect.PtrTo(v.Type().Elem()).Implements(nodeType) {
+			elem := reflect.New(v.Type().Elem())
+			if err := unmarshalStruct(data, elem.Elem()); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+		fallthrough
+	case reflect.Interface:
+		node, err := unmarshalNode(data)
+		if err != nil {
+			return err
+		}
+		if !reflect.TypeOf(node).AssignableTo(v.Type()) {
+			return fmt.Errorf("ast: unexpected node kind %q", node.GetKind())
+		}
+		v.Set(reflect.ValueOf(node))
+		return nil
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
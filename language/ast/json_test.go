@@ -0,0 +1,146 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/testutil"
+)
+
+func TestDocument_MarshalsToGraphQLJSAST(t *testing.T) {
+	astDoc := parse(t, `query Q($a: [Int!] = [1]) { f(a: $a) @d }`, parser.ParseOptions{})
+
+	b, err := json.Marshal(astDoc)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var results interface{}
+	if err := json.Unmarshal(b, &results); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	loc := func(start, end float64) map[string]interface{} {
+		return map[string]interface{}{"start": start, "end": end}
+	}
+	name := func(value string, start, end float64) map[string]interface{} {
+		return map[string]interface{}{"kind": "Name", "value": value, "loc": loc(start, end)}
+	}
+	expected := map[string]interface{}{
+		"kind": "Document",
+		"loc":  loc(0, 41),
+		"definitions": []interface{}{map[string]interface{}{
+			"kind":      "OperationDefinition",
+			"loc":       loc(0, 41),
+			"operation": "query",
+			"name":      name("Q", 6, 7),
+			"variableDefinitions": []interface{}{map[string]interface{}{
+				"kind": "VariableDefinition",
+				"loc":  loc(8, 24),
+				"variable": map[string]interface{}{
+					"kind": "Variable",
+					"loc":  loc(8, 10),
+					"name": name("a", 9, 10),
+				},
+				"type": map[string]interface{}{
+					"kind": "ListType",
+					"loc":  loc(12, 18),
+					"type": map[string]interface{}{
+						"kind": "NonNullType",
+						"loc":  loc(13, 17),
+						"type": map[string]interface{}{
+							"kind": "NamedType",
+							"loc":  loc(13, 16),
+							"name": name("Int", 13, 16),
+						},
+					},
+				},
+				"defaultValue": map[string]interface{}{
+					"kind": "ListValue",
+					"loc":  loc(21, 24),
+					"values": []interface{}{map[string]interface{}{
+						"kind":  "IntValue",
+						"loc":   loc(22, 23),
+						"value": "1",
+					}},
+				},
+				"directives": []interface{}{},
+			}},
+			"directives": []interface{}{},
+			"selectionSet": map[string]interface{}{
+				"kind": "SelectionSet",
+				"loc":  loc(26, 41),
+				"selections": []interface{}{map[string]interface{}{
+					"kind": "Field",
+					"loc":  loc(28, 39),
+					"name": name("f", 28, 29),
+					"arguments": []interface{}{map[string]interface{}{
+						"kind": "Argument",
+						"loc":  loc(30, 35),
+						"name": name("a", 30, 31),
+						"value": map[string]interface{}{
+							"kind": "Variable",
+							"loc":  loc(33, 35),
+							"name": name("a", 34, 35),
+						},
+					}},
+					"directives": []interface{}{map[string]interface{}{
+						"kind":      "Directive",
+						"loc":       loc(37, 39),
+						"name":      name("d", 38, 39),
+						"arguments": []interface{}{},
+					}},
+				}},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestDocument_RoundTripsThroughJSON(t *testing.T) {
+	for _, file := range []string{"../../kitchen-sink.graphql", "../../schema-kitchen-sink.graphql"} {
+		astDoc := parse(t, loadFile(t, file), parser.ParseOptions{KeepComments: true})
+
+		b, err := json.Marshal(astDoc)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		results := &ast.Document{}
+		if err := json.Unmarshal(b, results); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if !ast.Equal(astDoc, results, false) {
+			t.Fatalf("expected the decoded document of %v to be equal to the document", file)
+		}
+
+		// the decoded document is the same but for the sources of its locations
+		astDoc = parse(t, loadFile(t, file), parser.ParseOptions{KeepComments: true, NoSource: true})
+		if !reflect.DeepEqual(astDoc, results) {
+			t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(astDoc, results))
+		}
+	}
+}
+
+func TestDocument_UnmarshalReportsInvalidNodes(t *testing.T) {
+	tests := []struct {
+		json        string
+		expectedErr string
+	}{
+		{`{"kind": "Field"}`, `ast: expected a Document, got a Field`},
+		{`{"kind": "Unknown"}`, `ast: unknown node kind "Unknown"`},
+		{`{"kind": "Document", "definitions": [{"kind": "OperationDefinition", "selectionSet": {"kind": "SelectionSet", "selections": [{"kind": "Name"}]}}]}`,
+			`Document.Definitions: OperationDefinition.SelectionSet: SelectionSet.Selections: ast: unexpected node kind "Name"`},
+		{`{"kind": "Document", "definitions": [{"kind": "OperationDefinition", "name": {"kind": "IntValue"}}]}`,
+			`Document.Definitions: OperationDefinition.Name: ast: unexpected node kind "IntValue"`},
+	}
+	for _, test := range tests {
+		err := json.Unmarshal([]byte(test.json), &ast.Document{})
+		if err == nil || err.Error() != test.expectedErr {
+			t.Fatalf("Unexpected error for %v, expected: %v, got: %v", test.json, test.expectedErr, err)
+		}
+	}
+}
@@ -30,6 +30,9 @@ var _ Node = (*Directive)(nil)
 var _ Node = (*Named)(nil)
 var _ Node = (*List)(nil)
 var _ Node = (*NonNull)(nil)
+var _ Node = (*RequiredDesignator)(nil)
+var _ Node = (*OptionalDesignator)(nil)
+var _ Node = (*ListNullability)(nil)
 var _ Node = (*SchemaDefinition)(nil)
 var _ Node = (*OperationTypeDefinition)(nil)
 var _ Node = (*ScalarDefinition)(nil)
@@ -42,4 +45,5 @@ var _ Node = (*EnumDefinition)(nil)
 var _ Node = (*EnumValueDefinition)(nil)
 var _ Node = (*InputObjectDefinition)(nil)
 var _ Node = (*TypeExtensionDefinition)(nil)
+var _ Node = (*SchemaExtensionDefinition)(nil)
 var _ Node = (*DirectiveDefinition)(nil)
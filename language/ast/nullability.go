@@ -0,0 +1,93 @@
+package ast
+
+import (
+	"github.com/dagger/graphql/language/kinds"
+)
+
+// Nullability is the client controlled nullability designator of a field,
+// such as `!`, `?` or `[!]?`. It is only parsed with the
+// ExperimentalClientControlledNullability parse option.
+type Nullability interface {
+	GetKind() string
+	GetLoc() *Location
+}
+
+// Ensure that all nullability types implements Nullability interface
+var _ Nullability = (*RequiredDesignator)(nil)
+var _ Nullability = (*OptionalDesignator)(nil)
+var _ Nullability = (*ListNullability)(nil)
+
+// RequiredDesignator implements Node, Nullability. It is the `!` designator,
+// which makes the field non-null. Element is the nullability of the items
+// of the list, if the designator follows brackets.
+type RequiredDesignator struct {
+	Kind    string
+	Loc     *Location
+	Element *ListNullability
+}
+
+func NewRequiredDesignator(n *RequiredDesignator) *RequiredDesignator {
+	if n == nil {
+		n = &RequiredDesignator{}
+	}
+	n.Kind = kinds.RequiredDesignator
+	return n
+}
+
+func (n *RequiredDesignator) GetKind() string {
+	return n.Kind
+}
+
+func (n *RequiredDesignator) GetLoc() *Location {
+	return n.Loc
+}
+
+// OptionalDesignator implements Node, Nullability. It is the `?` designator,
+// which makes the field nullable. Element is the nullability of the items of
+// the list, if the designator follows brackets.
+type OptionalDesignator struct {
+	Kind    string
+	Loc     *Location
+	Element *ListNullability
+}
+
+func NewOptionalDesignator(n *OptionalDesignator) *OptionalDesignator {
+	if n == nil {
+		n = &OptionalDesignator{}
+	}
+	n.Kind = kinds.OptionalDesignator
+	return n
+}
+
+func (n *OptionalDesignator) GetKind() string {
+	return n.Kind
+}
+
+func (n *OptionalDesignator) GetLoc() *Location {
+	return n.Loc
+}
+
+// ListNullability implements Node, Nullability. It is a pair of brackets,
+// whose Element is the nullability of the items of the list, or nil if the
+// brackets are empty.
+type ListNullability struct {
+	Kind    string
+	Loc     *Location
+	Element Nullability
+}
+
+func NewListNullability(n *ListNullability) *ListNullability {
+	if n == nil {
+		n = &ListNullability{}
+	}
+	n.Kind = kinds.ListNullability
+	return n
+}
+
+func (n *ListNullability) GetKind() string {
+	return n.Kind
+}
+
+func (n *ListNullability) GetLoc() *Location {
+	return n.Loc
+}
@@ -20,8 +20,10 @@ type Field struct {
 	Alias        *Name
 	Name         *Name
 	Arguments    []*Argument
+	Nullability  Nullability
 	Directives   []*Directive
 	SelectionSet *SelectionSet
+	Comments     *CommentGroup
 }
 
 func NewField(f *Field) *Field {
@@ -40,6 +42,10 @@ func (f *Field) GetLoc() *Location {
 	return f.Loc
 }
 
+func (f *Field) GetComments() *CommentGroup {
+	return f.Comments
+}
+
 func (f *Field) GetSelectionSet() *SelectionSet {
 	return f.SelectionSet
 }
@@ -50,6 +56,7 @@ type FragmentSpread struct {
 	Loc        *Location
 	Name       *Name
 	Directives []*Directive
+	Comments   *CommentGroup
 }
 
 func NewFragmentSpread(fs *FragmentSpread) *FragmentSpread {
@@ -61,6 +68,7 @@ func NewFragmentSpread(fs *FragmentSpread) *FragmentSpread {
 		Loc:        fs.Loc,
 		Name:       fs.Name,
 		Directives: fs.Directives,
+		Comments:   fs.Comments,
 	}
 }
 
@@ -72,6 +80,10 @@ func (fs *FragmentSpread) GetLoc() *Location {
 	return fs.Loc
 }
 
+func (fs *FragmentSpread) GetComments() *CommentGroup {
+	return fs.Comments
+}
+
 func (fs *FragmentSpread) GetSelectionSet() *SelectionSet {
 	return nil
 }
@@ -83,6 +95,7 @@ type InlineFragment struct {
 	TypeCondition *Named
 	Directives    []*Directive
 	SelectionSet  *SelectionSet
+	Comments      *CommentGroup
 }
 
 func NewInlineFragment(f *InlineFragment) *InlineFragment {
@@ -95,6 +108,7 @@ func NewInlineFragment(f *InlineFragment) *InlineFragment {
 		TypeCondition: f.TypeCondition,
 		Directives:    f.Directives,
 		SelectionSet:  f.SelectionSet,
+		Comments:      f.Comments,
 	}
 }
 
@@ -106,6 +120,10 @@ func (f *InlineFragment) GetLoc() *Location {
 	return f.Loc
 }
 
+func (f *InlineFragment) GetComments() *CommentGroup {
+	return f.Comments
+}
+
 func (f *InlineFragment) GetSelectionSet() *SelectionSet {
 	return f.SelectionSet
 }
@@ -36,6 +36,7 @@ type TypeSystemDefinition interface {
 var _ TypeSystemDefinition = (*SchemaDefinition)(nil)
 var _ TypeSystemDefinition = (TypeDefinition)(nil)
 var _ TypeSystemDefinition = (*TypeExtensionDefinition)(nil)
+var _ TypeSystemDefinition = (*SchemaExtensionDefinition)(nil)
 var _ TypeSystemDefinition = (*DirectiveDefinition)(nil)
 
 // SchemaDefinition implements Node, Definition
@@ -44,6 +45,7 @@ type SchemaDefinition struct {
 	Loc            *Location
 	Directives     []*Directive
 	OperationTypes []*OperationTypeDefinition
+	Comments       *CommentGroup
 }
 
 func NewSchemaDefinition(def *SchemaDefinition) *SchemaDefinition {
@@ -55,6 +57,7 @@ func NewSchemaDefinition(def *SchemaDefinition) *SchemaDefinition {
 		Loc:            def.Loc,
 		Directives:     def.Directives,
 		OperationTypes: def.OperationTypes,
+		Comments:       def.Comments,
 	}
 }
 
@@ -66,6 +69,10 @@ func (def *SchemaDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *SchemaDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *SchemaDefinition) GetVariableDefinitions() []*VariableDefinition {
 	return []*VariableDefinition{}
 }
@@ -84,6 +91,7 @@ type OperationTypeDefinition struct {
 	Loc       *Location
 	Operation string
 	Type      *Named
+	Comments  *CommentGroup
 }
 
 func NewOperationTypeDefinition(def *OperationTypeDefinition) *OperationTypeDefinition {
@@ -95,6 +103,7 @@ func NewOperationTypeDefinition(def *OperationTypeDefinition) *OperationTypeDefi
 		Loc:       def.Loc,
 		Operation: def.Operation,
 		Type:      def.Type,
+		Comments:  def.Comments,
 	}
 }
 
@@ -106,6 +115,10 @@ func (def *OperationTypeDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *OperationTypeDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 // ScalarDefinition implements Node, Definition
 type ScalarDefinition struct {
 	Kind        string
@@ -113,6 +126,7 @@ type ScalarDefinition struct {
 	Description *StringValue
 	Name        *Name
 	Directives  []*Directive
+	Comments    *CommentGroup
 }
 
 func NewScalarDefinition(def *ScalarDefinition) *ScalarDefinition {
@@ -125,6 +139,7 @@ func NewScalarDefinition(def *ScalarDefinition) *ScalarDefinition {
 		Description: def.Description,
 		Name:        def.Name,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -136,6 +151,10 @@ func (def *ScalarDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *ScalarDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *ScalarDefinition) GetName() *Name {
 	return def.Name
 }
@@ -165,6 +184,7 @@ type ObjectDefinition struct {
 	Interfaces  []*Named
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Comments    *CommentGroup
 }
 
 func NewObjectDefinition(def *ObjectDefinition) *ObjectDefinition {
@@ -179,6 +199,7 @@ func NewObjectDefinition(def *ObjectDefinition) *ObjectDefinition {
 		Interfaces:  def.Interfaces,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -190,6 +211,10 @@ func (def *ObjectDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *ObjectDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *ObjectDefinition) GetName() *Name {
 	return def.Name
 }
@@ -219,6 +244,7 @@ type FieldDefinition struct {
 	Arguments   []*InputValueDefinition
 	Type        Type
 	Directives  []*Directive
+	Comments    *CommentGroup
 }
 
 func NewFieldDefinition(def *FieldDefinition) *FieldDefinition {
@@ -233,6 +259,7 @@ func NewFieldDefinition(def *FieldDefinition) *FieldDefinition {
 		Arguments:   def.Arguments,
 		Type:        def.Type,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -244,6 +271,10 @@ func (def *FieldDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *FieldDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *FieldDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -257,6 +288,7 @@ type InputValueDefinition struct {
 	Type         Type
 	DefaultValue Value
 	Directives   []*Directive
+	Comments     *CommentGroup
 }
 
 func NewInputValueDefinition(def *InputValueDefinition) *InputValueDefinition {
@@ -271,6 +303,7 @@ func NewInputValueDefinition(def *InputValueDefinition) *InputValueDefinition {
 		Type:         def.Type,
 		DefaultValue: def.DefaultValue,
 		Directives:   def.Directives,
+		Comments:     def.Comments,
 	}
 }
 
@@ -282,6 +315,10 @@ func (def *InputValueDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InputValueDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *InputValueDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -294,6 +331,7 @@ type InterfaceDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Comments    *CommentGroup
 }
 
 func NewInterfaceDefinition(def *InterfaceDefinition) *InterfaceDefinition {
@@ -307,6 +345,7 @@ func NewInterfaceDefinition(def *InterfaceDefinition) *InterfaceDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -318,6 +357,10 @@ func (def *InterfaceDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InterfaceDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *InterfaceDefinition) GetName() *Name {
 	return def.Name
 }
@@ -346,6 +389,7 @@ type UnionDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Types       []*Named
+	Comments    *CommentGroup
 }
 
 func NewUnionDefinition(def *UnionDefinition) *UnionDefinition {
@@ -359,6 +403,7 @@ func NewUnionDefinition(def *UnionDefinition) *UnionDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Types:       def.Types,
+		Comments:    def.Comments,
 	}
 }
 
@@ -370,6 +415,10 @@ func (def *UnionDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *UnionDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *UnionDefinition) GetName() *Name {
 	return def.Name
 }
@@ -398,6 +447,7 @@ type EnumDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Values      []*EnumValueDefinition
+	Comments    *CommentGroup
 }
 
 func NewEnumDefinition(def *EnumDefinition) *EnumDefinition {
@@ -411,6 +461,7 @@ func NewEnumDefinition(def *EnumDefinition) *EnumDefinition {
 		Description: def.Description,
 		Directives:  def.Directives,
 		Values:      def.Values,
+		Comments:    def.Comments,
 	}
 }
 
@@ -422,6 +473,10 @@ func (def *EnumDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *EnumDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *EnumDefinition) GetName() *Name {
 	return def.Name
 }
@@ -449,6 +504,7 @@ type EnumValueDefinition struct {
 	Name        *Name
 	Description *StringValue
 	Directives  []*Directive
+	Comments    *CommentGroup
 }
 
 func NewEnumValueDefinition(def *EnumValueDefinition) *EnumValueDefinition {
@@ -461,6 +517,7 @@ func NewEnumValueDefinition(def *EnumValueDefinition) *EnumValueDefinition {
 		Name:        def.Name,
 		Description: def.Description,
 		Directives:  def.Directives,
+		Comments:    def.Comments,
 	}
 }
 
@@ -472,6 +529,10 @@ func (def *EnumValueDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *EnumValueDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *EnumValueDefinition) GetDescription() *StringValue {
 	return def.Description
 }
@@ -484,6 +545,7 @@ type InputObjectDefinition struct {
 	Description *StringValue
 	Directives  []*Directive
 	Fields      []*InputValueDefinition
+	Comments    *CommentGroup
 }
 
 func NewInputObjectDefinition(def *InputObjectDefinition) *InputObjectDefinition {
@@ -497,6 +559,7 @@ func NewInputObjectDefinition(def *InputObjectDefinition) *InputObjectDefinition
 		Description: def.Description,
 		Directives:  def.Directives,
 		Fields:      def.Fields,
+		Comments:    def.Comments,
 	}
 }
 
@@ -508,6 +571,10 @@ func (def *InputObjectDefinition) GetLoc() *Location {
 	return def.Loc
 }
 
+func (def *InputObjectDefinition) GetComments() *CommentGroup {
+	return def.Comments
+}
+
 func (def *InputObjectDefinition) GetName() *Name {
 	return def.Name
 }
@@ -36,6 +36,11 @@ const (
 	List    = "List"    // previously ListType
 	NonNull = "NonNull" // previously NonNull
 
+	// Client Controlled Nullability
+	RequiredDesignator = "RequiredDesignator"
+	OptionalDesignator = "OptionalDesignator"
+	ListNullability    = "ListNullability"
+
 	// Type System Definitions
 	SchemaDefinition        = "SchemaDefinition"
 	OperationTypeDefinition = "OperationTypeDefinition"
@@ -52,7 +57,8 @@ const (
 	InputObjectDefinition = "InputObjectDefinition" // previously InputObjectTypeDefinition
 
 	// Types Extensions
-	TypeExtensionDefinition = "TypeExtensionDefinition"
+	TypeExtensionDefinition   = "TypeExtensionDefinition"
+	SchemaExtensionDefinition = "SchemaExtensionDefinition"
 
 	// Directive Definitions
 	DirectiveDefinition = "DirectiveDefinition"
@@ -34,29 +34,33 @@ const (
 	STRING
 	BLOCK_STRING
 	AMP
+	COMMENT
+	QUESTION_MARK
 )
 
 var tokenDescription = map[TokenKind]string{
-	EOF:          "EOF",
-	BANG:         "!",
-	DOLLAR:       "$",
-	PAREN_L:      "(",
-	PAREN_R:      ")",
-	SPREAD:       "...",
-	COLON:        ":",
-	EQUALS:       "=",
-	AT:           "@",
-	BRACKET_L:    "[",
-	BRACKET_R:    "]",
-	BRACE_L:      "{",
-	PIPE:         "|",
-	BRACE_R:      "}",
-	NAME:         "Name",
-	INT:          "Int",
-	FLOAT:        "Float",
-	STRING:       "String",
-	BLOCK_STRING: "BlockString",
-	AMP:          "&",
+	EOF:           "EOF",
+	BANG:          "!",
+	DOLLAR:        "$",
+	PAREN_L:       "(",
+	PAREN_R:       ")",
+	SPREAD:        "...",
+	COLON:         ":",
+	EQUALS:        "=",
+	AT:            "@",
+	BRACKET_L:     "[",
+	BRACKET_R:     "]",
+	BRACE_L:       "{",
+	PIPE:          "|",
+	BRACE_R:       "}",
+	NAME:          "Name",
+	INT:           "Int",
+	FLOAT:         "Float",
+	STRING:        "String",
+	BLOCK_STRING:  "BlockString",
+	AMP:           "&",
+	COMMENT:       "Comment",
+	QUESTION_MARK: "?",
 }
 
 func (kind TokenKind) String() string {
@@ -81,7 +85,8 @@ const (
 )
 
 // Token is a representation of a lexed Token. Value only appears for non-punctuation
-// tokens: NAME, INT, FLOAT, and STRING.
+// tokens: NAME, INT, FLOAT, STRING and COMMENT, whose value is the text following
+// the "#".
 type Token struct {
 	Kind  TokenKind
 	Start int
@@ -91,16 +96,52 @@ type Token struct {
 
 type Lexer func(resetPosition int) (Token, error)
 
+// Options configures a lexer. The limits are disabled when zero.
+type Options struct {
+	// MaxTokens is the maximum number of tokens of the document, EOF and
+	// comments excluded.
+	MaxTokens int
+
+	// MaxLength is the maximum length of the document, in bytes.
+	MaxLength int
+
+	// KeepComments makes the lexer return COMMENT tokens instead of skipping
+	// the comments like whitespace.
+	KeepComments bool
+
+	// QuestionMark makes the lexer return QUESTION_MARK tokens for "?",
+	// which is otherwise an unexpected character.
+	QuestionMark bool
+}
+
 func Lex(s *source.Source) Lexer {
+	return LexWithOptions(s, Options{})
+}
+
+// LexWithOptions is like Lex, but the returned lexer fails with a
+// gqlerrors.LimitError once a limit of opts is exceeded.
+func LexWithOptions(s *source.Source, opts Options) Lexer {
 	var prevPosition int
+	// tokens are counted once, even when the parser looks ahead
+	tokens, lastStart := 0, -1
 	return func(resetPosition int) (Token, error) {
+		if opts.MaxLength > 0 && len(s.Body) > opts.MaxLength {
+			return Token{}, gqlerrors.NewLimitError(s, opts.MaxLength, "MaxLength", opts.MaxLength)
+		}
 		if resetPosition == 0 {
 			resetPosition = prevPosition
 		}
-		token, err := readToken(s, resetPosition)
+		token, err := readToken(s, resetPosition, opts)
 		if err != nil {
 			return token, err
 		}
+		if token.Kind != EOF && token.Kind != COMMENT && token.Start > lastStart {
+			tokens++
+			lastStart = token.Start
+		}
+		if opts.MaxTokens > 0 && tokens > opts.MaxTokens {
+			return Token{}, gqlerrors.NewLimitError(s, token.Start, "MaxTokens", opts.MaxTokens)
+		}
 		prevPosition = token.End
 		return token, nil
 	}
@@ -479,10 +520,10 @@ func printCharCode(code rune) string {
 	return fmt.Sprintf(`"\\u%04X"`, code)
 }
 
-func readToken(s *source.Source, fromPosition int) (Token, error) {
+func readToken(s *source.Source, fromPosition int, opts Options) (Token, error) {
 	body := s.Body
 	bodyLength := len(body)
-	position, runePosition := positionAfterWhitespace(body, fromPosition)
+	position, runePosition := positionAfterWhitespace(body, fromPosition, opts.KeepComments)
 	if position >= bodyLength {
 		return makeToken(EOF, position, position, ""), nil
 	}
@@ -494,6 +535,9 @@ func readToken(s *source.Source, fromPosition int) (Token, error) {
 	}
 
 	switch code {
+	// #
+	case '#':
+		return readComment(s, position), nil
 	// !
 	case '!':
 		return makeToken(BANG, position, position+1, ""), nil
@@ -541,6 +585,11 @@ func readToken(s *source.Source, fromPosition int) (Token, error) {
 	// }
 	case '}':
 		return makeToken(BRACE_R, position, position+1, ""), nil
+	// ?
+	case '?':
+		if opts.QuestionMark {
+			return makeToken(QUESTION_MARK, position, position+1, ""), nil
+		}
 	// A-Z
 	case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N',
 		'O', 'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z':
@@ -593,9 +642,9 @@ func runeAt(body []byte, position int) (code rune, charWidth int) {
 
 // Reads from body starting at startPosition until it finds a non-whitespace
 // or commented character, then returns the position of that character for lexing.
-// lexing.
+// lexing. Comments are only skipped when keepComments is false.
 // Returns both byte positions and rune position
-func positionAfterWhitespace(body []byte, startPosition int) (position int, runePosition int) {
+func positionAfterWhitespace(body []byte, startPosition int, keepComments bool) (position int, runePosition int) {
 	bodyLength := len(body)
 	position = startPosition
 	runePosition = startPosition
@@ -615,22 +664,10 @@ func positionAfterWhitespace(body []byte, startPosition int) (position int, rune
 				code == 0x002C {
 				position += n
 				runePosition++
-			} else if code == 35 { // #
-				position += n
-				runePosition++
-				for {
-					code, n := runeAt(body, position)
-					if position < bodyLength &&
-						code != 0 &&
-						// SourceCharacter but not LineTerminator
-						(code > 0x001F || code == 0x0009) && code != 0x000A && code != 0x000D {
-						position += n
-						runePosition++
-						continue
-					} else {
-						break
-					}
-				}
+			} else if code == 35 && !keepComments { // #
+				end, runes := commentEnd(body, position+n)
+				position = end
+				runePosition += 1 + runes
 			} else {
 				break
 			}
@@ -642,6 +679,31 @@ func positionAfterWhitespace(body []byte, startPosition int) (position int, rune
 	return position, runePosition
 }
 
+// Returns the byte position of the end of the comment whose text starts at
+// position, and the number of runes of the text.
+func commentEnd(body []byte, position int) (end int, runes int) {
+	bodyLength := len(body)
+	for {
+		code, n := runeAt(body, position)
+		if position < bodyLength &&
+			code != 0 &&
+			// SourceCharacter but not LineTerminator
+			(code > 0x001F || code == 0x0009) && code != 0x000A && code != 0x000D {
+			position += n
+			runes++
+			continue
+		}
+		return position, runes
+	}
+}
+
+// Reads a comment from the source, start being the position of the "#".
+// #[\u0009\u0020-\uFFFF]*
+func readComment(s *source.Source, start int) Token {
+	end, _ := commentEnd(s.Body, start+1)
+	return makeToken(COMMENT, start, end, string(s.Body[start+1:end]))
+}
+
 func GetTokenDesc(token Token) string {
 	if token.Value == "" {
 		return token.Kind.String()
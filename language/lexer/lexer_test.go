@@ -1,9 +1,11 @@
 package lexer
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/dagger/graphql/gqlerrors"
 	"github.com/dagger/graphql/language/source"
 )
 
@@ -1069,3 +1071,61 @@ func TestLexer_ReportsUsefulInformationForDashesInNames(t *testing.T) {
 		t.Fatalf("unexpected error, token:%v\nexpected:\n%v\n\ngot:\n%v", token, errExpected, err.Error())
 	}
 }
+
+func TestLexer_ReportsExceededLimits(t *testing.T) {
+	lexer := LexWithOptions(createSource("{ a b }"), Options{MaxTokens: 3})
+	for i := 0; i < 3; i++ {
+		if _, err := lexer(0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// lexing a token again does not count it twice
+	if _, err := lexer(4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	errExpected := `Syntax Error GraphQL (1:7) Document exceeds MaxTokens of 3.
+
+1: { a b }
+         ^
+`
+	_, err := lexer(0)
+	if err == nil || err.Error() != errExpected {
+		t.Fatalf("unexpected error.\nexpected:\n%v\n\ngot:\n%v", errExpected, err)
+	}
+	var limitErr *gqlerrors.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "MaxTokens" || limitErr.Max != 3 {
+		t.Fatalf("expected a LimitError, got %#v", err)
+	}
+
+	lexer = LexWithOptions(createSource("{ a b }"), Options{MaxLength: 6})
+	if _, err := lexer(0); !errors.As(err, &limitErr) || limitErr.Limit != "MaxLength" {
+		t.Fatalf("expected a LimitError, got %v", err)
+	}
+}
+
+func TestLexer_KeepsComments(t *testing.T) {
+	lexer := LexWithOptions(createSource("# first\n{ a # second\n}"), Options{KeepComments: true, MaxTokens: 3})
+	expected := []Token{
+		{Kind: COMMENT, Start: 0, End: 7, Value: " first"},
+		{Kind: BRACE_L, Start: 8, End: 9},
+		{Kind: NAME, Start: 10, End: 11, Value: "a"},
+		{Kind: COMMENT, Start: 12, End: 20, Value: " second"},
+		{Kind: BRACE_R, Start: 21, End: 22},
+		{Kind: EOF, Start: 22, End: 22},
+	}
+	tokens := []Token{}
+	for {
+		// comments do not count towards MaxTokens
+		token, err := lexer(0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tokens = append(tokens, token)
+		if token.Kind == EOF {
+			break
+		}
+	}
+	if !reflect.DeepEqual(expected, tokens) {
+		t.Fatalf("unexpected tokens, expected: %v, got: %v", expected, tokens)
+	}
+}
@@ -0,0 +1,331 @@
+// Package normalize computes the canonical form of GraphQL operations, so that
+// operations of the same shape can be grouped or compared whatever their
+// formatting, their literal arguments or the order of their selections.
+package normalize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/printer"
+)
+
+// Operation returns the canonical form of the operation of doc named
+// operationName, or of its only operation if operationName is empty:
+//   - fragment spreads are inlined as inline fragments, and fragment
+//     definitions are dropped,
+//   - selections, arguments, directives and variable definitions are sorted,
+//   - int and float literals are replaced with 0, strings with "", lists
+//     with [] and input objects with {}; booleans, enum values and variables
+//     are kept,
+//   - locations and comments are dropped.
+//
+// doc is not modified.
+func Operation(doc *ast.Document, operationName string) (*ast.OperationDefinition, error) {
+	n := &normalizer{
+		fragments: map[string]*ast.FragmentDefinition{},
+		spreading: map[string]bool{},
+	}
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" && operation != nil {
+				return nil, errors.New("Must provide operation name if query contains multiple operations.")
+			}
+			if operationName == "" || definition.Name != nil && definition.Name.Value == operationName {
+				operation = definition
+			}
+		case *ast.FragmentDefinition:
+			if definition.Name != nil {
+				n.fragments[definition.Name.Value] = definition
+			}
+		}
+	}
+	if operation == nil {
+		if operationName != "" {
+			return nil, fmt.Errorf(`Unknown operation named "%v".`, operationName)
+		}
+		return nil, errors.New("Must provide an operation.")
+	}
+
+	selectionSet, err := n.selectionSet(operation.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewOperationDefinition(&ast.OperationDefinition{
+		Operation:           operation.Operation,
+		Name:                name(operation.Name),
+		VariableDefinitions: n.variableDefinitions(operation.VariableDefinitions),
+		Directives:          n.directives(operation.Directives),
+		SelectionSet:        selectionSet,
+	}), nil
+}
+
+// String returns the canonical form of the operation, as returned by
+// Operation, printed on a single line.
+func String(doc *ast.Document, operationName string) (string, error) {
+	operation, err := Operation(doc, operationName)
+	if err != nil {
+		return "", err
+	}
+	return printer.Format(operation, printer.Options{Compact: true}), nil
+}
+
+// Signature returns the hex encoded SHA-256 hash of the canonical form of the
+// operation, as returned by String.
+func Signature(doc *ast.Document, operationName string) (string, error) {
+	str, err := String(doc, operationName)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(str))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type normalizer struct {
+	fragments map[string]*ast.FragmentDefinition
+	// spreading holds the fragments being inlined, to detect cycles.
+	spreading map[string]bool
+}
+
+// sortByPrinted sorts the n nodes returned by node according to their
+// printed form.
+func sortByPrinted(n int, node func(i int) ast.Node, swap func(i, j int)) {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = printer.Format(node(i), printer.Options{Compact: true})
+	}
+	sort.Sort(byKey{keys, swap})
+}
+
+type byKey struct {
+	keys []string
+	swap func(i, j int)
+}
+
+func (s byKey) Len() int           { return len(s.keys) }
+func (s byKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s byKey) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
+
+func name(node *ast.Name) *ast.Name {
+	if node == nil {
+		return nil
+	}
+	return ast.NewName(&ast.Name{Value: node.Value})
+}
+
+func (n *normalizer) selectionSet(node *ast.SelectionSet) (*ast.SelectionSet, error) {
+	if node == nil {
+		return nil, nil
+	}
+	selections := []ast.Selection{}
+	for _, selection := range node.Selections {
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			var field *ast.Field
+			if field, err = n.field(selection); err == nil {
+				selections = append(selections, field)
+			}
+		case *ast.InlineFragment:
+			var fragment *ast.InlineFragment
+			if fragment, err = n.inlineFragment(selection); err == nil {
+				selections = append(selections, fragment)
+			}
+		case *ast.FragmentSpread:
+			var fragment *ast.InlineFragment
+			if fragment, err = n.fragmentSpread(selection); err == nil {
+				selections = append(selections, fragment)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortByPrinted(len(selections), func(i int) ast.Node {
+		return selections[i].(ast.Node)
+	}, func(i, j int) {
+		selections[i], selections[j] = selections[j], selections[i]
+	})
+	return ast.NewSelectionSet(&ast.SelectionSet{
+		Selections: selections,
+	}), nil
+}
+
+func (n *normalizer) field(node *ast.Field) (*ast.Field, error) {
+	selectionSet, err := n.selectionSet(node.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewField(&ast.Field{
+		Alias:        name(node.Alias),
+		Name:         name(node.Name),
+		Arguments:    n.arguments(node.Arguments),
+		Nullability:  nullability(node.Nullability),
+		Directives:   n.directives(node.Directives),
+		SelectionSet: selectionSet,
+	}), nil
+}
+
+func (n *normalizer) inlineFragment(node *ast.InlineFragment) (*ast.InlineFragment, error) {
+	selectionSet, err := n.selectionSet(node.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewInlineFragment(&ast.InlineFragment{
+		TypeCondition: named(node.TypeCondition),
+		Directives:    n.directives(node.Directives),
+		SelectionSet:  selectionSet,
+	}), nil
+}
+
+// fragmentSpread returns the inline fragment replacing the spread: it has the
+// type condition and the selections of the fragment, along with the directives
+// of both the spread and the fragment.
+func (n *normalizer) fragmentSpread(node *ast.FragmentSpread) (*ast.InlineFragment, error) {
+	fragmentName := ""
+	if node.Name != nil {
+		fragmentName = node.Name.Value
+	}
+	fragment, ok := n.fragments[fragmentName]
+	if !ok {
+		return nil, fmt.Errorf(`Unknown fragment "%v".`, fragmentName)
+	}
+	if n.spreading[fragmentName] {
+		return nil, fmt.Errorf(`Cannot spread fragment "%v" within itself.`, fragmentName)
+	}
+	n.spreading[fragmentName] = true
+	defer delete(n.spreading, fragmentName)
+
+	selectionSet, err := n.selectionSet(fragment.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+	directives := append(append([]*ast.Directive{}, node.Directives...), fragment.Directives...)
+	return ast.NewInlineFragment(&ast.InlineFragment{
+		TypeCondition: named(fragment.TypeCondition),
+		Directives:    n.directives(directives),
+		SelectionSet:  selectionSet,
+	}), nil
+}
+
+func (n *normalizer) arguments(nodes []*ast.Argument) []*ast.Argument {
+	args := []*ast.Argument{}
+	for _, node := range nodes {
+		args = append(args, ast.NewArgument(&ast.Argument{
+			Name:  name(node.Name),
+			Value: value(node.Value),
+		}))
+	}
+	sortByPrinted(len(args), func(i int) ast.Node {
+		return args[i]
+	}, func(i, j int) {
+		args[i], args[j] = args[j], args[i]
+	})
+	return args
+}
+
+func (n *normalizer) directives(nodes []*ast.Directive) []*ast.Directive {
+	directives := []*ast.Directive{}
+	for _, node := range nodes {
+		directives = append(directives, ast.NewDirective(&ast.Directive{
+			Name:      name(node.Name),
+			Arguments: n.arguments(node.Arguments),
+		}))
+	}
+	sortByPrinted(len(directives), func(i int) ast.Node {
+		return directives[i]
+	}, func(i, j int) {
+		directives[i], directives[j] = directives[j], directives[i]
+	})
+	return directives
+}
+
+func (n *normalizer) variableDefinitions(nodes []*ast.VariableDefinition) []*ast.VariableDefinition {
+	varDefs := []*ast.VariableDefinition{}
+	for _, node := range nodes {
+		var variable *ast.Variable
+		if node.Variable != nil {
+			variable = ast.NewVariable(&ast.Variable{Name: name(node.Variable.Name)})
+		}
+		varDefs = append(varDefs, ast.NewVariableDefinition(&ast.VariableDefinition{
+			Variable:     variable,
+			Type:         ttype(node.Type),
+			DefaultValue: value(node.DefaultValue),
+			Directives:   n.directives(node.Directives),
+		}))
+	}
+	sortByPrinted(len(varDefs), func(i int) ast.Node {
+		return varDefs[i]
+	}, func(i, j int) {
+		varDefs[i], varDefs[j] = varDefs[j], varDefs[i]
+	})
+	return varDefs
+}
+
+func named(node *ast.Named) *ast.Named {
+	if node == nil {
+		return nil
+	}
+	return ast.NewNamed(&ast.Named{Name: name(node.Name)})
+}
+
+func ttype(node ast.Type) ast.Type {
+	switch node := node.(type) {
+	case *ast.Named:
+		return named(node)
+	case *ast.List:
+		return ast.NewList(&ast.List{Type: ttype(node.Type)})
+	case *ast.NonNull:
+		return ast.NewNonNull(&ast.NonNull{Type: ttype(node.Type)})
+	}
+	return nil
+}
+
+func nullability(node ast.Nullability) ast.Nullability {
+	switch node := node.(type) {
+	case *ast.RequiredDesignator:
+		return ast.NewRequiredDesignator(&ast.RequiredDesignator{Element: listNullability(node.Element)})
+	case *ast.OptionalDesignator:
+		return ast.NewOptionalDesignator(&ast.OptionalDesignator{Element: listNullability(node.Element)})
+	case *ast.ListNullability:
+		return listNullability(node)
+	}
+	return nil
+}
+
+func listNullability(node *ast.ListNullability) *ast.ListNullability {
+	if node == nil {
+		return nil
+	}
+	return ast.NewListNullability(&ast.ListNullability{Element: nullability(node.Element)})
+}
+
+// value returns the placeholder standing for the literal node.
+func value(node ast.Value) ast.Value {
+	switch node := node.(type) {
+	case *ast.Variable:
+		return ast.NewVariable(&ast.Variable{Name: name(node.Name)})
+	case *ast.IntValue, *ast.FloatValue:
+		return ast.NewIntValue(&ast.IntValue{Value: "0"})
+	case *ast.StringValue:
+		return ast.NewStringValue(&ast.StringValue{Value: ""})
+	case *ast.BooleanValue:
+		return ast.NewBooleanValue(&ast.BooleanValue{Value: node.Value})
+	case *ast.EnumValue:
+		return ast.NewEnumValue(&ast.EnumValue{Value: node.Value})
+	case *ast.ListValue:
+		return ast.NewListValue(&ast.ListValue{Values: []ast.Value{}})
+	case *ast.ObjectValue:
+		return ast.NewObjectValue(&ast.ObjectValue{Fields: []*ast.ObjectField{}})
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package normalize_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/normalize"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/testutil"
+)
+
+func parse(t *testing.T, query string) *ast.Document {
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source: query,
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return astDoc
+}
+
+func TestString_PrintsTheCanonicalFormOfAnOperation(t *testing.T) {
+	astDoc := parse(t, `
+query Feed($first: Int = 10, $after: String) @live {
+  # the viewer
+  viewer { name, id }
+  feed(first: $first, after: $after, filter: {tags: ["a", "b"]}, order: DESC, since: 1.5) {
+    ...Story
+    ... on Ad @include(if: true) { url }
+  }
+}
+
+fragment Story on Story {
+  title(format: "short")
+  author { ...Author }
+}
+
+fragment Author on User @skip(if: false) {
+  id
+}
+`)
+
+	expected := `query Feed($after:String,$first:Int=0)@live{` +
+		`feed(after:$after,filter:{},first:$first,order:DESC,since:0){` +
+		`...on Ad@include(if:true){url} ` +
+		`...on Story{author{...on User@skip(if:false){id}} title(format:"")}` +
+		`} ` +
+		`viewer{id name}` +
+		`}`
+	results, err := normalize.String(astDoc, "")
+	if err != nil {
+		t.Fatalf("String failed: %v", err)
+	}
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestSignature_IsTheSameForOperationsOfTheSameShape(t *testing.T) {
+	first := parse(t, `
+query Q { a(x: 1, y: "foo") { ...F } b }
+fragment F on T { c d }
+`)
+	second := parse(t, `query Q{b,a(y:"bar",x:2){...on T{d,c}}}`)
+	third := parse(t, `query Q { a(x: 1, y: "foo") { c d } b }`)
+
+	firstSignature, err := normalize.Signature(first, "Q")
+	if err != nil {
+		t.Fatalf("Signature failed: %v", err)
+	}
+	secondSignature, err := normalize.Signature(second, "Q")
+	if err != nil {
+		t.Fatalf("Signature failed: %v", err)
+	}
+	thirdSignature, err := normalize.Signature(third, "Q")
+	if err != nil {
+		t.Fatalf("Signature failed: %v", err)
+	}
+	if firstSignature != secondSignature {
+		t.Fatalf("expected the same signatures, got %v and %v", firstSignature, secondSignature)
+	}
+	if firstSignature == thirdSignature {
+		t.Fatalf("expected different signatures, got %v", firstSignature)
+	}
+	if len(firstSignature) != 64 {
+		t.Fatalf("expected a hex encoded SHA-256 hash, got %v", firstSignature)
+	}
+}
+
+func TestOperation_DoesNotAlterAST(t *testing.T) {
+	astDoc := parse(t, `
+query Q($b: Int, $a: Int = 1) { b(x: 1) ...F a }
+fragment F on Query { c }
+`)
+	before := printer.Print(astDoc)
+
+	if _, err := normalize.Operation(astDoc, ""); err != nil {
+		t.Fatalf("Operation failed: %v", err)
+	}
+
+	after := printer.Print(astDoc)
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(before, after))
+	}
+}
+
+func TestOperation_SelectsTheOperation(t *testing.T) {
+	astDoc := parse(t, `
+query A { a }
+query B { b }
+`)
+
+	tests := []struct {
+		operationName string
+		expected      string
+		expectedErr   string
+	}{
+		{"A", "query A{a}", ""},
+		{"B", "query B{b}", ""},
+		{"", "", "Must provide operation name if query contains multiple operations."},
+		{"C", "", `Unknown operation named "C".`},
+	}
+	for _, test := range tests {
+		results, err := normalize.String(astDoc, test.operationName)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		if results != test.expected || errStr != test.expectedErr {
+			t.Fatalf("Unexpected result for %q, expected: %q, %q, got: %q, %q",
+				test.operationName, test.expected, test.expectedErr, results, errStr)
+		}
+	}
+}
+
+func TestOperation_ReportsInvalidFragmentSpreads(t *testing.T) {
+	tests := []struct {
+		query       string
+		expectedErr string
+	}{
+		{`{ ...F }`, `Unknown fragment "F".`},
+		{`{ ...F } fragment F on T { ...G } fragment G on T { ...F }`, `Cannot spread fragment "F" within itself.`},
+	}
+	for _, test := range tests {
+		_, err := normalize.Operation(parse(t, test.query), "")
+		if err == nil || err.Error() != test.expectedErr {
+			t.Fatalf("Unexpected error for %q, expected: %v, got: %v", test.query, test.expectedErr, err)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/dagger/graphql/gqlerrors"
 	"github.com/dagger/graphql/language/ast"
@@ -44,6 +48,45 @@ func init() {
 type ParseOptions struct {
 	NoLocation bool
 	NoSource   bool
+
+	// RecoverErrors makes Parse continue after syntax errors, skipping to the
+	// next selection or definition. Parse then returns the document made of
+	// what could be parsed, along with a SyntaxErrors holding every error.
+	RecoverErrors bool
+
+	// MaxTokens is the maximum number of tokens of the document, MaxDepth the
+	// maximum nesting of its selection sets, list values and object values,
+	// and MaxLength its maximum length in bytes. Parse fails with a
+	// gqlerrors.LimitError as soon as one of them is exceeded, even when
+	// recovering from errors. The limits are disabled when zero.
+	MaxTokens int
+	MaxDepth  int
+	MaxLength int
+
+	// KeepComments attaches the "#" comments of the source to the nodes of
+	// the document: a comment is attached to the following definition,
+	// selection or field, argument, enum value and operation type
+	// definition, unless it follows a node on its last line or ends a block.
+	KeepComments bool
+
+	// ExperimentalClientControlledNullability enables the client controlled
+	// nullability designators of the fields: `field!` makes a field
+	// non-null, `field?` nullable, and brackets apply the designators to the
+	// items of lists, as in `field[!]?`. The designators are stored in
+	// ast.Field.Nullability.
+	ExperimentalClientControlledNullability bool
+}
+
+// SyntaxErrors is the error returned by Parse when ParseOptions.RecoverErrors
+// is set and the source has syntax errors, in the order they were found.
+type SyntaxErrors []error
+
+func (errs SyntaxErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
 }
 
 type ParseParams struct {
@@ -57,18 +100,23 @@ type Parser struct {
 	Options  ParseOptions
 	PrevEnd  int
 	Token    lexer.Token
+
+	// brackets are the kinds of the brackets opened before Token and not
+	// closed yet
+	brackets []lexer.TokenKind
+	// errors are the syntax errors recovered from
+	errors SyntaxErrors
+	// depth is the nesting of the selection sets, list values and object
+	// values being parsed
+	depth int
+	// comments are the comment tokens lexed and not attached to a node yet,
+	// and lastComment the start of the last one
+	comments    []lexer.Token
+	lastComment int
 }
 
 func Parse(p ParseParams) (*ast.Document, error) {
-	var sourceObj *source.Source
-	switch src := p.Source.(type) {
-	case *source.Source:
-		sourceObj = src
-	default:
-		body, _ := p.Source.(string)
-		sourceObj = source.NewSource(&source.Source{Body: []byte(body)})
-	}
-	parser, err := makeParser(sourceObj, p.Options)
+	parser, err := makeParser(getSource(p.Source), p.Options)
 	if err != nil {
 		return nil, err
 	}
@@ -76,31 +124,109 @@ func Parse(p ParseParams) (*ast.Document, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(parser.errors) > 0 {
+		return doc, parser.errors
+	}
 	return doc, nil
 }
 
-// TODO: test and expose parseValue as a public
-func parseValue(p ParseParams) (ast.Value, error) {
-	var value ast.Value
-	var sourceObj *source.Source
-	switch src := p.Source.(type) {
-	case *source.Source:
-		sourceObj = src
-	default:
-		body, _ := p.Source.(string)
-		sourceObj = source.NewSource(&source.Source{Body: []byte(body)})
+// ParseSources parses sources as a single document, such as a schema split
+// across files: the document holds the definitions of every source, in
+// order. The location of each node keeps the source it was parsed from, so
+// that errors report the right file; the document itself has no location.
+// The options apply to each source, including the limits.
+func ParseSources(sources []*source.Source, options ParseOptions) (*ast.Document, error) {
+	var (
+		definitions []ast.Node
+		dangling    []*ast.Comment
+		errs        SyntaxErrors
+	)
+	for _, s := range sources {
+		doc, err := Parse(ParseParams{Source: source.NewSource(s), Options: options})
+		if recovered, ok := err.(SyntaxErrors); ok {
+			errs = append(errs, recovered...)
+		} else if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, doc.Definitions...)
+		if doc.Comments != nil {
+			dangling = append(dangling, doc.Comments.Dangling...)
+		}
+	}
+	var comments *ast.CommentGroup
+	if len(dangling) > 0 {
+		comments = &ast.CommentGroup{Dangling: dangling}
+	}
+	doc := ast.NewDocument(&ast.Document{
+		Definitions: definitions,
+		Comments:    comments,
+	})
+	if len(errs) > 0 {
+		return doc, errs
 	}
-	parser, err := makeParser(sourceObj, p.Options)
+	return doc, nil
+}
+
+// ParseValue parses the source as a single value literal, such as
+// `{a: [1, $b]}`. The value may contain variables.
+func ParseValue(p ParseParams) (ast.Value, error) {
+	return parseValueSource(p, false)
+}
+
+// ParseConstValue parses the source as a single constant value literal, such
+// as a default value: unlike ParseValue, variables are syntax errors.
+func ParseConstValue(p ParseParams) (ast.Value, error) {
+	return parseValueSource(p, true)
+}
+
+// ParseType parses the source as a single type reference, such as
+// `[String!]!`.
+func ParseType(p ParseParams) (ast.Type, error) {
+	parser, err := makeParser(getSource(p.Source), p.Options)
 	if err != nil {
-		return value, err
+		return nil, err
+	}
+	if len(parser.errors) > 0 {
+		return nil, parser.errors
 	}
-	value, err = parseValueLiteral(parser, false)
+	ttype, err := parseType(parser)
 	if err != nil {
-		return value, err
+		return nil, err
+	}
+	if _, err := expect(parser, lexer.EOF); err != nil {
+		return nil, err
+	}
+	return ttype, nil
+}
+
+func parseValueSource(p ParseParams, isConst bool) (ast.Value, error) {
+	parser, err := makeParser(getSource(p.Source), p.Options)
+	if err != nil {
+		return nil, err
+	}
+	if len(parser.errors) > 0 {
+		return nil, parser.errors
+	}
+	value, err := parseValueLiteral(parser, isConst)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := expect(parser, lexer.EOF); err != nil {
+		return nil, err
 	}
 	return value, nil
 }
 
+// getSource returns the *source.Source of ParseParams.Source, which is either
+// one or the string of the body.
+func getSource(src interface{}) *source.Source {
+	if src, ok := src.(*source.Source); ok {
+		return src
+	}
+	body, _ := src.(string)
+	return source.NewSource(&source.Source{Body: []byte(body)})
+}
+
 // Converts a name lex token into a name parse node.
 func parseName(parser *Parser) (*ast.Name, error) {
 	token, err := expect(parser, lexer.NAME)
@@ -114,18 +240,30 @@ func parseName(parser *Parser) (*ast.Name, error) {
 }
 
 func makeParser(s *source.Source, opts ParseOptions) (*Parser, error) {
-	lexToken := lexer.Lex(s)
-	token, err := lexToken(0)
-	if err != nil {
-		return &Parser{}, err
+	parser := &Parser{
+		Source:      s,
+		Options:     opts,
+		lastComment: -1,
+	}
+	parser.LexToken = lexComments(parser, lexer.LexWithOptions(s, lexer.Options{
+		MaxTokens:    opts.MaxTokens,
+		MaxLength:    opts.MaxLength,
+		KeepComments: opts.KeepComments,
+		QuestionMark: opts.ExperimentalClientControlledNullability,
+	}))
+	token, err := parser.LexToken(0)
+	if err != nil {
+		if !opts.RecoverErrors || isLimitError(err) {
+			return &Parser{}, err
+		}
+		parser.errors = append(parser.errors, err)
+		if err := skipToken(parser); err != nil {
+			return &Parser{}, err
+		}
+		return parser, nil
 	}
-	return &Parser{
-		LexToken: lexToken,
-		Source:   s,
-		Options:  opts,
-		PrevEnd:  0,
-		Token:    token,
-	}, nil
+	parser.Token = token
+	return parser, nil
 }
 
 /* Implements the parsing rules in the Document section. */
@@ -144,20 +282,39 @@ func parseDocument(parser *Parser) (*ast.Document, error) {
 		} else if skp {
 			break
 		}
+		definitionStart := parser.Token.Start
 		switch kind := parser.Token.Kind; kind {
 		case lexer.BRACE_L, lexer.NAME, lexer.STRING, lexer.BLOCK_STRING:
 			item = tokenDefinitionFn[kind.String()]
 		default:
-			return nil, unexpected(parser, lexer.Token{})
+			err = unexpected(parser, lexer.Token{})
+			if !parser.Options.RecoverErrors {
+				return nil, err
+			}
+			if err := recoverDefinition(parser, definitionStart, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 		if node, err = item(parser); err != nil {
-			return nil, err
+			if !parser.Options.RecoverErrors || isLimitError(err) {
+				return nil, err
+			}
+			if err := recoverDefinition(parser, definitionStart, err); err != nil {
+				return nil, err
+			}
+			continue
 		}
 		nodes = append(nodes, node)
 	}
+	var comments *ast.CommentGroup
+	if len(parser.comments) > 0 {
+		comments = &ast.CommentGroup{Dangling: takeComments(parser, parser.Token.Start)}
+	}
 	return ast.NewDocument(&ast.Document{
 		Loc:         loc(parser, start),
 		Definitions: nodes,
+		Comments:    comments,
 	}), nil
 }
 
@@ -178,6 +335,7 @@ func parseOperationDefinition(parser *Parser) (ast.Node, error) {
 		err                 error
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if peek(parser, lexer.BRACE_L) {
 		selectionSet, err := parseSelectionSet(parser)
 		if err != nil {
@@ -188,6 +346,7 @@ func parseOperationDefinition(parser *Parser) (ast.Node, error) {
 			Directives:   []*ast.Directive{},
 			SelectionSet: selectionSet,
 			Loc:          loc(parser, start),
+			Comments:     nodeComments(parser, comments),
 		}), nil
 	}
 	if operation, err = parseOperationType(parser); err != nil {
@@ -202,7 +361,7 @@ func parseOperationDefinition(parser *Parser) (ast.Node, error) {
 	if variableDefinitions, err = parseVariableDefinitions(parser); err != nil {
 		return nil, err
 	}
-	if directives, err = parseDirectives(parser); err != nil {
+	if directives, err = parseDirectives(parser, false); err != nil {
 		return nil, err
 	}
 	if selectionSet, err = parseSelectionSet(parser); err != nil {
@@ -215,6 +374,7 @@ func parseOperationDefinition(parser *Parser) (ast.Node, error) {
 		Directives:          directives,
 		SelectionSet:        selectionSet,
 		Loc:                 loc(parser, start),
+		Comments:            nodeComments(parser, comments),
 	}), nil
 }
 
@@ -260,7 +420,7 @@ func parseVariableDefinitions(parser *Parser) ([]*ast.VariableDefinition, error)
 }
 
 /**
- * VariableDefinition : Variable : Type DefaultValue?
+ * VariableDefinition : Variable : Type DefaultValue? Directives[Const]?
  */
 func parseVariableDefinition(parser *Parser) (interface{}, error) {
 	var (
@@ -269,6 +429,7 @@ func parseVariableDefinition(parser *Parser) (interface{}, error) {
 		err      error
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if variable, err = parseVariable(parser); err != nil {
 		return nil, err
 	}
@@ -286,11 +447,17 @@ func parseVariableDefinition(parser *Parser) (interface{}, error) {
 			return nil, err
 		}
 	}
+	directives, err := parseDirectives(parser, true)
+	if err != nil {
+		return nil, err
+	}
 	return ast.NewVariableDefinition(&ast.VariableDefinition{
 		Variable:     variable,
 		Type:         ttype,
 		DefaultValue: defaultValue,
+		Directives:   directives,
 		Loc:          loc(parser, start),
+		Comments:     nodeComments(parser, comments),
 	}), nil
 }
 
@@ -320,14 +487,29 @@ func parseVariable(parser *Parser) (*ast.Variable, error) {
  */
 func parseSelectionSet(parser *Parser) (*ast.SelectionSet, error) {
 	start := parser.Token.Start
+	if err := enterNested(parser); err != nil {
+		return nil, err
+	}
+	defer leaveNested(parser)
 	selections := []ast.Selection{}
+	parseFn := parseSelection
+	if parser.Options.RecoverErrors {
+		depth := len(parser.brackets) + 1
+		parseFn = func(parser *Parser) (interface{}, error) {
+			return recoverSelection(parser, depth)
+		}
+	}
 	if iSelections, err := reverse(parser,
-		lexer.BRACE_L, parseSelection, lexer.BRACE_R,
+		lexer.BRACE_L, parseFn, lexer.BRACE_R,
 		true,
 	); err != nil {
 		return nil, err
 	} else {
 		for _, iSelection := range iSelections {
+			// selections recovered from are nil
+			if iSelection == nil {
+				continue
+			}
 			selections = append(selections, iSelection.(ast.Selection))
 		}
 	}
@@ -352,7 +534,7 @@ func parseSelection(parser *Parser) (interface{}, error) {
 }
 
 /**
- * Field : Alias? Name Arguments? Directives? SelectionSet?
+ * Field : Alias? Name Arguments? Nullability? Directives? SelectionSet?
  *
  * Alias : Name :
  */
@@ -365,6 +547,7 @@ func parseField(parser *Parser) (*ast.Field, error) {
 		err        error
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if name, err = parseName(parser); err != nil {
 		return nil, err
 	}
@@ -376,10 +559,16 @@ func parseField(parser *Parser) (*ast.Field, error) {
 			return nil, err
 		}
 	}
-	if arguments, err = parseArguments(parser); err != nil {
+	if arguments, err = parseArguments(parser, false); err != nil {
 		return nil, err
 	}
-	if directives, err = parseDirectives(parser); err != nil {
+	var nullability ast.Nullability
+	if parser.Options.ExperimentalClientControlledNullability {
+		if nullability, err = parseNullability(parser); err != nil {
+			return nil, err
+		}
+	}
+	if directives, err = parseDirectives(parser, false); err != nil {
 		return nil, err
 	}
 	var selectionSet *ast.SelectionSet
@@ -392,20 +581,83 @@ func parseField(parser *Parser) (*ast.Field, error) {
 		Alias:        alias,
 		Name:         name,
 		Arguments:    arguments,
+		Nullability:  nullability,
 		Directives:   directives,
 		SelectionSet: selectionSet,
 		Loc:          loc(parser, start),
+		Comments:     nodeComments(parser, comments),
 	}), nil
 }
 
+/**
+ * Nullability :
+ *   - ListNullability NullabilityDesignator?
+ *   - NullabilityDesignator
+ *
+ * ListNullability : [ Nullability? ]
+ *
+ * NullabilityDesignator : one of ! ?
+ *
+ * Returns nil if the token starts no nullability.
+ */
+func parseNullability(parser *Parser) (ast.Nullability, error) {
+	start := parser.Token.Start
+	var list *ast.ListNullability
+	if peek(parser, lexer.BRACKET_L) {
+		if err := enterNested(parser); err != nil {
+			return nil, err
+		}
+		defer leaveNested(parser)
+		if _, err := expect(parser, lexer.BRACKET_L); err != nil {
+			return nil, err
+		}
+		element, err := parseNullability(parser)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := expect(parser, lexer.BRACKET_R); err != nil {
+			return nil, err
+		}
+		list = ast.NewListNullability(&ast.ListNullability{
+			Element: element,
+			Loc:     loc(parser, start),
+		})
+	}
+	switch {
+	case peek(parser, lexer.BANG):
+		if err := advance(parser); err != nil {
+			return nil, err
+		}
+		return ast.NewRequiredDesignator(&ast.RequiredDesignator{
+			Element: list,
+			Loc:     loc(parser, start),
+		}), nil
+	case peek(parser, lexer.QUESTION_MARK):
+		if err := advance(parser); err != nil {
+			return nil, err
+		}
+		return ast.NewOptionalDesignator(&ast.OptionalDesignator{
+			Element: list,
+			Loc:     loc(parser, start),
+		}), nil
+	case list != nil:
+		return list, nil
+	}
+	return nil, nil
+}
+
 /**
  * Arguments : ( Argument+ )
  */
-func parseArguments(parser *Parser) ([]*ast.Argument, error) {
+func parseArguments(parser *Parser, isConst bool) ([]*ast.Argument, error) {
 	arguments := []*ast.Argument{}
+	item := parseArgument
+	if isConst {
+		item = parseConstArgument
+	}
 	if peek(parser, lexer.PAREN_L) {
 		if iArguments, err := reverse(parser,
-			lexer.PAREN_L, parseArgument, lexer.PAREN_R,
+			lexer.PAREN_L, item, lexer.PAREN_R,
 			true,
 		); err != nil {
 			return arguments, err
@@ -422,25 +674,35 @@ func parseArguments(parser *Parser) ([]*ast.Argument, error) {
  * Argument : Name : Value
  */
 func parseArgument(parser *Parser) (interface{}, error) {
+	return parseArgumentValue(parser, false)
+}
+
+func parseConstArgument(parser *Parser) (interface{}, error) {
+	return parseArgumentValue(parser, true)
+}
+
+func parseArgumentValue(parser *Parser, isConst bool) (interface{}, error) {
 	var (
 		err   error
 		name  *ast.Name
 		value ast.Value
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if name, err = parseName(parser); err != nil {
 		return nil, err
 	}
 	if _, err = expect(parser, lexer.COLON); err != nil {
 		return nil, err
 	}
-	if value, err = parseValueLiteral(parser, false); err != nil {
+	if value, err = parseValueLiteral(parser, isConst); err != nil {
 		return nil, err
 	}
 	return ast.NewArgument(&ast.Argument{
-		Name:  name,
-		Value: value,
-		Loc:   loc(parser, start),
+		Name:     name,
+		Value:    value,
+		Loc:      loc(parser, start),
+		Comments: nodeComments(parser, comments),
 	}), nil
 }
 
@@ -458,6 +720,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
 		err error
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if _, err = expect(parser, lexer.SPREAD); err != nil {
 		return nil, err
 	}
@@ -466,7 +729,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-		directives, err := parseDirectives(parser)
+		directives, err := parseDirectives(parser, false)
 		if err != nil {
 			return nil, err
 		}
@@ -474,6 +737,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
 			Name:       name,
 			Directives: directives,
 			Loc:        loc(parser, start),
+			Comments:   nodeComments(parser, comments),
 		}), nil
 	}
 	var typeCondition *ast.Named
@@ -488,7 +752,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
 		typeCondition = name
 
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -501,6 +765,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
 		Directives:    directives,
 		SelectionSet:  selectionSet,
 		Loc:           loc(parser, start),
+		Comments:      nodeComments(parser, comments),
 	}), nil
 }
 
@@ -512,6 +777,7 @@ func parseFragment(parser *Parser) (interface{}, error) {
  */
 func parseFragmentDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	_, err := expectKeyWord(parser, lexer.FRAGMENT)
 	if err != nil {
 		return nil, err
@@ -528,7 +794,7 @@ func parseFragmentDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -542,6 +808,7 @@ func parseFragmentDefinition(parser *Parser) (ast.Node, error) {
 		Directives:    directives,
 		SelectionSet:  selectionSet,
 		Loc:           loc(parser, start),
+		Comments:      nodeComments(parser, comments),
 	}), nil
 }
 
@@ -647,6 +914,10 @@ func parseValueValue(parser *Parser) (interface{}, error) {
  */
 func parseList(parser *Parser, isConst bool) (*ast.ListValue, error) {
 	start := parser.Token.Start
+	if err := enterNested(parser); err != nil {
+		return nil, err
+	}
+	defer leaveNested(parser)
 	var item parseFn = parseValueValue
 	if isConst {
 		item = parseConstValue
@@ -675,6 +946,10 @@ func parseList(parser *Parser, isConst bool) (*ast.ListValue, error) {
  */
 func parseObject(parser *Parser, isConst bool) (*ast.ObjectValue, error) {
 	start := parser.Token.Start
+	if err := enterNested(parser); err != nil {
+		return nil, err
+	}
+	defer leaveNested(parser)
 	if _, err := expect(parser, lexer.BRACE_L); err != nil {
 		return nil, err
 	}
@@ -728,10 +1003,10 @@ func parseObjectField(parser *Parser, isConst bool) (*ast.ObjectField, error) {
 /**
  * Directives : Directive+
  */
-func parseDirectives(parser *Parser) ([]*ast.Directive, error) {
+func parseDirectives(parser *Parser, isConst bool) ([]*ast.Directive, error) {
 	directives := []*ast.Directive{}
 	for peek(parser, lexer.AT) {
-		if directive, err := parseDirective(parser); err != nil {
+		if directive, err := parseDirective(parser, isConst); err != nil {
 			return directives, err
 		} else {
 			directives = append(directives, directive)
@@ -743,7 +1018,7 @@ func parseDirectives(parser *Parser) ([]*ast.Directive, error) {
 /**
  * Directive : @ Name Arguments?
  */
-func parseDirective(parser *Parser) (*ast.Directive, error) {
+func parseDirective(parser *Parser, isConst bool) (*ast.Directive, error) {
 	var (
 		err  error
 		name *ast.Name
@@ -756,7 +1031,7 @@ func parseDirective(parser *Parser) (*ast.Directive, error) {
 	if name, err = parseName(parser); err != nil {
 		return nil, err
 	}
-	if args, err = parseArguments(parser); err != nil {
+	if args, err = parseArguments(parser, isConst); err != nil {
 		return nil, err
 	}
 	return ast.NewDirective(&ast.Directive{
@@ -777,27 +1052,27 @@ func parseDirective(parser *Parser) (*ast.Directive, error) {
 func parseType(parser *Parser) (ttype ast.Type, err error) {
 	token := parser.Token
 	// [ String! ]!
-	switch token.Kind {
-	case lexer.BRACKET_L:
-		if err = advance(parser); err != nil {
+	if peek(parser, lexer.BRACKET_L) {
+		if err := enterNested(parser); err != nil {
 			return nil, err
 		}
+		defer leaveNested(parser)
+	}
+	if skp, err := skip(parser, lexer.BRACKET_L); err != nil {
+		return nil, err
+	} else if skp {
 		if ttype, err = parseType(parser); err != nil {
 			return nil, err
 		}
-		fallthrough
-	case lexer.BRACKET_R:
-		if err = advance(parser); err != nil {
+		if _, err = expect(parser, lexer.BRACKET_R); err != nil {
 			return nil, err
 		}
 		ttype = ast.NewList(&ast.List{
 			Type: ttype,
 			Loc:  loc(parser, token.Start),
 		})
-	case lexer.NAME:
-		if ttype, err = parseNamed(parser); err != nil {
-			return nil, err
-		}
+	} else if ttype, err = parseNamed(parser); err != nil {
+		return nil, err
 	}
 
 	// BANG must be executed
@@ -874,11 +1149,12 @@ func parseTypeSystemDefinition(parser *Parser) (ast.Node, error) {
  */
 func parseSchemaDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	_, err := expectKeyWord(parser, "schema")
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -900,11 +1176,13 @@ func parseSchemaDefinition(parser *Parser) (ast.Node, error) {
 		OperationTypes: operationTypes,
 		Directives:     directives,
 		Loc:            loc(parser, start),
+		Comments:       nodeComments(parser, comments),
 	}), nil
 }
 
 func parseOperationTypeDefinition(parser *Parser) (interface{}, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	operation, err := parseOperationType(parser)
 	if err != nil {
 		return nil, err
@@ -921,6 +1199,7 @@ func parseOperationTypeDefinition(parser *Parser) (interface{}, error) {
 		Operation: operation,
 		Type:      ttype,
 		Loc:       loc(parser, start),
+		Comments:  nodeComments(parser, comments),
 	}), nil
 }
 
@@ -929,10 +1208,12 @@ func parseOperationTypeDefinition(parser *Parser) (interface{}, error) {
  */
 func parseScalarTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.SCALAR)
 	if err != nil {
 		return nil, err
@@ -941,7 +1222,7 @@ func parseScalarTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -950,6 +1231,7 @@ func parseScalarTypeDefinition(parser *Parser) (ast.Node, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 	})
 	return def, nil
 }
@@ -961,10 +1243,12 @@ func parseScalarTypeDefinition(parser *Parser) (ast.Node, error) {
  */
 func parseObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.TYPE)
 	if err != nil {
 		return nil, err
@@ -977,7 +1261,7 @@ func parseObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -998,6 +1282,7 @@ func parseObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 		Name:        name,
 		Description: description,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Interfaces:  interfaces,
 		Directives:  directives,
 		Fields:      fields,
@@ -1016,7 +1301,9 @@ func parseImplementsInterfaces(parser *Parser) ([]*ast.Named, error) {
 			return nil, err
 		}
 		// optional leading ampersand
-		skip(parser, lexer.AMP)
+		if _, err := skip(parser, lexer.AMP); err != nil {
+			return nil, err
+		}
 		for {
 			ttype, err := parseNamed(parser)
 			if err != nil {
@@ -1038,10 +1325,12 @@ func parseImplementsInterfaces(parser *Parser) ([]*ast.Named, error) {
  */
 func parseFieldDefinition(parser *Parser) (interface{}, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	name, err := parseName(parser)
 	if err != nil {
 		return nil, err
@@ -1058,7 +1347,7 @@ func parseFieldDefinition(parser *Parser) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1069,6 +1358,7 @@ func parseFieldDefinition(parser *Parser) (interface{}, error) {
 		Type:        ttype,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 	}), nil
 }
 
@@ -1108,9 +1398,11 @@ func parseInputValueDef(parser *Parser) (interface{}, error) {
 		err         error
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if description, err = parseDescription(parser); err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	if name, err = parseName(parser); err != nil {
 		return nil, err
 	}
@@ -1132,7 +1424,7 @@ func parseInputValueDef(parser *Parser) (interface{}, error) {
 			defaultValue = val
 		}
 	}
-	if directives, err = parseDirectives(parser); err != nil {
+	if directives, err = parseDirectives(parser, false); err != nil {
 		return nil, err
 	}
 	return ast.NewInputValueDefinition(&ast.InputValueDefinition{
@@ -1142,6 +1434,7 @@ func parseInputValueDef(parser *Parser) (interface{}, error) {
 		DefaultValue: defaultValue,
 		Directives:   directives,
 		Loc:          loc(parser, start),
+		Comments:     describedComments(parser, comments, described),
 	}), nil
 }
 
@@ -1152,10 +1445,12 @@ func parseInputValueDef(parser *Parser) (interface{}, error) {
  */
 func parseInterfaceTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.INTERFACE)
 	if err != nil {
 		return nil, err
@@ -1164,7 +1459,7 @@ func parseInterfaceTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1186,6 +1481,7 @@ func parseInterfaceTypeDefinition(parser *Parser) (ast.Node, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Fields:      fields,
 	}), nil
 }
@@ -1195,10 +1491,12 @@ func parseInterfaceTypeDefinition(parser *Parser) (ast.Node, error) {
  */
 func parseUnionTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.UNION)
 	if err != nil {
 		return nil, err
@@ -1207,7 +1505,7 @@ func parseUnionTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1224,17 +1522,22 @@ func parseUnionTypeDefinition(parser *Parser) (ast.Node, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Types:       types,
 	}), nil
 }
 
 /**
  * UnionMembers :
- *   - NamedType
+ *   - `|`? NamedType
  *   - UnionMembers | NamedType
  */
 func parseUnionMembers(parser *Parser) ([]*ast.Named, error) {
 	members := []*ast.Named{}
+	// optional leading pipe
+	if _, err := skip(parser, lexer.PIPE); err != nil {
+		return nil, err
+	}
 	for {
 		member, err := parseNamed(parser)
 		if err != nil {
@@ -1255,10 +1558,12 @@ func parseUnionMembers(parser *Parser) ([]*ast.Named, error) {
  */
 func parseEnumTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.ENUM)
 	if err != nil {
 		return nil, err
@@ -1267,7 +1572,7 @@ func parseEnumTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1289,6 +1594,7 @@ func parseEnumTypeDefinition(parser *Parser) (ast.Node, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Values:      values,
 	}), nil
 }
@@ -1300,15 +1606,17 @@ func parseEnumTypeDefinition(parser *Parser) (ast.Node, error) {
  */
 func parseEnumValueDefinition(parser *Parser) (interface{}, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	name, err := parseName(parser)
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1317,6 +1625,7 @@ func parseEnumValueDefinition(parser *Parser) (interface{}, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 	}), nil
 }
 
@@ -1326,10 +1635,12 @@ func parseEnumValueDefinition(parser *Parser) (interface{}, error) {
  */
 func parseInputObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	description, err := parseDescription(parser)
 	if err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	_, err = expectKeyWord(parser, lexer.INPUT)
 	if err != nil {
 		return nil, err
@@ -1338,7 +1649,7 @@ func parseInputObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	directives, err := parseDirectives(parser)
+	directives, err := parseDirectives(parser, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1360,19 +1671,28 @@ func parseInputObjectTypeDefinition(parser *Parser) (ast.Node, error) {
 		Description: description,
 		Directives:  directives,
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Fields:      fields,
 	}), nil
 }
 
 /**
  * TypeExtensionDefinition : extend ObjectTypeDefinition
+ *
+ * SchemaExtensionDefinition :
+ *   - extend schema Directives[Const]? { OperationTypeDefinition+ }
+ *   - extend schema Directives[Const]
  */
 func parseTypeExtensionDefinition(parser *Parser) (ast.Node, error) {
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	_, err := expectKeyWord(parser, lexer.EXTEND)
 	if err != nil {
 		return nil, err
 	}
+	if parser.Token.Kind == lexer.NAME && parser.Token.Value == lexer.SCHEMA {
+		return parseSchemaExtensionDefinition(parser, start, comments)
+	}
 
 	definition, err := parseObjectTypeDefinition(parser)
 	if err != nil {
@@ -1380,10 +1700,45 @@ func parseTypeExtensionDefinition(parser *Parser) (ast.Node, error) {
 	}
 	return ast.NewTypeExtensionDefinition(&ast.TypeExtensionDefinition{
 		Loc:        loc(parser, start),
+		Comments:   nodeComments(parser, comments),
 		Definition: definition.(*ast.ObjectDefinition),
 	}), nil
 }
 
+func parseSchemaExtensionDefinition(parser *Parser, start int, comments []*ast.Comment) (ast.Node, error) {
+	if _, err := expectKeyWord(parser, lexer.SCHEMA); err != nil {
+		return nil, err
+	}
+	directives, err := parseDirectives(parser, true)
+	if err != nil {
+		return nil, err
+	}
+	operationTypes := []*ast.OperationTypeDefinition{}
+	if peek(parser, lexer.BRACE_L) {
+		operationTypesI, err := reverse(
+			parser,
+			lexer.BRACE_L, parseOperationTypeDefinition, lexer.BRACE_R,
+			true,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range operationTypesI {
+			if op, ok := op.(*ast.OperationTypeDefinition); ok {
+				operationTypes = append(operationTypes, op)
+			}
+		}
+	} else if len(directives) == 0 {
+		return nil, unexpected(parser, parser.Token)
+	}
+	return ast.NewSchemaExtensionDefinition(&ast.SchemaExtensionDefinition{
+		OperationTypes: operationTypes,
+		Directives:     directives,
+		Loc:            loc(parser, start),
+		Comments:       nodeComments(parser, comments),
+	}), nil
+}
+
 /**
  * DirectiveDefinition :
  *   - directive @ Name ArgumentsDefinition? on DirectiveLocations
@@ -1397,9 +1752,11 @@ func parseDirectiveDefinition(parser *Parser) (ast.Node, error) {
 		locations   []*ast.Name
 	)
 	start := parser.Token.Start
+	comments := leadingComments(parser)
 	if description, err = parseDescription(parser); err != nil {
 		return nil, err
 	}
+	described := leadingComments(parser)
 	if _, err = expectKeyWord(parser, lexer.DIRECTIVE); err != nil {
 		return nil, err
 	}
@@ -1421,6 +1778,7 @@ func parseDirectiveDefinition(parser *Parser) (ast.Node, error) {
 
 	return ast.NewDirectiveDefinition(&ast.DirectiveDefinition{
 		Loc:         loc(parser, start),
+		Comments:    describedComments(parser, comments, described),
 		Name:        name,
 		Description: description,
 		Arguments:   args,
@@ -1430,11 +1788,15 @@ func parseDirectiveDefinition(parser *Parser) (ast.Node, error) {
 
 /**
  * DirectiveLocations :
- *   - Name
+ *   - `|`? Name
  *   - DirectiveLocations | Name
  */
 func parseDirectiveLocations(parser *Parser) ([]*ast.Name, error) {
 	locations := []*ast.Name{}
+	// optional leading pipe
+	if _, err := skip(parser, lexer.PIPE); err != nil {
+		return nil, err
+	}
 	for {
 		if name, err := parseName(parser); err != nil {
 			return locations, err
@@ -1493,17 +1855,152 @@ func loc(parser *Parser, start int) *ast.Location {
 	})
 }
 
+// lexComments wraps lexToken to skip the COMMENT tokens, recording them in
+// the comments of parser until they are attached to a node.
+func lexComments(parser *Parser, lexToken lexer.Lexer) lexer.Lexer {
+	return func(resetPosition int) (lexer.Token, error) {
+		for {
+			token, err := lexToken(resetPosition)
+			if err != nil || token.Kind != lexer.COMMENT {
+				return token, err
+			}
+			// lookahead lexes the same comments again
+			if token.Start > parser.lastComment {
+				parser.lastComment = token.Start
+				parser.comments = append(parser.comments, token)
+			}
+			resetPosition = token.End
+		}
+	}
+}
+
+func makeComment(parser *Parser, token lexer.Token) *ast.Comment {
+	comment := &ast.Comment{Value: token.Value}
+	if !parser.Options.NoLocation {
+		comment.Loc = ast.NewLocation(&ast.Location{
+			Start: token.Start,
+			End:   token.End,
+		})
+		if !parser.Options.NoSource {
+			comment.Loc.Source = parser.Source
+		}
+	}
+	return comment
+}
+
+// takeComments removes the comments ending before end from the comments
+// waiting to be attached, and returns them.
+func takeComments(parser *Parser, end int) []*ast.Comment {
+	var taken []*ast.Comment
+	pending := parser.comments[:0]
+	for _, token := range parser.comments {
+		if token.End <= end {
+			taken = append(taken, makeComment(parser, token))
+		} else {
+			pending = append(pending, token)
+		}
+	}
+	parser.comments = pending
+	return taken
+}
+
+// leadingComments returns the comments preceding the node starting at the
+// current token.
+func leadingComments(parser *Parser) []*ast.Comment {
+	if len(parser.comments) == 0 {
+		return nil
+	}
+	return takeComments(parser, parser.Token.Start)
+}
+
+// nodeComments returns the comments of the node ending at parser.PrevEnd:
+// its leading comments and the comments within it not attached to a nested
+// node, the comment following it on the same line, and the comments before
+// the end of the block, arguments or document it ends.
+func nodeComments(parser *Parser, leading []*ast.Comment) *ast.CommentGroup {
+	var trailing *ast.Comment
+	var dangling []*ast.Comment
+	if len(parser.comments) > 0 {
+		leading = append(leading, takeComments(parser, parser.PrevEnd)...)
+		if len(parser.comments) > 0 {
+			token := parser.comments[0]
+			if token.End <= parser.Token.Start &&
+				!bytes.ContainsAny(parser.Source.Body[parser.PrevEnd:token.Start], "\r\n") {
+				trailing = makeComment(parser, token)
+				parser.comments = parser.comments[1:]
+			}
+		}
+		if peek(parser, lexer.BRACE_R) || peek(parser, lexer.PAREN_R) || peek(parser, lexer.EOF) {
+			dangling = takeComments(parser, parser.Token.Start)
+		}
+	}
+	if leading == nil && trailing == nil && dangling == nil {
+		return nil
+	}
+	return &ast.CommentGroup{
+		Leading:  leading,
+		Trailing: trailing,
+		Dangling: dangling,
+	}
+}
+
+// describedComments returns the comments of the described node ending at
+// parser.PrevEnd, see nodeComments, along with the comments between its
+// description and the node.
+func describedComments(parser *Parser, leading, afterDescription []*ast.Comment) *ast.CommentGroup {
+	comments := nodeComments(parser, leading)
+	if len(afterDescription) == 0 {
+		return comments
+	}
+	if comments == nil {
+		comments = &ast.CommentGroup{}
+	}
+	comments.AfterDescription = afterDescription
+	return comments
+}
+
 // Moves the internal parser object to the next lexed token.
 func advance(parser *Parser) error {
 	parser.PrevEnd = parser.Token.End
 	token, err := parser.LexToken(parser.PrevEnd)
 	if err != nil {
-		return err
+		if !parser.Options.RecoverErrors || isLimitError(err) {
+			return err
+		}
+		// the characters the lexer fails on are skipped
+		return skipToken(parser)
 	}
+	trackBrackets(parser)
 	parser.Token = token
 	return nil
 }
 
+// trackBrackets updates the brackets opened before the parser moves past its
+// current token. A closing bracket also closes the unclosed brackets it
+// contains.
+func trackBrackets(parser *Parser) {
+	var open lexer.TokenKind
+	switch parser.Token.Kind {
+	case lexer.BRACE_L, lexer.PAREN_L, lexer.BRACKET_L:
+		parser.brackets = append(parser.brackets, parser.Token.Kind)
+		return
+	case lexer.BRACE_R:
+		open = lexer.BRACE_L
+	case lexer.PAREN_R:
+		open = lexer.PAREN_L
+	case lexer.BRACKET_R:
+		open = lexer.BRACKET_L
+	default:
+		return
+	}
+	for i := len(parser.brackets) - 1; i >= 0; i-- {
+		if parser.brackets[i] == open {
+			parser.brackets = parser.brackets[:i]
+			return
+		}
+	}
+}
+
 // lookahead retrieves the next token
 func lookahead(parser *Parser) (lexer.Token, error) {
 	return parser.LexToken(parser.Token.End)
@@ -1566,6 +2063,204 @@ func unexpectedEmpty(parser *Parser, beginLoc int, openKind, closeKind lexer.Tok
 	return gqlerrors.NewSyntaxError(parser.Source, beginLoc, description)
 }
 
+/* Limits, see ParseOptions */
+
+// enterNested increments the nesting depth of the parser, failing when it
+// exceeds ParseOptions.MaxDepth. leaveNested must be called when it succeeds.
+func enterNested(parser *Parser) error {
+	if max := parser.Options.MaxDepth; max > 0 && parser.depth >= max {
+		return gqlerrors.NewLimitError(parser.Source, parser.Token.Start, "MaxDepth", max)
+	}
+	parser.depth++
+	return nil
+}
+
+func leaveNested(parser *Parser) {
+	parser.depth--
+}
+
+func isLimitError(err error) bool {
+	var limitErr *gqlerrors.LimitError
+	return errors.As(err, &limitErr)
+}
+
+/* Error recovery, see ParseOptions.RecoverErrors */
+
+// addError records a syntax error recovered from. Lexer errors are reported
+// once, even though skipToken may run into them several times.
+func addError(parser *Parser, err error) {
+	if n := len(parser.errors); n > 0 && parser.errors[n-1].Error() == err.Error() {
+		return
+	}
+	parser.errors = append(parser.errors, err)
+}
+
+// skipToken moves the parser to the next token, skipping the characters the
+// lexer fails on. It only fails when a limit is exceeded.
+func skipToken(parser *Parser) error {
+	for position := parser.Token.End; ; {
+		token, err := parser.LexToken(position)
+		if isLimitError(err) {
+			return err
+		}
+		if err != nil {
+			addError(parser, err)
+			position = failedTokenEnd(parser.Source.Body, position)
+			continue
+		}
+		trackBrackets(parser)
+		parser.PrevEnd = parser.Token.End
+		parser.Token = token
+		return nil
+	}
+}
+
+// failedTokenEnd returns the end of the token following position, which the
+// lexer failed on, so that lexing resumes at the next token boundary rather
+// than inside of the token: strings end at their closing quote or at the end
+// of their line, numbers at the next character that cannot be part of them.
+func failedTokenEnd(body []byte, position int) int {
+	// skip the ignored tokens before the token
+	for position < len(body) {
+		switch body[position] {
+		case ' ', '\t', ',', '\n', '\r':
+			position++
+			continue
+		case 0xef:
+			if bytes.HasPrefix(body[position:], []byte("\ufeff")) {
+				position += 3
+				continue
+			}
+		case '#':
+			for position < len(body) && body[position] != '\n' && body[position] != '\r' {
+				position++
+			}
+			continue
+		}
+		break
+	}
+	if position >= len(body) {
+		return len(body)
+	}
+
+	switch code := body[position]; {
+	case bytes.HasPrefix(body[position:], []byte(`"""`)):
+		for i := position + 3; i < len(body); i++ {
+			if bytes.HasPrefix(body[i:], []byte(`\"""`)) {
+				i += 3
+			} else if bytes.HasPrefix(body[i:], []byte(`"""`)) {
+				return i + 3
+			}
+		}
+		return len(body)
+	case code == '"':
+		for i := position + 1; i < len(body); i++ {
+			switch body[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			case '\n', '\r':
+				return i
+			}
+		}
+		return len(body)
+	case code == '-' || code >= '0' && code <= '9':
+		end := position + 1
+		for end < len(body) && (body[end] == '.' || body[end] == '+' || body[end] == '-' ||
+			body[end] == '_' || body[end] >= '0' && body[end] <= '9' ||
+			body[end] >= 'a' && body[end] <= 'z' || body[end] >= 'A' && body[end] <= 'Z') {
+			end++
+		}
+		return end
+	}
+	_, n := utf8.DecodeRune(body[position:])
+	return position + n
+}
+
+// recoverDefinition records err, raised while parsing the definition starting
+// at start, and skips to the next definition. It only fails when a limit is
+// exceeded.
+func recoverDefinition(parser *Parser, start int, err error) error {
+	addError(parser, err)
+	if parser.Token.Start == start && !peek(parser, lexer.EOF) {
+		if err := skipToken(parser); err != nil {
+			return err
+		}
+	}
+	for !peek(parser, lexer.EOF) && (len(parser.brackets) > 0 || !peekDefinition(parser)) {
+		if err := skipToken(parser); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// peekDefinition determines if the next token can start a definition.
+func peekDefinition(parser *Parser) bool {
+	switch parser.Token.Kind {
+	case lexer.BRACE_L, lexer.STRING, lexer.BLOCK_STRING:
+		return true
+	case lexer.NAME:
+		switch parser.Token.Value {
+		case lexer.QUERY, lexer.MUTATION, lexer.SUBSCRIPTION, lexer.FRAGMENT,
+			lexer.SCHEMA, lexer.SCALAR, lexer.TYPE, lexer.INTERFACE, lexer.UNION,
+			lexer.ENUM, lexer.INPUT, lexer.EXTEND, lexer.DIRECTIVE:
+			return true
+		}
+	}
+	return false
+}
+
+// recoverSelection parses a selection of a selection set whose content is at
+// the given depth. On syntax errors, it records the error and skips to the
+// next selection, returning nil. The error is returned when the end of the
+// selection set cannot be found.
+func recoverSelection(parser *Parser, depth int) (interface{}, error) {
+	start := parser.Token.Start
+	selection, err := parseSelection(parser)
+	if err == nil {
+		return selection, nil
+	}
+	if peek(parser, lexer.EOF) || isLimitError(err) {
+		return nil, err
+	}
+	if parser.Token.Start == start {
+		if err := skipToken(parser); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if peek(parser, lexer.EOF) || len(parser.brackets) < depth {
+			return nil, err
+		}
+		if peek(parser, lexer.BRACE_R) && innermostBrace(parser) == depth-1 {
+			// the brackets left open in the selection are closed with it
+			parser.brackets = parser.brackets[:depth]
+			break
+		}
+		if len(parser.brackets) == depth && (peek(parser, lexer.NAME) || peek(parser, lexer.SPREAD)) {
+			break
+		}
+		if err := skipToken(parser); err != nil {
+			return nil, err
+		}
+	}
+	addError(parser, err)
+	return nil, nil
+}
+
+// innermostBrace returns the index of the innermost "{" opened before the
+// current token, -1 if none.
+func innermostBrace(parser *Parser) int {
+	for i := len(parser.brackets) - 1; i >= 0; i-- {
+		if parser.brackets[i] == lexer.BRACE_L {
+			return i
+		}
+	}
+	return -1
+}
+
 //	Returns list of parse nodes, determined by
 //
 // the parseFn. This list begins with a lex token of openKind
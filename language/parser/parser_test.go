@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"reflect"
@@ -147,6 +148,397 @@ func TestParseProvidesUsefulErrorsWhenUsingSource(t *testing.T) {
 	testErrorMessage(t, test)
 }
 
+func TestParseSources(t *testing.T) {
+	query := source.NewSource(&source.Source{
+		Body: []byte("type Query {\n  me: User\n}\n"),
+		Name: "schema/query.graphql",
+	})
+	user := source.NewSource(&source.Source{
+		Body: []byte("# users\ntype User {\n  name: String\n}\n\nextend type Query {\n  users: [User]\n}\n"),
+		Name: "schema/user.graphql",
+	})
+	document, err := ParseSources([]*source.Source{query, user}, ParseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if document.Loc != nil {
+		t.Fatalf("unexpected document location: %v", document.Loc)
+	}
+	expectedSources := []*source.Source{query, user, user}
+	if len(document.Definitions) != len(expectedSources) {
+		t.Fatalf("expected %d definitions, got %d", len(expectedSources), len(document.Definitions))
+	}
+	for i, definition := range document.Definitions {
+		if s := definition.GetLoc().Source; s != expectedSources[i] {
+			t.Fatalf("unexpected source of definition %d: %v", i, s.Name)
+		}
+	}
+	expectedLocation := location.SourceLocation{Line: 6, Column: 1}
+	if l := location.GetLocation(user, document.Definitions[2].GetLoc().Start); l != expectedLocation {
+		t.Fatalf("unexpected location: %v", l)
+	}
+}
+
+func TestParseSourcesProvidesTheFileOfErrors(t *testing.T) {
+	sources := []*source.Source{
+		{Body: []byte("type Query {\n  me: User\n}\n"), Name: "schema/query.graphql"},
+		{Body: []byte("type User {\n  name String\n}\n"), Name: "schema/user.graphql"},
+	}
+	_, err := ParseSources(sources, ParseOptions{})
+	expectedMessage := `Syntax Error schema/user.graphql (2:8) Expected :, found Name "String"
+
+schema/user.graphql:2:8
+1: type User {
+2:   name String
+          ^
+3: }
+`
+	if err == nil || err.Error() != expectedMessage {
+		t.Fatalf("unexpected error.\nexpected:\n%v\n\ngot:\n%v", expectedMessage, err)
+	}
+	formatted := gqlerrors.FormatError(err)
+	if !reflect.DeepEqual(formatted.Sources, []string{"schema/user.graphql"}) {
+		t.Fatalf("unexpected FormattedError.Sources: %v", formatted.Sources)
+	}
+}
+
+func TestParseSourcesRecoversFromErrors(t *testing.T) {
+	sources := []*source.Source{
+		{Body: []byte("{ a(x: ) }"), Name: "a.graphql"},
+		{Body: []byte("{ b }"), Name: "b.graphql"},
+		{Body: []byte("{ c { } }"), Name: "c.graphql"},
+	}
+	document, err := ParseSources(sources, ParseOptions{RecoverErrors: true})
+	errs, ok := err.(SyntaxErrors)
+	if !ok {
+		t.Fatalf("expected SyntaxErrors, got %v", err)
+	}
+	if len(document.Definitions) != len(sources) {
+		t.Fatalf("expected %d definitions, got %d", len(sources), len(document.Definitions))
+	}
+	expectedMessages := []string{
+		`Syntax Error a.graphql (1:8) Unexpected )`,
+		`Syntax Error c.graphql (1:5) Unexpected empty IN {}`,
+	}
+	if len(errs) != len(expectedMessages) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expectedMessages), len(errs), errs)
+	}
+	for i, expectedMessage := range expectedMessages {
+		checkErrorMessage(t, errs[i], expectedMessage)
+	}
+}
+
+func TestParseRecoversFromErrors(t *testing.T) {
+	body := `query A { a b(x: ) c { d: : e } f }
+query B { g }
+foo bar { }
+{ h ... on { i } j }
+{ k(x: 1 }
+` + "fragment F on T { \u0007 l }\n"
+	document, err := Parse(ParseParams{
+		Source:  body,
+		Options: ParseOptions{RecoverErrors: true},
+	})
+	errs, ok := err.(SyntaxErrors)
+	if !ok {
+		t.Fatalf("expected SyntaxErrors, got %v", err)
+	}
+
+	expectedDocument := `query A {
+  a
+  c {
+    e
+  }
+  f
+}
+
+query B {
+  g
+}
+
+{
+  h
+  j
+}
+
+{}
+
+fragment F on T {
+  l
+}
+`
+	if printed := printer.Print(document); printed != expectedDocument {
+		t.Fatalf("unexpected document.\nexpected:\n%v\n\ngot:\n%v", expectedDocument, printed)
+	}
+
+	expectedMessages := []string{
+		`Syntax Error GraphQL (1:18) Unexpected )`,
+		`Syntax Error GraphQL (1:27) Expected Name, found :`,
+		`Syntax Error GraphQL (3:1) Unexpected Name "foo"`,
+		`Syntax Error GraphQL (3:9) Unexpected empty IN {}`,
+		`Syntax Error GraphQL (4:12) Expected Name, found {`,
+		`Syntax Error GraphQL (5:10) Expected Name, found }`,
+		`Syntax Error GraphQL (6:19) Invalid character "\\u0007"`,
+	}
+	if len(errs) != len(expectedMessages) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expectedMessages), len(errs), errs)
+	}
+	for i, expectedMessage := range expectedMessages {
+		checkErrorMessage(t, errs[i], expectedMessage)
+	}
+	expectedLocations := []location.SourceLocation{{Line: 1, Column: 18}}
+	if locations := toError(errs[0]).Locations; !reflect.DeepEqual(expectedLocations, locations) {
+		t.Fatalf("unexpected Error.Locations.\nexpected:\n%v\n\ngot:\n%v", expectedLocations, locations)
+	}
+}
+
+func TestParseRecoversFromErrors_ResumesAfterTheFailedToken(t *testing.T) {
+	tests := []struct {
+		body             string
+		expectedDocument string
+		expectedMessages []string
+	}{
+		{
+			"{ a b( } c }",
+			"{\n  a\n}\n",
+			[]string{
+				`Syntax Error GraphQL (1:8) Expected Name, found }`,
+				`Syntax Error GraphQL (1:10) Unexpected Name "c"`,
+			},
+		},
+		{
+			"{ a \"unterminated\n b }",
+			"{\n  a\n  b\n}\n",
+			[]string{
+				`Syntax Error GraphQL (1:18) Unterminated string.`,
+			},
+		},
+		{
+			"{ a 1.x b }",
+			"{\n  a\n  b\n}\n",
+			[]string{
+				`Syntax Error GraphQL (1:7) Invalid number, expected digit but got: "x".`,
+			},
+		},
+	}
+	for _, test := range tests {
+		document, err := Parse(ParseParams{
+			Source:  test.body,
+			Options: ParseOptions{RecoverErrors: true},
+		})
+		errs, ok := err.(SyntaxErrors)
+		if !ok {
+			t.Fatalf("expected SyntaxErrors for %q, got %v", test.body, err)
+		}
+		if printed := printer.Print(document); printed != test.expectedDocument {
+			t.Fatalf("unexpected document for %q.\nexpected:\n%v\n\ngot:\n%v", test.body, test.expectedDocument, printed)
+		}
+		if len(errs) != len(test.expectedMessages) {
+			t.Fatalf("expected %d errors for %q, got %d: %v", len(test.expectedMessages), test.body, len(errs), errs)
+		}
+		for i, expectedMessage := range test.expectedMessages {
+			checkErrorMessage(t, errs[i], expectedMessage)
+		}
+	}
+}
+
+func TestParseRecoversFromErrors_NoErrors(t *testing.T) {
+	document, err := Parse(ParseParams{
+		Source:  "{ a }",
+		Options: ParseOptions{RecoverErrors: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(document.Definitions) != 1 {
+		t.Fatalf("unexpected definitions: %v", document.Definitions)
+	}
+}
+
+func TestParseEnforcesLimits(t *testing.T) {
+	tests := []struct {
+		source          string
+		options         ParseOptions
+		expectedMessage string
+	}{
+		{
+			"{ a { b { c } } }",
+			ParseOptions{MaxDepth: 2},
+			`Syntax Error GraphQL (1:9) Document exceeds MaxDepth of 2.`,
+		},
+		{
+			"{ a(x: [[1]]) }",
+			ParseOptions{MaxDepth: 2},
+			`Syntax Error GraphQL (1:9) Document exceeds MaxDepth of 2.`,
+		},
+		{
+			"{ a(x: {y: {z: 1}}) }",
+			ParseOptions{MaxDepth: 2},
+			`Syntax Error GraphQL (1:12) Document exceeds MaxDepth of 2.`,
+		},
+		{
+			"{ a b c }",
+			ParseOptions{MaxTokens: 4},
+			`Syntax Error GraphQL (1:9) Document exceeds MaxTokens of 4.`,
+		},
+		{
+			"{ a b c }",
+			ParseOptions{MaxLength: 8},
+			`Syntax Error GraphQL (1:9) Document exceeds MaxLength of 8.`,
+		},
+		{
+			"query ($a: [[[Int]]]) { a }",
+			ParseOptions{MaxDepth: 2},
+			`Syntax Error GraphQL (1:14) Document exceeds MaxDepth of 2.`,
+		},
+		{
+			"query ($a: " + strings.Repeat("[", 100000) + "Int" + strings.Repeat("]", 100000) + ") { a }",
+			ParseOptions{MaxDepth: 10},
+			`Syntax Error GraphQL (1:22) Document exceeds MaxDepth of 10.`,
+		},
+		{
+			"{ a[[[!]]] }",
+			ParseOptions{MaxDepth: 2, ExperimentalClientControlledNullability: true},
+			`Syntax Error GraphQL (1:5) Document exceeds MaxDepth of 2.`,
+		},
+		{
+			// limits are not recovered from
+			"{ a { b { ) c } } }",
+			ParseOptions{MaxDepth: 2, RecoverErrors: true},
+			`Syntax Error GraphQL (1:9) Document exceeds MaxDepth of 2.`,
+		},
+	}
+	for _, test := range tests {
+		document, err := Parse(ParseParams{Source: test.source, Options: test.options})
+		if document != nil {
+			t.Fatalf("unexpected document for %q", test.source)
+		}
+		checkErrorMessage(t, err, test.expectedMessage)
+		var limitErr *gqlerrors.LimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("expected a LimitError for %q, got %#v", test.source, err)
+		}
+	}
+
+	if _, err := Parse(ParseParams{
+		Source:  "{ a { b } }",
+		Options: ParseOptions{MaxDepth: 2, MaxTokens: 7, MaxLength: 11},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseKeepsComments(t *testing.T) {
+	source := `# operation
+{
+  # a
+  a(x: 1) # after a
+  b {
+    c
+    # end of b
+  }
+}
+`
+	document, err := Parse(ParseParams{
+		Source:  source,
+		Options: ParseOptions{NoLocation: true, KeepComments: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operation := document.Definitions[0].(*ast.OperationDefinition)
+	a := operation.SelectionSet.Selections[0].(*ast.Field)
+	b := operation.SelectionSet.Selections[1].(*ast.Field)
+	c := b.SelectionSet.Selections[0].(*ast.Field)
+	tests := []struct {
+		comments *ast.CommentGroup
+		expected *ast.CommentGroup
+	}{
+		{operation.Comments, &ast.CommentGroup{Leading: []*ast.Comment{{Value: " operation"}}}},
+		{a.Comments, &ast.CommentGroup{
+			Leading:  []*ast.Comment{{Value: " a"}},
+			Trailing: &ast.Comment{Value: " after a"},
+		}},
+		{b.Comments, nil},
+		{c.Comments, &ast.CommentGroup{Dangling: []*ast.Comment{{Value: " end of b"}}}},
+	}
+	for i, test := range tests {
+		if !reflect.DeepEqual(test.expected, test.comments) {
+			t.Fatalf("unexpected comments for node %d, expected: %#v, got: %#v", i, test.expected, test.comments)
+		}
+	}
+
+	if printed := printer.Print(document); printed != source {
+		t.Fatalf("unexpected printed document:\n%v", printed)
+	}
+
+	document, err = Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if comments := document.Definitions[0].(*ast.OperationDefinition).Comments; comments != nil {
+		t.Fatalf("unexpected comments %v", comments)
+	}
+}
+
+func TestParseKeepsCommentsInPlace(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// formats reports whether Format prints the source unchanged too
+		formats bool
+	}{
+		{"after descriptions", `# before A
+"""A"""
+# after A
+type A {
+  b: Int
+  
+  """a"""
+  # after a
+  a: Int
+}
+`, false},
+		{"on variable definitions", `query Q(
+  # a
+  $a: Int
+  # b
+  $b: Int = 1 # after b
+  # end of variables
+) {
+  f
+}
+`, true},
+		{"on arguments", `{
+  f(
+    # a
+    a: 1
+    b: 2 # after b
+    # end of arguments
+  ) @d(
+    # c
+    c: 3
+  )
+}
+`, true},
+	}
+	for _, test := range tests {
+		document, err := Parse(ParseParams{
+			Source:  test.source,
+			Options: ParseOptions{NoLocation: true, KeepComments: true},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error for comments %v: %v", test.name, err)
+		}
+		if printed := printer.Print(document); printed != test.source {
+			t.Fatalf("unexpected printed document for comments %v:\n%v", test.name, printed)
+		}
+		if formatted := printer.Format(document, printer.Options{}); test.formats && formatted != test.source {
+			t.Fatalf("unexpected formatted document for comments %v:\n%v", test.name, formatted)
+		}
+	}
+}
+
 func TestParsesVariableInlineValues(t *testing.T) {
 	source := `{ field(complex: { a: { b: [ $var ] } }) }`
 	// should not return error
@@ -192,6 +584,139 @@ func TestDoesNotAllowNullAsValue(t *testing.T) {
 	testErrorMessage(t, test)
 }
 
+func TestParsesVariableDefinitionDirectives(t *testing.T) {
+	source := `query Q($a: Int = 1 @deprecated, $b: [String] @a(x: "y") @b) { field }`
+	doc, err := Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	varDefs := doc.Definitions[0].(*ast.OperationDefinition).VariableDefinitions
+	names := []string{}
+	for _, varDef := range varDefs {
+		for _, directive := range varDef.Directives {
+			names = append(names, varDef.Variable.Name.Value+"@"+directive.Name.Value)
+		}
+	}
+	if expected := []string{"a@deprecated", "b@a", "b@b"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected directives, expected: %v, got: %v", expected, names)
+	}
+}
+
+func TestVariableDefinitionDirectivesAreConstant(t *testing.T) {
+	test := errorMessageTest{
+		`query Q($a: Int @d(x: $b)) { field }`,
+		`Syntax Error GraphQL (1:23) Unexpected $`,
+		false,
+	}
+	testErrorMessage(t, test)
+}
+
+func TestParsesLeadingPipes(t *testing.T) {
+	source := `
+		directive @a on | FIELD | QUERY
+		union U = | A | B
+	`
+	doc, err := Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if locations := doc.Definitions[0].(*ast.DirectiveDefinition).Locations; len(locations) != 2 {
+		t.Fatalf("unexpected locations: %v", locations)
+	}
+	if types := doc.Definitions[1].(*ast.UnionDefinition).Types; len(types) != 2 {
+		t.Fatalf("unexpected types: %v", types)
+	}
+}
+
+func TestParsesSchemaExtensions(t *testing.T) {
+	source := `
+		extend schema @a { subscription: Subscription }
+		extend schema @b
+	`
+	doc, err := Parse(ParseParams{Source: source})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := doc.Definitions[0].(*ast.SchemaExtensionDefinition)
+	if len(first.Directives) != 1 || len(first.OperationTypes) != 1 || first.OperationTypes[0].Operation != "subscription" {
+		t.Fatalf("unexpected schema extension: %v", first)
+	}
+	second := doc.Definitions[1].(*ast.SchemaExtensionDefinition)
+	if len(second.Directives) != 1 || len(second.OperationTypes) != 0 {
+		t.Fatalf("unexpected schema extension: %v", second)
+	}
+}
+
+func TestDoesNotAcceptEmptySchemaExtensions(t *testing.T) {
+	test := errorMessageTest{
+		`extend schema`,
+		`Syntax Error GraphQL (1:14) Unexpected EOF`,
+		false,
+	}
+	testErrorMessage(t, test)
+}
+
+func TestParsesClientControlledNullability(t *testing.T) {
+	source := `{ a! b? c[!]? d[[]]! e(x: 1)[] @skip(if: false) }`
+	doc, err := Parse(ParseParams{
+		Source: source,
+		Options: ParseOptions{
+			NoLocation:                              true,
+			ExperimentalClientControlledNullability: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []ast.Nullability{
+		ast.NewRequiredDesignator(nil),
+		ast.NewOptionalDesignator(nil),
+		ast.NewOptionalDesignator(&ast.OptionalDesignator{
+			Element: ast.NewListNullability(&ast.ListNullability{
+				Element: ast.NewRequiredDesignator(nil),
+			}),
+		}),
+		ast.NewRequiredDesignator(&ast.RequiredDesignator{
+			Element: ast.NewListNullability(&ast.ListNullability{
+				Element: ast.NewListNullability(nil),
+			}),
+		}),
+		ast.NewListNullability(nil),
+	}
+	selections := doc.Definitions[0].(*ast.OperationDefinition).SelectionSet.Selections
+	nullabilities := []ast.Nullability{}
+	for _, selection := range selections {
+		nullabilities = append(nullabilities, selection.(*ast.Field).Nullability)
+	}
+	if !reflect.DeepEqual(nullabilities, expected) {
+		t.Fatalf("unexpected nullabilities, expected: %v, got: %v", expected, nullabilities)
+	}
+	if directives := selections[4].(*ast.Field).Directives; len(directives) != 1 {
+		t.Fatalf("unexpected directives: %v", directives)
+	}
+}
+
+func TestClientControlledNullabilityIsOptIn(t *testing.T) {
+	testErrorMessage(t, errorMessageTest{
+		`{ a! }`,
+		`Syntax Error GraphQL (1:4) Expected Name, found !`,
+		false,
+	})
+	testErrorMessage(t, errorMessageTest{
+		`{ a? }`,
+		`Syntax Error GraphQL (1:4) Unexpected character "?".`,
+		false,
+	})
+}
+
+func TestDoesNotAcceptUnclosedListNullability(t *testing.T) {
+	_, err := Parse(ParseParams{
+		Source:  `{ a[! }`,
+		Options: ParseOptions{ExperimentalClientControlledNullability: true},
+	})
+	checkErrorMessage(t, err, `Syntax Error GraphQL (1:7) Expected ], found }`)
+}
+
 func TestParsesMultiByteCharacters_Unicode(t *testing.T) {
 
 	doc := `
@@ -736,6 +1261,96 @@ func TestParseCreatesAst(t *testing.T) {
 
 }
 
+func TestParseValue(t *testing.T) {
+	value, err := ParseValue(ParseParams{Source: `[123 "abc"]`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ast.NewListValue(&ast.ListValue{
+		Loc: &ast.Location{Start: 0, End: 11, Source: value.GetLoc().Source},
+		Values: []ast.Value{
+			ast.NewIntValue(&ast.IntValue{
+				Loc:   &ast.Location{Start: 1, End: 4, Source: value.GetLoc().Source},
+				Value: "123",
+			}),
+			ast.NewStringValue(&ast.StringValue{
+				Loc:   &ast.Location{Start: 5, End: 10, Source: value.GetLoc().Source},
+				Value: "abc",
+			}),
+		},
+	})
+	if !reflect.DeepEqual(value, expected) {
+		t.Fatalf("unexpected value, expected: %v, got: %v", expected, value)
+	}
+
+	value, err = ParseValue(ParseParams{Source: `{a: [1, $b], c: ENUM}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if printed := printer.Print(value); printed != `{a: [1, $b], c: ENUM}` {
+		t.Fatalf("unexpected value: %v", printed)
+	}
+}
+
+func TestParseValue_ReportsErrors(t *testing.T) {
+	testTable := []struct {
+		parse           func(ParseParams) (ast.Value, error)
+		source          interface{}
+		expectedMessage string
+	}{
+		{ParseValue, `1 2`, `Syntax Error GraphQL (1:3) Expected EOF, found Int "2"`},
+		{ParseValue, `{a: 1`, `Syntax Error GraphQL (1:6) Expected Name, found EOF`},
+		{ParseValue, ``, `Syntax Error GraphQL (1:1) Unexpected EOF`},
+		{ParseConstValue, `{a: [$b]}`, `Syntax Error GraphQL (1:6) Unexpected $`},
+		{ParseConstValue, source.NewSource(&source.Source{
+			Body: []byte("[1,\n  {b: $c}]"),
+			Name: "config.yaml",
+		}), `Syntax Error config.yaml (2:7) Unexpected $`},
+	}
+	for _, test := range testTable {
+		_, err := test.parse(ParseParams{Source: test.source})
+		checkErrorMessage(t, err, test.expectedMessage)
+	}
+}
+
+func TestParseType(t *testing.T) {
+	ttype, err := ParseType(ParseParams{
+		Source:  `[String!]!`,
+		Options: ParseOptions{NoLocation: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := ast.NewNonNull(&ast.NonNull{
+		Type: ast.NewList(&ast.List{
+			Type: ast.NewNonNull(&ast.NonNull{
+				Type: ast.NewNamed(&ast.Named{
+					Name: ast.NewName(&ast.Name{Value: "String"}),
+				}),
+			}),
+		}),
+	})
+	if !reflect.DeepEqual(ttype, expected) {
+		t.Fatalf("unexpected type, expected: %v, got: %v", expected, ttype)
+	}
+
+	testTable := []struct {
+		source          string
+		expectedMessage string
+	}{
+		{`[String`, `Syntax Error GraphQL (1:8) Expected ], found EOF`},
+		{`[String}`, `Syntax Error GraphQL (1:8) Expected ], found }`},
+		{`String]`, `Syntax Error GraphQL (1:7) Expected EOF, found ]`},
+		{`String!!`, `Syntax Error GraphQL (1:8) Expected EOF, found !`},
+		{`]`, `Syntax Error GraphQL (1:1) Expected Name, found ]`},
+		{``, `Syntax Error GraphQL (1:1) Expected Name, found EOF`},
+	}
+	for _, test := range testTable {
+		_, err := ParseType(ParseParams{Source: test.source})
+		checkErrorMessage(t, err, test.expectedMessage)
+	}
+}
+
 type errorMessageTest struct {
 	source          interface{}
 	expectedMessage string
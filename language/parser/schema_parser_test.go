@@ -794,6 +794,10 @@ input Hello {
 		},
 	})
 
+	expectedSource := &source.Source{
+		Body: []byte(body),
+		Name: "GraphQL",
+	}
 	expectedError := &gqlerrors.Error{
 		Message: `Syntax Error GraphQL (3:8) Expected :, found (
 
@@ -809,18 +813,13 @@ input Hello {
           ^
 4: }
 `,
-		Nodes: []ast.Node{},
-		Source: &source.Source{
-			Body: []byte(`
-input Hello {
-  world(foo: Int): String
-}`),
-			Name: "GraphQL",
-		},
+		Nodes:     []ast.Node{},
+		Source:    expectedSource,
 		Positions: []int{22},
 		Locations: []location.SourceLocation{
 			{Line: 3, Column: 8},
 		},
+		Sources: []*source.Source{expectedSource},
 	}
 	if err == nil {
 		t.Fatalf("expected error, expected: %v, got: %v", expectedError, nil)
@@ -0,0 +1,498 @@
+package printer
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/lexer"
+	"github.com/dagger/graphql/language/source"
+)
+
+// Options configures Format.
+type Options struct {
+	// IndentWidth is the number of spaces of an indentation level, 2 if zero.
+	IndentWidth int
+	// Compact prints the node on a single line without insignificant
+	// whitespace nor comments, e.g. for logging queries.
+	Compact bool
+	// MaxLineWidth is the width past which the arguments and variable
+	// definitions of a line are printed on their own lines. There is no limit
+	// if zero. It is ignored by Compact.
+	MaxLineWidth int
+	// BlockStringDescriptions prints descriptions as """block strings"""
+	// instead of "strings". It is ignored by Compact.
+	BlockStringDescriptions bool
+}
+
+// Format prints node according to opts. Unlike Print, it supports any layout
+// and does not need to walk the node with a visitor.
+func Format(node ast.Node, opts Options) string {
+	if opts.IndentWidth <= 0 {
+		opts.IndentWidth = 2
+	}
+	f := &formatter{
+		opts: opts,
+		unit: strings.Repeat(" ", opts.IndentWidth),
+	}
+	return f.format(node, 0)
+}
+
+// Fprint writes node formatted according to opts to w.
+func Fprint(w io.Writer, node ast.Node, opts Options) error {
+	_, err := io.WriteString(w, Format(node, opts))
+	return err
+}
+
+type formatter struct {
+	opts Options
+	unit string
+}
+
+// sep returns str, or compact in compact mode.
+func (f *formatter) sep(str, compact string) string {
+	if f.opts.Compact {
+		return compact
+	}
+	return str
+}
+
+func (f *formatter) indent(str string) string {
+	return strings.Replace(str, "\n", "\n"+f.unit, -1)
+}
+
+// fits reports whether line, starting at the indentation level depth, fits in
+// the maximum line width.
+func (f *formatter) fits(depth int, line string) bool {
+	if f.opts.MaxLineWidth <= 0 || f.opts.Compact {
+		return true
+	}
+	return depth*f.opts.IndentWidth+utf8.RuneCountInString(line) <= f.opts.MaxLineWidth
+}
+
+// block prints each item on its own line, wrapped in an indented "{ }" block.
+func (f *formatter) block(items []string) string {
+	if f.opts.Compact {
+		return "{" + join(items, " ") + "}"
+	}
+	if len(items) == 0 {
+		return "{}"
+	}
+	return f.indent("{\n"+join(items, "\n")) + "\n}"
+}
+
+// list prints items between parentheses, on their own lines if multiline.
+func (f *formatter) list(items []string, multiline bool) string {
+	switch {
+	case f.opts.Compact:
+		return wrap("(", join(items, ","), ")")
+	case multiline:
+		return wrap("(", f.indent("\n"+join(items, "\n")), "\n)")
+	}
+	return wrap("(", join(items, ", "), ")")
+}
+
+func (f *formatter) comments(node ast.CommentedNode, str string) string {
+	if f.opts.Compact {
+		return str
+	}
+	return printComments(node, str)
+}
+
+func (f *formatter) description(node ast.DescribableNode, str string) string {
+	sval := node.GetDescription()
+	if sval == nil || sval.Value == "" {
+		return str
+	}
+	desc := printString(sval.Value)
+	if f.opts.BlockStringDescriptions && !f.opts.Compact {
+		if block, ok := printBlockString(sval.Value); ok {
+			desc = block
+		}
+	}
+	if !f.opts.Compact {
+		desc += descriptionComments(node)
+	}
+	return desc + f.sep("\n", " ") + str
+}
+
+// printBlockString prints value as a block string. It reports false when
+// the block string does not parse back to value, e.g. because of a trailing
+// backslash or of an indentation common to all the lines, which block strings
+// remove.
+func printBlockString(value string) (string, bool) {
+	escaped := strings.Replace(value, `"""`, `\"""`, -1)
+	sep := ""
+	if strings.ContainsRune(escaped, '\n') || strings.HasSuffix(escaped, `"`) {
+		sep = "\n"
+	}
+	block := `"""` + sep + escaped + sep + `"""`
+	token, err := lexer.Lex(source.NewSource(&source.Source{Body: []byte(block)}))(0)
+	if err != nil || token.End != len(block) || token.Value != value {
+		return "", false
+	}
+	return block, true
+}
+
+// described prints the description of fields, arguments and enum values,
+// preceded by an empty line.
+func (f *formatter) described(node ast.DescribableNode, str string) string {
+	described := f.description(node, str)
+	if described != str && !f.opts.Compact {
+		described = "\n" + described
+	}
+	return described
+}
+
+func (f *formatter) name(node *ast.Name) string {
+	if node == nil {
+		return ""
+	}
+	return node.Value
+}
+
+func (f *formatter) directives(directives []*ast.Directive, depth int) string {
+	strs := []string{}
+	for _, directive := range directives {
+		strs = append(strs, f.format(directive, depth))
+	}
+	return join(strs, f.sep(" ", ""))
+}
+
+func (f *formatter) listNullability(node *ast.ListNullability, depth int) string {
+	if node == nil {
+		return ""
+	}
+	return f.format(node, depth)
+}
+
+// arguments formats args, and reports whether they must be printed on their
+// own lines because of their comments.
+func (f *formatter) arguments(args []*ast.Argument, depth int) (strs []string, commented bool) {
+	strs = []string{}
+	for _, arg := range args {
+		strs = append(strs, f.format(arg, depth+1))
+	}
+	return strs, argumentsCommented(args) && !f.opts.Compact
+}
+
+func (f *formatter) inputValues(defs []*ast.InputValueDefinition, depth int) (strs []string, described bool) {
+	for _, def := range defs {
+		strs = append(strs, f.format(def, depth+1))
+		if def.Description != nil && def.Description.Value != "" || def.Comments != nil {
+			described = true
+		}
+	}
+	return strs, described && !f.opts.Compact
+}
+
+func (f *formatter) format(node ast.Node, depth int) string {
+	switch node := node.(type) {
+	case *ast.Name:
+		return f.name(node)
+	case *ast.Variable:
+		return "$" + f.name(node.Name)
+
+	// Document
+	case *ast.Document:
+		defs := []string{}
+		for _, def := range node.Definitions {
+			defs = append(defs, f.format(def, depth))
+		}
+		if f.opts.Compact {
+			return join(defs, " ")
+		}
+		return printComments(node, join(defs, "\n\n")) + "\n"
+	case *ast.OperationDefinition:
+		varDefs := []string{}
+		for _, varDef := range node.VariableDefinitions {
+			varDefs = append(varDefs, f.format(varDef, depth+1))
+		}
+		name := f.name(node.Name)
+		directives := f.directives(node.Directives, depth)
+		selectionSet := ""
+		if node.SelectionSet != nil {
+			selectionSet = f.format(node.SelectionSet, depth)
+		}
+		// Anonymous queries with no directives or variable definitions can use
+		// the query short form.
+		if name == "" && directives == "" && len(varDefs) == 0 && node.Operation == ast.OperationTypeQuery {
+			return f.comments(node, selectionSet)
+		}
+		head := func(multiline bool) string {
+			return join([]string{node.Operation, name + f.list(varDefs, multiline)}, " ")
+		}
+		multiline := variableDefinitionsCommented(node.VariableDefinitions) && !f.opts.Compact ||
+			!f.fits(depth, head(false)+wrap(" ", directives, "")+" {")
+		str := join([]string{
+			head(multiline),
+			directives,
+			selectionSet,
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.VariableDefinition:
+		str := f.format(node.Variable, depth) + f.sep(": ", ":") + f.format(node.Type, depth)
+		if node.DefaultValue != nil {
+			str += f.sep(" = ", "=") + f.format(node.DefaultValue, depth)
+		}
+		return f.comments(node, str+wrap(f.sep(" ", ""), f.directives(node.Directives, depth), ""))
+	case *ast.SelectionSet:
+		selections := []string{}
+		for _, selection := range node.Selections {
+			if selection, ok := selection.(ast.Node); ok {
+				selections = append(selections, f.format(selection, depth+1))
+			}
+		}
+		return f.block(selections)
+	case *ast.Field:
+		args, multiline := f.arguments(node.Arguments, depth)
+		head := wrap("", f.name(node.Alias), f.sep(": ", ":")) + f.name(node.Name)
+		nullability := ""
+		if node.Nullability != nil {
+			nullability = f.format(node.Nullability, depth)
+		}
+		directives := f.directives(node.Directives, depth)
+		selectionSet := ""
+		if node.SelectionSet != nil {
+			selectionSet = f.format(node.SelectionSet, depth)
+		}
+		line := head + f.list(args, false) + nullability + wrap(" ", directives, "")
+		if selectionSet != "" {
+			line += " {"
+		}
+		str := join([]string{
+			head + f.list(args, multiline || !f.fits(depth, line)) + nullability,
+			directives,
+			selectionSet,
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.Argument:
+		return f.comments(node, f.name(node.Name)+f.sep(": ", ":")+f.format(node.Value, depth))
+
+	// Fragments
+	case *ast.FragmentSpread:
+		str := join([]string{
+			"..." + f.name(node.Name),
+			f.directives(node.Directives, depth),
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.InlineFragment:
+		head := "..."
+		if node.TypeCondition != nil {
+			head += f.sep(" ", "") + "on " + f.format(node.TypeCondition, depth)
+		}
+		selectionSet := ""
+		if node.SelectionSet != nil {
+			selectionSet = f.format(node.SelectionSet, depth)
+		}
+		str := join([]string{
+			head,
+			f.directives(node.Directives, depth),
+			selectionSet,
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.FragmentDefinition:
+		typeCondition := ""
+		if node.TypeCondition != nil {
+			typeCondition = f.format(node.TypeCondition, depth)
+		}
+		selectionSet := ""
+		if node.SelectionSet != nil {
+			selectionSet = f.format(node.SelectionSet, depth)
+		}
+		str := join([]string{
+			"fragment " + f.name(node.Name) + " on " + typeCondition,
+			f.directives(node.Directives, depth),
+			selectionSet,
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+
+	// Value
+	case *ast.IntValue:
+		return node.Value
+	case *ast.FloatValue:
+		return node.Value
+	case *ast.StringValue:
+		return printString(node.Value)
+	case *ast.BooleanValue:
+		return strconv.FormatBool(node.Value)
+	case *ast.EnumValue:
+		return node.Value
+	case *ast.ListValue:
+		values := []string{}
+		for _, value := range node.Values {
+			values = append(values, f.format(value, depth))
+		}
+		return "[" + join(values, f.sep(", ", ",")) + "]"
+	case *ast.ObjectValue:
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, f.format(field, depth))
+		}
+		return "{" + join(fields, f.sep(", ", ",")) + "}"
+	case *ast.ObjectField:
+		return f.name(node.Name) + f.sep(": ", ":") + f.format(node.Value, depth)
+
+	// Directive
+	case *ast.Directive:
+		args, multiline := f.arguments(node.Arguments, depth)
+		return "@" + f.name(node.Name) + f.list(args, multiline)
+
+	// Type
+	case *ast.Named:
+		return f.name(node.Name)
+	case *ast.List:
+		return "[" + f.format(node.Type, depth) + "]"
+	case *ast.NonNull:
+		return f.format(node.Type, depth) + "!"
+
+	// Client Controlled Nullability
+	case *ast.RequiredDesignator:
+		return f.listNullability(node.Element, depth) + "!"
+	case *ast.OptionalDesignator:
+		return f.listNullability(node.Element, depth) + "?"
+	case *ast.ListNullability:
+		element := ""
+		if node.Element != nil {
+			element = f.format(node.Element, depth)
+		}
+		return "[" + element + "]"
+
+	// Type System Definitions
+	case *ast.SchemaDefinition:
+		operationTypes := []string{}
+		for _, operationType := range node.OperationTypes {
+			operationTypes = append(operationTypes, f.format(operationType, depth+1))
+		}
+		str := join([]string{
+			"schema",
+			f.directives(node.Directives, depth),
+			f.block(operationTypes),
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.OperationTypeDefinition:
+		return f.comments(node, node.Operation+f.sep(": ", ":")+f.format(node.Type, depth))
+	case *ast.ScalarDefinition:
+		str := join([]string{
+			"scalar " + f.name(node.Name),
+			f.directives(node.Directives, depth),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.ObjectDefinition:
+		interfaces := []string{}
+		for _, iface := range node.Interfaces {
+			interfaces = append(interfaces, f.format(iface, depth))
+		}
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, f.format(field, depth+1))
+		}
+		str := join([]string{
+			"type " + f.name(node.Name) + wrap(" implements ", join(interfaces, f.sep(" & ", "&")), ""),
+			f.directives(node.Directives, depth),
+			f.block(fields),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.FieldDefinition:
+		args, multiline := f.inputValues(node.Arguments, depth)
+		name := f.name(node.Name)
+		rest := f.sep(": ", ":") + f.format(node.Type, depth) + wrap(f.sep(" ", ""), f.directives(node.Directives, depth), "")
+		multiline = multiline || !f.fits(depth, name+f.list(args, false)+rest)
+		return f.comments(node, f.described(node, name+f.list(args, multiline)+rest))
+	case *ast.InputValueDefinition:
+		str := f.name(node.Name) + f.sep(": ", ":") + f.format(node.Type, depth)
+		if node.DefaultValue != nil {
+			str += f.sep(" = ", "=") + f.format(node.DefaultValue, depth)
+		}
+		str = join([]string{str, f.directives(node.Directives, depth)}, f.sep(" ", ""))
+		return f.comments(node, f.described(node, str))
+	case *ast.InterfaceDefinition:
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, f.format(field, depth+1))
+		}
+		str := join([]string{
+			"interface " + f.name(node.Name),
+			f.directives(node.Directives, depth),
+			f.block(fields),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.UnionDefinition:
+		types := []string{}
+		for _, ttype := range node.Types {
+			types = append(types, f.format(ttype, depth))
+		}
+		str := join([]string{
+			"union " + f.name(node.Name),
+			f.directives(node.Directives, depth),
+			f.sep("= ", "=") + join(types, f.sep(" | ", "|")),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.EnumDefinition:
+		values := []string{}
+		for _, value := range node.Values {
+			values = append(values, f.format(value, depth+1))
+		}
+		str := join([]string{
+			"enum " + f.name(node.Name),
+			f.directives(node.Directives, depth),
+			f.block(values),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.EnumValueDefinition:
+		str := join([]string{
+			f.name(node.Name),
+			f.directives(node.Directives, depth),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.described(node, str))
+	case *ast.InputObjectDefinition:
+		fields := []string{}
+		for _, field := range node.Fields {
+			fields = append(fields, f.format(field, depth+1))
+		}
+		str := join([]string{
+			"input " + f.name(node.Name),
+			f.directives(node.Directives, depth),
+			f.block(fields),
+		}, f.sep(" ", ""))
+		return f.comments(node, f.description(node, str))
+	case *ast.TypeExtensionDefinition:
+		definition := ""
+		if node.Definition != nil {
+			definition = f.format(node.Definition, depth)
+		}
+		return f.comments(node, "extend "+definition)
+	case *ast.SchemaExtensionDefinition:
+		operationTypes := ""
+		if len(node.OperationTypes) > 0 {
+			formatted := []string{}
+			for _, operationType := range node.OperationTypes {
+				formatted = append(formatted, f.format(operationType, depth+1))
+			}
+			operationTypes = f.block(formatted)
+		}
+		str := join([]string{
+			"extend schema",
+			f.directives(node.Directives, depth),
+			operationTypes,
+		}, f.sep(" ", ""))
+		return f.comments(node, str)
+	case *ast.DirectiveDefinition:
+		args, multiline := f.inputValues(node.Arguments, depth)
+		locations := []string{}
+		for _, location := range node.Locations {
+			locations = append(locations, f.name(location))
+		}
+		head := "directive @" + f.name(node.Name)
+		rest := f.sep(" on ", "on ") + join(locations, f.sep(" | ", "|"))
+		multiline = multiline || !f.fits(depth, head+f.list(args, false)+rest)
+		str := head + f.list(args, multiline) + rest
+		if len(args) == 0 {
+			str = head + " on " + join(locations, f.sep(" | ", "|"))
+		}
+		return f.comments(node, f.description(node, str))
+	}
+	return ""
+}
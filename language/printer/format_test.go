@@ -0,0 +1,310 @@
+package printer_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/testutil"
+)
+
+func TestFormat_PrintsLikePrintWithBlockStringDescriptions(t *testing.T) {
+	for _, file := range []string{
+		"../../kitchen-sink.graphql",
+		"../../schema-kitchen-sink.graphql",
+		"../../schema-all-descriptions.graphql",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to load %v", file)
+		}
+		astDoc := parse(t, string(b))
+
+		expected := printer.Print(astDoc)
+		results := printer.Format(astDoc, printer.Options{BlockStringDescriptions: true})
+		if !reflect.DeepEqual(expected, results) {
+			t.Fatalf("Unexpected result for %v, Diff: %v", file, testutil.Diff(expected, results))
+		}
+	}
+}
+
+func TestFormat_PrintsComments(t *testing.T) {
+	query := `# leading
+query Q {
+  a # trailing
+  # dangling
+}
+`
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source: query,
+		Options: parser.ParseOptions{
+			NoLocation:   true,
+			KeepComments: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	results := printer.Format(astDoc, printer.Options{})
+	if results != query {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(query, results))
+	}
+	expected := `query Q{a}`
+	results = printer.Format(astDoc, printer.Options{Compact: true})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestFormat_PrintsCompactKitchenSink(t *testing.T) {
+	b, err := ioutil.ReadFile("../../kitchen-sink.graphql")
+	if err != nil {
+		t.Fatalf("unable to load kitchen-sink.graphql")
+	}
+	astDoc := parse(t, string(b))
+
+	expected := `query namedQuery($foo:ComplexFooType,$bar:Bar=DefaultBarValue){customUser:user(id:[987,654]){id ...on User@defer{field2{id alias:field1(first:10,after:$foo)@include(if:$foo){id ...frag}}} ...@skip(unless:$foo){id} ...{id}}} ` +
+		`mutation favPost{fav(post:123)@defer{post{id}}} ` +
+		`subscription PostFavSubscription($input:StoryLikeSubscribeInput){postFavSubscribe(input:$input){post{favers{count} favSentence{text}}}} ` +
+		`fragment frag on Follower{foo(size:$size,bar:$b,obj:{key:"value"})} ` +
+		`{unnamed(truthyVal:true,falseyVal:false) query}`
+	results := printer.Format(astDoc, printer.Options{Compact: true})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// the compact form parses back to the same document
+	if printed, reprinted := printer.Print(astDoc), printer.Print(parse(t, results)); printed != reprinted {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(printed, reprinted))
+	}
+}
+
+func TestFormat_PrintsCompactSchema(t *testing.T) {
+	astDoc := parse(t, `
+schema @d { query: Q mutation: M }
+
+"A type"
+type A implements B & C @d {
+  "A field"
+  f(a: Int = 1 @d, b: [String!]): String @d
+}
+
+union U @d = A | B
+
+directive @x(a: Int) on FIELD | QUERY
+
+directive @y on FIELD
+
+extend schema @d { subscription: S }
+
+query Q($a: Int = 1 @d, $b: Int @d @e) { f }
+`)
+
+	expected := `schema@d{query:Q mutation:M} ` +
+		`"A type" type A implements B&C@d{"A field" f(a:Int=1@d,b:[String!]):String@d} ` +
+		`union U@d=A|B ` +
+		`directive @x(a:Int)on FIELD|QUERY ` +
+		`directive @y on FIELD ` +
+		`extend schema@d{subscription:S} ` +
+		`query Q($a:Int=1@d,$b:Int@d@e){f}`
+	results := printer.Format(astDoc, printer.Options{Compact: true, BlockStringDescriptions: true})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestFormat_UsesIndentWidth(t *testing.T) {
+	astDoc := parse(t, `{ a { b { c } } }`)
+
+	expected := "{\n    a {\n        b {\n            c\n        }\n    }\n}\n"
+	results := printer.Format(astDoc, printer.Options{IndentWidth: 4})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestFormat_WrapsArgumentsPastMaxLineWidth(t *testing.T) {
+	astDoc := parse(t, `
+query Query($first: Int, $after: String) {
+  short(a: 1)
+  users(first: $first, after: $after, orderBy: {field: NAME, direction: ASC}) {
+    id
+  }
+}
+
+type Query {
+  users(first: Int, after: String, orderBy: UserOrder): [User]
+}
+`)
+
+	expected := `query Query($first: Int, $after: String) {
+  short(a: 1)
+  users(
+    first: $first
+    after: $after
+    orderBy: {field: NAME, direction: ASC}
+  ) {
+    id
+  }
+}
+
+type Query {
+  users(
+    first: Int
+    after: String
+    orderBy: UserOrder
+  ): [User]
+}
+`
+	results := printer.Format(astDoc, printer.Options{MaxLineWidth: 50})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	expected = `query Query(
+  $first: Int
+  $after: String
+) {
+  short(a: 1)
+  users(
+    first: $first
+    after: $after
+    orderBy: {field: NAME, direction: ASC}
+  ) {
+    id
+  }
+}
+
+type Query {
+  users(
+    first: Int
+    after: String
+    orderBy: UserOrder
+  ): [User]
+}
+`
+	results = printer.Format(astDoc, printer.Options{MaxLineWidth: 40})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestFormat_PrintsQuotedOrBlockStringDescriptions(t *testing.T) {
+	astDoc := parse(t, `
+"""
+Multi
+line
+"""
+scalar A
+
+"Ends with a \"quote\""
+scalar B
+
+"Has \"\"\" in it"
+scalar C
+`)
+
+	expected := `"Multi\nline"
+scalar A
+
+"Ends with a \"quote\""
+scalar B
+
+"Has \"\"\" in it"
+scalar C
+`
+	results := printer.Format(astDoc, printer.Options{})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	expected = `"""
+Multi
+line
+"""
+scalar A
+
+"""
+Ends with a "quote"
+"""
+scalar B
+
+"""Has \""" in it"""
+scalar C
+`
+	results = printer.Format(astDoc, printer.Options{BlockStringDescriptions: true})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestFormat_FallsBackToQuotedDescriptions(t *testing.T) {
+	astDoc := parse(t, `
+"Ends with \\"
+scalar A
+
+"  Indented\n  lines"
+scalar B
+
+"Partly\n  indented"
+scalar C
+`)
+
+	expected := `"Ends with \\"
+scalar A
+
+"  Indented\n  lines"
+scalar B
+
+"""
+Partly
+  indented
+"""
+scalar C
+`
+	results := printer.Format(astDoc, printer.Options{BlockStringDescriptions: true})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+	if reparsed := printer.Format(parse(t, results), printer.Options{BlockStringDescriptions: true}); reparsed != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, reparsed))
+	}
+}
+
+func TestFormat_PrintsGraphQLStringEscapes(t *testing.T) {
+	astDoc := parse(t, "\"nul \\u0000, del \u007f, emoji \U0001F600, tab \\t\"\nscalar A\n")
+
+	expected := "\"nul \\u0000, del \\u007F, emoji \U0001F600, tab \\t\"\nscalar A\n"
+	results := printer.Format(astDoc, printer.Options{})
+	if results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+	if reparsed := printer.Format(parse(t, results), printer.Options{}); reparsed != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, reparsed))
+	}
+}
+
+func TestFprint_WritesTheFormattedNode(t *testing.T) {
+	node := ast.NewField(&ast.Field{
+		Name: ast.NewName(&ast.Name{Value: "foo"}),
+		Arguments: []*ast.Argument{
+			ast.NewArgument(&ast.Argument{
+				Name:  ast.NewName(&ast.Name{Value: "bar"}),
+				Value: ast.NewIntValue(&ast.IntValue{Value: "1"}),
+			}),
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, node, printer.Options{Compact: true}); err != nil {
+		t.Fatalf("Fprint failed: %v", err)
+	}
+	if expected := "foo(bar:1)"; buf.String() != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, buf.String()))
+	}
+}
@@ -5,82 +5,14 @@ import (
 	"strconv"
 	"strings"
 
-	"reflect"
-
 	"github.com/dagger/graphql/language/ast"
 	"github.com/dagger/graphql/language/visitor"
 )
 
-func getMapValue(m map[string]interface{}, key string) interface{} {
-	tokens := strings.Split(key, ".")
-	valMap := m
-	for _, token := range tokens {
-		v, ok := valMap[token]
-		if !ok {
-			return nil
-		}
-		switch v := v.(type) {
-		case []interface{}:
-			return v
-		case map[string]interface{}:
-			valMap = v
-			continue
-		default:
-			return v
-		}
-	}
-	return valMap
-}
-func getMapSliceValue(m map[string]interface{}, key string) []interface{} {
-	tokens := strings.Split(key, ".")
-	valMap := m
-	for _, token := range tokens {
-		v, ok := valMap[token]
-		if !ok {
-			return []interface{}{}
-		}
-		switch v := v.(type) {
-		case []interface{}:
-			return v
-		default:
-			return []interface{}{}
-		}
-	}
-	return []interface{}{}
-}
-func getMapValueString(m map[string]interface{}, key string) string {
-	tokens := strings.Split(key, ".")
-	valMap := m
-	for _, token := range tokens {
-		v, ok := valMap[token]
-		if !ok {
-			return ""
-		}
-		if v == nil {
-			return ""
-		}
-		switch v := v.(type) {
-		case map[string]interface{}:
-			valMap = v
-			continue
-		case string:
-			return v
-		default:
-			return fmt.Sprintf("%v", v)
-		}
-	}
-	return ""
-}
-func getDescription(raw interface{}) string {
+func getDescription(node ast.DescribableNode) string {
 	var desc string
-
-	switch node := raw.(type) {
-	case ast.DescribableNode:
-		if sval := node.GetDescription(); sval != nil {
-			desc = sval.Value
-		}
-	case map[string]interface{}:
-		desc = getMapValueString(node, "Description.Value")
+	if sval := node.GetDescription(); sval != nil {
+		desc = sval.Value
 	}
 	if desc != "" {
 		sep := ""
@@ -88,29 +20,42 @@ func getDescription(raw interface{}) string {
 			sep = "\n"
 		}
 		desc = join([]string{`"""`, desc, `"""`}, sep)
+		desc += descriptionComments(node)
 	}
 	return desc
 }
 
-func toSliceString(slice interface{}) []string {
-	if slice == nil {
-		return []string{}
-	}
-	res := []string{}
-	switch reflect.TypeOf(slice).Kind() {
-	case reflect.Slice:
-		s := reflect.ValueOf(slice)
-		for i := 0; i < s.Len(); i++ {
-			elem := s.Index(i)
-			elemInterface := elem.Interface()
-			if elem, ok := elemInterface.(string); ok {
-				res = append(res, elem)
+// printString prints value as a string literal, escaping the characters the
+// GraphQL grammar does not allow in strings.
+func printString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
 			}
 		}
-		return res
-	default:
-		return res
 	}
+	b.WriteByte('"')
+	return b.String()
 }
 
 func join(str []string, sep string) string {
@@ -133,88 +78,186 @@ func wrap(start, maybeString, end string) string {
 }
 
 // Given array, print each item on its own line, wrapped in an indented "{ }" block.
-func block(maybeArray interface{}) string {
-	s := toSliceString(maybeArray)
+func block(s []string) string {
 	if len(s) == 0 {
 		return "{}"
 	}
 	return indent("{\n"+join(s, "\n")) + "\n}"
 }
 
-func indent(maybeString interface{}) string {
-	if maybeString == nil {
-		return ""
+func indent(str string) string {
+	return strings.Replace(str, "\n", "\n  ", -1)
+}
+
+// printArguments prints the arguments of a field or directive definition, on
+// their own lines if any of them has a description or comments.
+func printArguments(args []string, defs []*ast.InputValueDefinition) string {
+	for _, arg := range defs {
+		if arg.Description != nil && arg.Description.Value != "" || arg.Comments != nil {
+			return printList(args, true)
+		}
 	}
-	switch str := maybeString.(type) {
-	case string:
-		return strings.Replace(str, "\n", "\n  ", -1)
+	return printList(args, false)
+}
+
+// printList prints the arguments or variable definitions items between
+// parentheses, on their own lines if multiline.
+func printList(items []string, multiline bool) string {
+	if multiline {
+		return wrap("(", indent("\n"+join(items, "\n")), "\n)")
+	}
+	return wrap("(", join(items, ", "), ")")
+}
+
+// argumentsCommented reports whether any of args has comments, which are
+// then printed on their own lines.
+func argumentsCommented(args []*ast.Argument) bool {
+	for _, arg := range args {
+		if arg.Comments != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// variableDefinitionsCommented reports whether any of varDefs has comments.
+func variableDefinitionsCommented(varDefs []*ast.VariableDefinition) bool {
+	for _, varDef := range varDefs {
+		if varDef.Comments != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func commentValues(comments []*ast.Comment) []string {
+	values := []string{}
+	for _, comment := range comments {
+		values = append(values, "#"+comment.Value)
+	}
+	return values
+}
+
+// descriptionComments returns the comments printed after the description of
+// node, on their own lines.
+func descriptionComments(node interface{}) string {
+	if node, ok := node.(ast.CommentedNode); ok && node.GetComments() != nil {
+		if comments := node.GetComments().AfterDescription; len(comments) > 0 {
+			return "\n" + join(commentValues(comments), "\n")
+		}
 	}
 	return ""
 }
 
-var printDocASTReducer = map[string]visitor.VisitFunc{
-	"Name": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Name:
-			return visitor.ActionUpdate, node.Value
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValue(node, "Value")
+func getComments(node ast.CommentedNode) (leading []string, trailing string, dangling []string) {
+	if group := node.GetComments(); group != nil {
+		leading, dangling = commentValues(group.Leading), commentValues(group.Dangling)
+		if group.Trailing != nil {
+			trailing = "#" + group.Trailing.Value
 		}
-		return visitor.ActionNoChange, nil
-	},
-	"Variable": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Variable:
-			return visitor.ActionUpdate, fmt.Sprintf("$%v", node.Name)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, "$" + getMapValueString(node, "Name")
+	}
+	return leading, trailing, dangling
+}
+
+// printComments surrounds the printed node str with its comments: the leading
+// ones on the lines before, the trailing one at the end of its last line and
+// the dangling ones on the lines after.
+func printComments(node ast.CommentedNode, str string) string {
+	leading, trailing, dangling := getComments(node)
+	if len(leading) == 0 && trailing == "" && len(dangling) == 0 {
+		return str
+	}
+	// described fields and arguments are preceded by an empty line
+	prefix := ""
+	if strings.HasPrefix(str, "\n") {
+		prefix, str = "\n", str[1:]
+	}
+	str = join([]string{str, trailing}, " ")
+	lines := append(leading, str)
+	lines = append(lines, dangling...)
+	return prefix + join(lines, "\n")
+}
+
+// printed is the printed form of a node, along with the key of its parent
+// field holding it.
+type printed struct {
+	key string
+	str string
+}
+
+// printer prints the nodes once their children have been printed: the printed
+// children of the nodes being walked are kept on a stack.
+type printer struct {
+	stack  []printed
+	frames []int
+}
+
+// children returns the printed children of the node being left.
+func (p *printer) children() []printed {
+	return p.stack[p.frames[len(p.frames)-1]:]
+}
+
+// part returns the printed child held by the field key.
+func (p *printer) part(key string) string {
+	for _, child := range p.children() {
+		if child.key == key {
+			return child.str
 		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Document
-	"Document": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Document:
-			definitions := toSliceString(node.Definitions)
-			return visitor.ActionUpdate, join(definitions, "\n\n") + "\n"
-		case map[string]interface{}:
-			definitions := toSliceString(getMapValue(node, "Definitions"))
-			return visitor.ActionUpdate, join(definitions, "\n\n") + "\n"
+	}
+	return ""
+}
+
+// parts returns the printed children held by the list field key.
+func (p *printer) parts(key string) []string {
+	strs := []string{}
+	for _, child := range p.children() {
+		if child.key == key {
+			strs = append(strs, child.str)
 		}
-		return visitor.ActionNoChange, nil
-	},
-	"OperationDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.OperationDefinition:
-			op := string(node.Operation)
-			name := fmt.Sprintf("%v", node.Name)
-
-			varDefs := wrap("(", join(toSliceString(node.VariableDefinitions), ", "), ")")
-			directives := join(toSliceString(node.Directives), " ")
-			selectionSet := fmt.Sprintf("%v", node.SelectionSet)
-			// Anonymous queries with no directives or variable definitions can use
-			// the query short form.
-			str := ""
-			if name == "" && directives == "" && varDefs == "" && op == ast.OperationTypeQuery {
-				str = selectionSet
-			} else {
-				str = join([]string{
-					op,
-					join([]string{name, varDefs}, ""),
-					directives,
-					selectionSet,
-				}, " ")
-			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
+	}
+	return strs
+}
+
+// set replaces the printed children of the node being left with str.
+func (p *printer) set(c *visitor.Cursor, str string) visitor.Action {
+	if node, ok := c.Node().(ast.CommentedNode); ok {
+		if _, ok := node.(*ast.Document); !ok {
+			str = printComments(node, str)
+		}
+	}
+	start := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	p.stack = append(p.stack[:start], printed{key: c.Key(), str: str})
+	return visitor.Continue
+}
+
+func (p *printer) visitor() *visitor.Visitor {
+	return &visitor.Visitor{
+		Enter: func(c *visitor.Cursor) visitor.Action {
+			p.frames = append(p.frames, len(p.stack))
+			return visitor.Continue
+		},
 
-			op := getMapValueString(node, "Operation")
-			name := getMapValueString(node, "Name")
+		LeaveName: func(node *ast.Name, c *visitor.Cursor) visitor.Action {
+			return p.set(c, node.Value)
+		},
+		LeaveVariable: func(node *ast.Variable, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "$"+p.part("Name"))
+		},
 
-			varDefs := wrap("(", join(toSliceString(getMapValue(node, "VariableDefinitions")), ", "), ")")
-			directives := join(toSliceString(getMapValue(node, "Directives")), " ")
-			selectionSet := getMapValueString(node, "SelectionSet")
+		// Document
+		LeaveDocument: func(node *ast.Document, c *visitor.Cursor) visitor.Action {
+			return p.set(c, printComments(node, join(p.parts("Definitions"), "\n\n"))+"\n")
+		},
+		LeaveOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			op := node.Operation
+			name := p.part("Name")
+
+			varDefs := printList(p.parts("VariableDefinitions"), variableDefinitionsCommented(node.VariableDefinitions))
+			directives := join(p.parts("Directives"), " ")
+			selectionSet := p.part("SelectionSet")
+			// Anonymous queries with no directives or variable definitions can use
+			// the query short form.
 			str := ""
 			if name == "" && directives == "" && varDefs == "" && op == ast.OperationTypeQuery {
 				str = selectionSet
@@ -226,728 +269,273 @@ var printDocASTReducer = map[string]visitor.VisitFunc{
 					selectionSet,
 				}, " ")
 			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"VariableDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.VariableDefinition:
-			variable := fmt.Sprintf("%v", node.Variable)
-			ttype := fmt.Sprintf("%v", node.Type)
-			defaultValue := fmt.Sprintf("%v", node.DefaultValue)
-
-			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "")
-		case map[string]interface{}:
-
-			variable := getMapValueString(node, "Variable")
-			ttype := getMapValueString(node, "Type")
-			defaultValue := getMapValueString(node, "DefaultValue")
-
-			return visitor.ActionUpdate, variable + ": " + ttype + wrap(" = ", defaultValue, "")
-
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"SelectionSet": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.SelectionSet:
-			str := block(node.Selections)
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			selections := getMapValue(node, "Selections")
-			str := block(selections)
-			return visitor.ActionUpdate, str
-
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"Field": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Argument:
-			name := fmt.Sprintf("%v", node.Name)
-			value := fmt.Sprintf("%v", node.Value)
-			return visitor.ActionUpdate, name + ": " + value
-		case map[string]interface{}:
-
-			alias := getMapValueString(node, "Alias")
-			name := getMapValueString(node, "Name")
-			args := toSliceString(getMapValue(node, "Arguments"))
-			directives := toSliceString(getMapValue(node, "Directives"))
-			selectionSet := getMapValueString(node, "SelectionSet")
+			return p.set(c, str)
+		},
+		LeaveVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			variable := p.part("Variable")
+			ttype := p.part("Type")
+			defaultValue := p.part("DefaultValue")
+			directives := join(p.parts("Directives"), " ")
+			return p.set(c, variable+": "+ttype+wrap(" = ", defaultValue, "")+wrap(" ", directives, ""))
+		},
+		LeaveSelectionSet: func(node *ast.SelectionSet, c *visitor.Cursor) visitor.Action {
+			return p.set(c, block(p.parts("Selections")))
+		},
+		LeaveField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			alias := p.part("Alias")
+			name := p.part("Name")
+			args := p.parts("Arguments")
+			nullability := p.part("Nullability")
+			directives := p.parts("Directives")
+			selectionSet := p.part("SelectionSet")
 
 			str := join(
 				[]string{
-					wrap("", alias, ": ") + name + wrap("(", join(args, ", "), ")"),
+					wrap("", alias, ": ") + name + printList(args, argumentsCommented(node.Arguments)) + nullability,
 					join(directives, " "),
 					selectionSet,
 				},
 				" ",
 			)
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"Argument": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.FragmentSpread:
-			name := fmt.Sprintf("%v", node.Name)
-			directives := toSliceString(node.Directives)
-			return visitor.ActionUpdate, "..." + name + wrap(" ", join(directives, " "), "")
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			value := getMapValueString(node, "Value")
-			return visitor.ActionUpdate, name + ": " + value
-		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Fragments
-	"FragmentSpread": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.InlineFragment:
-			typeCondition := fmt.Sprintf("%v", node.TypeCondition)
-			directives := toSliceString(node.Directives)
-			selectionSet := fmt.Sprintf("%v", node.SelectionSet)
-			return visitor.ActionUpdate, "... on " + typeCondition + " " + wrap("", join(directives, " "), " ") + selectionSet
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			directives := toSliceString(getMapValue(node, "Directives"))
-			return visitor.ActionUpdate, "..." + name + wrap(" ", join(directives, " "), "")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"InlineFragment": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case map[string]interface{}:
-			typeCondition := getMapValueString(node, "TypeCondition")
-			directives := toSliceString(getMapValue(node, "Directives"))
-			selectionSet := getMapValueString(node, "SelectionSet")
-			return visitor.ActionUpdate,
+			return p.set(c, str)
+		},
+		LeaveArgument: func(node *ast.Argument, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Name")+": "+p.part("Value"))
+		},
+
+		// Fragments
+		LeaveFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
+			name := p.part("Name")
+			directives := p.parts("Directives")
+			return p.set(c, "..."+name+wrap(" ", join(directives, " "), ""))
+		},
+		LeaveInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+			typeCondition := p.part("TypeCondition")
+			directives := p.parts("Directives")
+			selectionSet := p.part("SelectionSet")
+			return p.set(c,
 				join([]string{
 					"...",
 					wrap("on ", typeCondition, ""),
 					join(directives, " "),
 					selectionSet,
-				}, " ")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"FragmentDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.FragmentDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			typeCondition := fmt.Sprintf("%v", node.TypeCondition)
-			directives := toSliceString(node.Directives)
-			selectionSet := fmt.Sprintf("%v", node.SelectionSet)
-			return visitor.ActionUpdate, "fragment " + name + " on " + typeCondition + " " + wrap("", join(directives, " "), " ") + selectionSet
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			typeCondition := getMapValueString(node, "TypeCondition")
-			directives := toSliceString(getMapValue(node, "Directives"))
-			selectionSet := getMapValueString(node, "SelectionSet")
-			return visitor.ActionUpdate, "fragment " + name + " on " + typeCondition + " " + wrap("", join(directives, " "), " ") + selectionSet
-		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Value
-	"IntValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.IntValue:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Value)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Value")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"FloatValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.FloatValue:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Value)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Value")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"StringValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.StringValue:
-			return visitor.ActionUpdate, strconv.Quote(node.Value)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, `"` + getMapValueString(node, "Value") + `"`
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"BooleanValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.BooleanValue:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Value)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Value")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"EnumValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.EnumValue:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Value)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Value")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"ListValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.ListValue:
-			return visitor.ActionUpdate, "[" + join(toSliceString(node.Values), ", ") + "]"
-		case map[string]interface{}:
-			return visitor.ActionUpdate, "[" + join(toSliceString(getMapValue(node, "Values")), ", ") + "]"
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"ObjectValue": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.ObjectValue:
-			return visitor.ActionUpdate, "{" + join(toSliceString(node.Fields), ", ") + "}"
-		case map[string]interface{}:
-			return visitor.ActionUpdate, "{" + join(toSliceString(getMapValue(node, "Fields")), ", ") + "}"
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"ObjectField": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.ObjectField:
-			name := fmt.Sprintf("%v", node.Name)
-			value := fmt.Sprintf("%v", node.Value)
-			return visitor.ActionUpdate, name + ": " + value
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			value := getMapValueString(node, "Value")
-			return visitor.ActionUpdate, name + ": " + value
-		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Directive
-	"Directive": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Directive:
-			name := fmt.Sprintf("%v", node.Name)
-			args := toSliceString(node.Arguments)
-			return visitor.ActionUpdate, "@" + name + wrap("(", join(args, ", "), ")")
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			args := toSliceString(getMapValue(node, "Arguments"))
-			return visitor.ActionUpdate, "@" + name + wrap("(", join(args, ", "), ")")
-		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Type
-	"Named": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.Named:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Name)
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Name")
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"List": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.List:
-			return visitor.ActionUpdate, "[" + fmt.Sprintf("%v", node.Type) + "]"
-		case map[string]interface{}:
-			return visitor.ActionUpdate, "[" + getMapValueString(node, "Type") + "]"
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"NonNull": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.NonNull:
-			return visitor.ActionUpdate, fmt.Sprintf("%v", node.Type) + "!"
-		case map[string]interface{}:
-			return visitor.ActionUpdate, getMapValueString(node, "Type") + "!"
-		}
-		return visitor.ActionNoChange, nil
-	},
-
-	// Type System Definitions
-	"SchemaDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.SchemaDefinition:
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+				}, " "))
+		},
+		LeaveFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			name := p.part("Name")
+			typeCondition := p.part("TypeCondition")
+			directives := p.parts("Directives")
+			selectionSet := p.part("SelectionSet")
+			return p.set(c, "fragment "+name+" on "+typeCondition+" "+wrap("", join(directives, " "), " ")+selectionSet)
+		},
+
+		// Value
+		LeaveIntValue: func(node *ast.IntValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, node.Value)
+		},
+		LeaveFloatValue: func(node *ast.FloatValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, node.Value)
+		},
+		LeaveStringValue: func(node *ast.StringValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, printString(node.Value))
+		},
+		LeaveBooleanValue: func(node *ast.BooleanValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, strconv.FormatBool(node.Value))
+		},
+		LeaveEnumValue: func(node *ast.EnumValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, node.Value)
+		},
+		LeaveListValue: func(node *ast.ListValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "["+join(p.parts("Values"), ", ")+"]")
+		},
+		LeaveObjectValue: func(node *ast.ObjectValue, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "{"+join(p.parts("Fields"), ", ")+"}")
+		},
+		LeaveObjectField: func(node *ast.ObjectField, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Name")+": "+p.part("Value"))
+		},
+
+		// Directive
+		LeaveDirective: func(node *ast.Directive, c *visitor.Cursor) visitor.Action {
+			name := p.part("Name")
+			args := p.parts("Arguments")
+			return p.set(c, "@"+name+printList(args, argumentsCommented(node.Arguments)))
+		},
+
+		// Type
+		LeaveNamed: func(node *ast.Named, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Name"))
+		},
+		LeaveList: func(node *ast.List, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "["+p.part("Type")+"]")
+		},
+		LeaveNonNull: func(node *ast.NonNull, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Type")+"!")
+		},
+
+		// Client Controlled Nullability
+		LeaveRequiredDesignator: func(node *ast.RequiredDesignator, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Element")+"!")
+		},
+		LeaveOptionalDesignator: func(node *ast.OptionalDesignator, c *visitor.Cursor) visitor.Action {
+			return p.set(c, p.part("Element")+"?")
+		},
+		LeaveListNullability: func(node *ast.ListNullability, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "["+p.part("Element")+"]")
+		},
+
+		// Type System Definitions
+		LeaveSchemaDefinition: func(node *ast.SchemaDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"schema",
-				join(directives, " "),
-				block(node.OperationTypes),
+				join(p.parts("Directives"), " "),
+				block(p.parts("OperationTypes")),
 			}, " ")
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			operationTypes := toSliceString(getMapValue(node, "OperationTypes"))
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			str := join([]string{
-				"schema",
-				join(directives, " "),
-				block(operationTypes),
-			}, " ")
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"OperationTypeDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.OperationTypeDefinition:
-			str := fmt.Sprintf("%v: %v", node.Operation, node.Type)
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			operation := getMapValueString(node, "Operation")
-			ttype := getMapValueString(node, "Type")
-			str := fmt.Sprintf("%v: %v", operation, ttype)
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"ScalarDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.ScalarDefinition:
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+			return p.set(c, str)
+		},
+		LeaveOperationTypeDefinition: func(node *ast.OperationTypeDefinition, c *visitor.Cursor) visitor.Action {
+			return p.set(c, fmt.Sprintf("%v: %v", node.Operation, p.part("Type")))
+		},
+		LeaveScalarDefinition: func(node *ast.ScalarDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"scalar",
-				fmt.Sprintf("%v", node.Name),
-				join(directives, " "),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			str := join([]string{
-				"scalar",
-				name,
-				join(directives, " "),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"ObjectDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.ObjectDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			interfaces := toSliceString(node.Interfaces)
-			fields := node.Fields
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
-			str := join([]string{
-				"type",
-				name,
-				wrap("implements ", join(interfaces, " & "), ""),
-				join(directives, " "),
-				block(fields),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			interfaces := toSliceString(getMapValue(node, "Interfaces"))
-			fields := getMapValue(node, "Fields")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
+			return p.set(c, str)
+		},
+		LeaveObjectDefinition: func(node *ast.ObjectDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"type",
-				name,
-				wrap("implements ", join(interfaces, " & "), ""),
-				join(directives, " "),
-				block(fields),
+				p.part("Name"),
+				wrap("implements ", join(p.parts("Interfaces"), " & "), ""),
+				join(p.parts("Directives"), " "),
+				block(p.parts("Fields")),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"FieldDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.FieldDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			ttype := fmt.Sprintf("%v", node.Type)
-			args := toSliceString(node.Arguments)
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
-			hasArgDesc := false
-			for _, arg := range node.Arguments {
-				if arg.Description != nil && arg.Description.Value != "" {
-					hasArgDesc = true
-					break
-				}
-			}
-			var argsStr string
-			if hasArgDesc {
-				argsStr = wrap("(", indent("\n"+join(args, "\n")), "\n)")
-			} else {
-				argsStr = wrap("(", join(args, ", "), ")")
-			}
+			return p.set(c, str)
+		},
+		LeaveFieldDefinition: func(node *ast.FieldDefinition, c *visitor.Cursor) visitor.Action {
+			name := p.part("Name")
+			ttype := p.part("Type")
+			argsStr := printArguments(p.parts("Arguments"), node.Arguments)
+			directives := p.parts("Directives")
 			str := name + argsStr + ": " + ttype + wrap(" ", join(directives, " "), "")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("\n%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			ttype := getMapValueString(node, "Type")
-			args := toSliceString(getMapValue(node, "Arguments"))
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			hasArgDesc := false
-			for _, arg := range args {
-				if strings.HasPrefix(strings.TrimSpace(arg), `"""`) {
-					hasArgDesc = true
-					break
-				}
-			}
-			var argsStr string
-			if hasArgDesc {
-				argsStr = wrap("(", indent("\n"+join(args, "\n")), "\n)")
-			} else {
-				argsStr = wrap("(", join(args, ", "), ")")
-			}
-			str := name + argsStr + ": " + ttype + wrap(" ", join(directives, " "), "")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("\n%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"InputValueDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.InputValueDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			ttype := fmt.Sprintf("%v", node.Type)
-			defaultValue := fmt.Sprintf("%v", node.DefaultValue)
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+			return p.set(c, str)
+		},
+		LeaveInputValueDefinition: func(node *ast.InputValueDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
-				name + ": " + ttype,
-				wrap("= ", defaultValue, ""),
-				join(directives, " "),
+				p.part("Name") + ": " + p.part("Type"),
+				wrap("= ", p.part("DefaultValue"), ""),
+				join(p.parts("Directives"), " "),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("\n%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			ttype := getMapValueString(node, "Type")
-			defaultValue := getMapValueString(node, "DefaultValue")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			str := join([]string{
-				name + ": " + ttype,
-				wrap("= ", defaultValue, ""),
-				join(directives, " "),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("\n%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"InterfaceDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.InterfaceDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			fields := node.Fields
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
-			str := join([]string{
-				"interface",
-				name,
-				join(directives, " "),
-				block(fields),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			fields := getMapValue(node, "Fields")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
+			return p.set(c, str)
+		},
+		LeaveInterfaceDefinition: func(node *ast.InterfaceDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"interface",
-				name,
-				join(directives, " "),
-				block(fields),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
+				block(p.parts("Fields")),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"UnionDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.UnionDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			types := toSliceString(node.Types)
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+			return p.set(c, str)
+		},
+		LeaveUnionDefinition: func(node *ast.UnionDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"union",
-				name,
-				join(directives, " "),
-				"= " + join(types, " | "),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
+				"= " + join(p.parts("Types"), " | "),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			types := toSliceString(getMapValue(node, "Types"))
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			str := join([]string{
-				"union",
-				name,
-				join(directives, " "),
-				"= " + join(types, " | "),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"EnumDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.EnumDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			values := node.Values
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+			return p.set(c, str)
+		},
+		LeaveEnumDefinition: func(node *ast.EnumDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"enum",
-				name,
-				join(directives, " "),
-				block(values),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
+				block(p.parts("Values")),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			values := getMapValue(node, "Values")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
-			str := join([]string{
-				"enum",
-				name,
-				join(directives, " "),
-				block(values),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"EnumValueDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.EnumValueDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
-			str := join([]string{
-				name,
-				join(directives, " "),
-			}, " ")
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("\n%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
-			}
+			return p.set(c, str)
+		},
+		LeaveEnumValueDefinition: func(node *ast.EnumValueDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
-				name,
-				join(directives, " "),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("\n%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"InputObjectDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.InputObjectDefinition:
-			name := fmt.Sprintf("%v", node.Name)
-			fields := node.Fields
-			directives := []string{}
-			for _, directive := range node.Directives {
-				directives = append(directives, fmt.Sprintf("%v", directive.Name))
-			}
+			return p.set(c, str)
+		},
+		LeaveInputObjectDefinition: func(node *ast.InputObjectDefinition, c *visitor.Cursor) visitor.Action {
 			str := join([]string{
 				"input",
-				name,
-				join(directives, " "),
-				block(fields),
+				p.part("Name"),
+				join(p.parts("Directives"), " "),
+				block(p.parts("Fields")),
 			}, " ")
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			fields := getMapValue(node, "Fields")
-			directives := []string{}
-			for _, directive := range getMapSliceValue(node, "Directives") {
-				directives = append(directives, fmt.Sprintf("%v", directive))
+			return p.set(c, str)
+		},
+		LeaveTypeExtensionDefinition: func(node *ast.TypeExtensionDefinition, c *visitor.Cursor) visitor.Action {
+			return p.set(c, "extend "+p.part("Definition"))
+		},
+		LeaveSchemaExtensionDefinition: func(node *ast.SchemaExtensionDefinition, c *visitor.Cursor) visitor.Action {
+			operationTypes := ""
+			if len(node.OperationTypes) > 0 {
+				operationTypes = block(p.parts("OperationTypes"))
 			}
 			str := join([]string{
-				"input",
-				name,
-				join(directives, " "),
-				block(fields),
+				"extend schema",
+				join(p.parts("Directives"), " "),
+				operationTypes,
 			}, " ")
+			return p.set(c, str)
+		},
+		LeaveDirectiveDefinition: func(node *ast.DirectiveDefinition, c *visitor.Cursor) visitor.Action {
+			argsStr := printArguments(p.parts("Arguments"), node.Arguments)
+			str := fmt.Sprintf("directive @%v%v on %v", p.part("Name"), argsStr, join(p.parts("Locations"), " | "))
 			if desc := getDescription(node); desc != "" {
 				str = fmt.Sprintf("%s\n%s", desc, str)
 			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"TypeExtensionDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.TypeExtensionDefinition:
-			definition := fmt.Sprintf("%v", node.Definition)
-			str := "extend " + definition
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			definition := getMapValueString(node, "Definition")
-			str := "extend " + definition
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
-	"DirectiveDefinition": func(p visitor.VisitFuncParams) (string, interface{}) {
-		switch node := p.Node.(type) {
-		case *ast.DirectiveDefinition:
-			args := toSliceString(node.Arguments)
-			hasArgDesc := false
-			for _, arg := range node.Arguments {
-				if arg.Description != nil && arg.Description.Value != "" {
-					hasArgDesc = true
-					break
-				}
-			}
-			var argsStr string
-			if hasArgDesc {
-				argsStr = wrap("(", indent("\n"+join(args, "\n")), "\n)")
-			} else {
-				argsStr = wrap("(", join(args, ", "), ")")
-			}
-			str := fmt.Sprintf("directive @%v%v on %v", node.Name, argsStr, join(toSliceString(node.Locations), " | "))
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		case map[string]interface{}:
-			name := getMapValueString(node, "Name")
-			locations := toSliceString(getMapValue(node, "Locations"))
-			args := toSliceString(getMapValue(node, "Arguments"))
-			hasArgDesc := false
-			for _, arg := range args {
-				if strings.HasPrefix(strings.TrimSpace(arg), `"""`) {
-					hasArgDesc = true
-					break
-				}
-			}
-			var argsStr string
-			if hasArgDesc {
-				argsStr = wrap("(", indent("\n"+join(args, "\n")), "\n)")
-			} else {
-				argsStr = wrap("(", join(args, ", "), ")")
-			}
-			str := fmt.Sprintf("directive @%v%v on %v", name, argsStr, join(locations, " | "))
-			if desc := getDescription(node); desc != "" {
-				str = fmt.Sprintf("%s\n%s", desc, str)
-			}
-			return visitor.ActionUpdate, str
-		}
-		return visitor.ActionNoChange, nil
-	},
+			return p.set(c, str)
+		},
+	}
 }
 
 func Print(astNode ast.Node) (printed interface{}) {
-	defer func() interface{} {
+	defer func() {
 		if r := recover(); r != nil {
-			return fmt.Sprintf("%v", astNode)
+			printed = fmt.Sprintf("%v", astNode)
 		}
-		return printed
 	}()
-	printed = visitor.Visit(astNode, &visitor.VisitorOptions{
-		LeaveKindMap: printDocASTReducer,
-	}, nil)
-	return printed
+	if astNode == nil {
+		return nil
+	}
+	p := &printer{}
+	visitor.Walk(astNode, p.visitor())
+	return p.stack[0].str
 }
@@ -200,3 +200,66 @@ func TestPrinter_CorrectlyPrintsStringArgumentsWithProperQuoting(t *testing.T) {
 		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
 	}
 }
+
+func TestPrinter_PrintsVariableDefinitionDirectives(t *testing.T) {
+	queryAst := `query Q($a: Int = 1 @deprecated(reason: "no"), $b: [String] @a @b) { foo(a: $a, b: $b) }`
+	expected := `query Q($a: Int = 1 @deprecated(reason: "no"), $b: [String] @a @b) {
+  foo(a: $a, b: $b)
+}
+`
+	astDoc := parse(t, queryAst)
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// the printed operation parses back to the same document
+	if reprinted := printer.Print(parse(t, expected)); !reflect.DeepEqual(expected, reprinted) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, reprinted))
+	}
+}
+
+func TestPrinter_PrintsClientControlledNullability(t *testing.T) {
+	query := `{
+  a!
+  b?
+  c[!]?
+  d[[]]! {
+    e(x: 1)[] @skip(if: false)
+  }
+}
+`
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source:  query,
+		Options: parser.ParseOptions{ExperimentalClientControlledNullability: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(query, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(query, results))
+	}
+	expected := `{a! b? c[!]? d[[]]!{e(x:1)[]@skip(if:false)}}`
+	if results := printer.Format(astDoc, printer.Options{Compact: true}); results != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestPrinter_EscapesStringsWithGraphQLEscapeSequences(t *testing.T) {
+	astDoc := ast.NewStringValue(&ast.StringValue{
+		Value: "tab\t, bell\a, del\x7f, quote\", backslash\\, unicodeé",
+	})
+	expected := `"tab\t, bell\u0007, del\u007F, quote\", backslash\\, unicode` + "é" + `"`
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+
+	// the printed string parses back to the same value
+	query := parse(t, `{ foo(str: `+expected+`) }`)
+	value := query.Definitions[0].(*ast.OperationDefinition).SelectionSet.Selections[0].(*ast.Field).Arguments[0].Value
+	if value.GetValue() != astDoc.Value {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(astDoc.Value, value.GetValue()))
+	}
+}
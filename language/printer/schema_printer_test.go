@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
 	"github.com/dagger/graphql/language/printer"
 	"github.com/dagger/graphql/testutil"
 )
@@ -84,6 +85,8 @@ union Feed = Story | Article | Advert
 
 union AnnotatedUnion @onUnion = A | B
 
+union AnnotatedUnionTwo @onUnion = A | B
+
 scalar CustomScalar
 
 scalar AnnotatedScalar @onScalar
@@ -115,9 +118,18 @@ extend type Foo @onType {}
 
 type NoFields {}
 
+extend schema @onSchema
+
+extend schema @onSchema {
+  subscription: SubscriptionType
+}
+
 directive @skip(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
 
 directive @include(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
+
+"""This is a description of the @include2 directive."""
+directive @include2(if: Boolean!) on FIELD | FRAGMENT_SPREAD | INLINE_FRAGMENT
 `
 	results := printer.Print(astDoc)
 	if !reflect.DeepEqual(expected, results) {
@@ -305,3 +317,34 @@ directive @DirectiveMultiLine on SCALAR
 		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
 	}
 }
+
+func TestSchemaPrinter_PrintsComments(t *testing.T) {
+	source := `# Foo type
+type Foo {
+  # field
+  a(
+    # argument
+    x: Int # after x
+  ): Int # after a
+  b: String
+  # end of Foo
+}
+
+enum Color {
+  RED # red
+  GREEN
+}
+# end of document
+`
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source:  source,
+		Options: parser.ParseOptions{NoLocation: true, KeepComments: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	results := printer.Print(astDoc)
+	if !reflect.DeepEqual(source, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(source, results))
+	}
+}
@@ -1,7 +1,8 @@
 package source
 
 const (
-	name = "GraphQL"
+	// DefaultName is the name of the sources created without one.
+	DefaultName = "GraphQL"
 )
 
 type Source struct {
@@ -11,10 +12,10 @@ type Source struct {
 
 func NewSource(s *Source) *Source {
 	if s == nil {
-		s = &Source{Name: name}
+		s = &Source{Name: DefaultName}
 	}
 	if s.Name == "" {
-		s.Name = name
+		s.Name = DefaultName
 	}
 	return s
 }
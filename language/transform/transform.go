@@ -0,0 +1,428 @@
+// Package transform provides composable rewriters of GraphQL documents, such
+// as adding __typename fields or inlining fragments. A Transform returns a new
+// document and never modifies its input, so that transforms can be chained and
+// applied to cached documents.
+package transform
+
+import (
+	"fmt"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/visitor"
+)
+
+// Transform rewrites a document into a new one. It does not modify doc.
+type Transform func(doc *ast.Document) (*ast.Document, error)
+
+// Chain returns a Transform applying transforms in order. It stops at the
+// first error.
+func Chain(transforms ...Transform) Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		for _, transform := range transforms {
+			var err error
+			if doc, err = transform(doc); err != nil {
+				return nil, err
+			}
+		}
+		return doc, nil
+	}
+}
+
+// Apply applies transforms to doc in order, see Chain.
+func Apply(doc *ast.Document, transforms ...Transform) (*ast.Document, error) {
+	return Chain(transforms...)(doc)
+}
+
+// edit walks a copy of doc with v, and returns the edited copy.
+func edit(doc *ast.Document, v *visitor.Visitor) *ast.Document {
+	if doc == nil {
+		return nil
+	}
+	return visitor.Walk(ast.Clone(doc), v).(*ast.Document)
+}
+
+func newName(value string) *ast.Name {
+	return ast.NewName(&ast.Name{Value: value})
+}
+
+// AddTypename adds a __typename field to every selection set but the ones of
+// the operations, unless it already selects __typename. Clients use it to
+// know the type of the objects they cache.
+func AddTypename() Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		return edit(doc, &visitor.Visitor{
+			EnterSelectionSet: func(node *ast.SelectionSet, c *visitor.Cursor) visitor.Action {
+				if _, ok := c.Parent().(*ast.OperationDefinition); ok {
+					return visitor.Continue
+				}
+				for _, selection := range node.Selections {
+					if field, ok := selection.(*ast.Field); ok && responseName(field) == "__typename" {
+						return visitor.Continue
+					}
+				}
+				node.Selections = append(node.Selections, ast.NewField(&ast.Field{
+					Name:       newName("__typename"),
+					Arguments:  []*ast.Argument{},
+					Directives: []*ast.Directive{},
+				}))
+				return visitor.Continue
+			},
+		}), nil
+	}
+}
+
+func responseName(field *ast.Field) string {
+	if field.Alias != nil {
+		return field.Alias.Value
+	}
+	if field.Name != nil {
+		return field.Name.Value
+	}
+	return ""
+}
+
+// InlineFragments replaces the fragment spreads with inline fragments of the
+// same type condition, holding the directives of both the spread and the
+// fragment definition, and removes the fragment definitions. It fails if a
+// spread fragment is not defined, or spreads itself.
+func InlineFragments() Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		var err error
+		fragments := fragmentDefinitions(doc)
+		// spreading holds the names of the fragments being inlined, by inline
+		// fragment, to detect cycles.
+		spreading := map[*ast.InlineFragment]string{}
+		inlining := map[string]bool{}
+		doc = edit(doc, &visitor.Visitor{
+			EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+				c.Delete()
+				return visitor.Skip
+			},
+			EnterFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
+				name := ""
+				if node.Name != nil {
+					name = node.Name.Value
+				}
+				fragment, ok := fragments[name]
+				if !ok {
+					err = fmt.Errorf(`Unknown fragment "%v".`, name)
+					return visitor.Break
+				}
+				if inlining[name] {
+					err = fmt.Errorf(`Cannot spread fragment "%v" within itself.`, name)
+					return visitor.Break
+				}
+				directives := append([]*ast.Directive{}, node.Directives...)
+				for _, directive := range fragment.Directives {
+					directives = append(directives, ast.Clone(directive).(*ast.Directive))
+				}
+				inline := ast.NewInlineFragment(&ast.InlineFragment{
+					Loc:        node.Loc,
+					Directives: directives,
+					Comments:   node.Comments,
+				})
+				if fragment.TypeCondition != nil {
+					inline.TypeCondition = ast.Clone(fragment.TypeCondition).(*ast.Named)
+				}
+				if fragment.SelectionSet != nil {
+					inline.SelectionSet = ast.Clone(fragment.SelectionSet).(*ast.SelectionSet)
+				}
+				spreading[inline] = name
+				inlining[name] = true
+				c.Replace(inline)
+				return visitor.Continue
+			},
+			LeaveInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+				if name, ok := spreading[node]; ok {
+					delete(inlining, name)
+				}
+				return visitor.Continue
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+}
+
+func fragmentDefinitions(doc *ast.Document) map[string]*ast.FragmentDefinition {
+	fragments := map[string]*ast.FragmentDefinition{}
+	if doc == nil {
+		return fragments
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	return fragments
+}
+
+// usage holds the fragments spread and the variables used by an operation or
+// a fragment definition.
+type usage struct {
+	spreads   []string
+	variables map[string]bool
+}
+
+// usages returns the usages of the operations and of the fragment
+// definitions of doc, by definition.
+func usages(doc *ast.Document) map[ast.Node]*usage {
+	usages := map[ast.Node]*usage{}
+	var current *usage
+	enter := func(c *visitor.Cursor) visitor.Action {
+		current = &usage{variables: map[string]bool{}}
+		usages[c.Node()] = current
+		return visitor.Continue
+	}
+	visitor.Walk(doc, &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			return enter(c)
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			return enter(c)
+		},
+		EnterVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			// the defined variable is not a usage, and default values and
+			// directives are constant.
+			return visitor.Skip
+		},
+		EnterFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
+			if current != nil && node.Name != nil {
+				current.spreads = append(current.spreads, node.Name.Value)
+			}
+			return visitor.Continue
+		},
+		EnterVariable: func(node *ast.Variable, c *visitor.Cursor) visitor.Action {
+			if current != nil && node.Name != nil {
+				current.variables[node.Name.Value] = true
+			}
+			return visitor.Continue
+		},
+	})
+	return usages
+}
+
+// reachable returns the names of the fragments spread by the definition
+// node, directly or through other fragments.
+func reachable(node ast.Node, usages map[ast.Node]*usage, fragments map[string]*ast.FragmentDefinition) map[string]bool {
+	names := map[string]bool{}
+	queue := []ast.Node{node}
+	for len(queue) > 0 {
+		u := usages[queue[0]]
+		queue = queue[1:]
+		if u == nil {
+			continue
+		}
+		for _, name := range u.spreads {
+			if fragment, ok := fragments[name]; ok && !names[name] {
+				names[name] = true
+				queue = append(queue, fragment)
+			}
+		}
+	}
+	return names
+}
+
+// RemoveUnusedFragments removes the fragment definitions which are not spread
+// by any operation, directly or through other fragments.
+func RemoveUnusedFragments() Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		if doc == nil {
+			return nil, nil
+		}
+		doc = ast.Clone(doc).(*ast.Document)
+		fragments := fragmentDefinitions(doc)
+		usages := usages(doc)
+		used := map[string]bool{}
+		for _, definition := range doc.Definitions {
+			if operation, ok := definition.(*ast.OperationDefinition); ok {
+				for name := range reachable(operation, usages, fragments) {
+					used[name] = true
+				}
+			}
+		}
+		definitions := []ast.Node{}
+		for _, definition := range doc.Definitions {
+			if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil && !used[fragment.Name.Value] {
+				continue
+			}
+			definitions = append(definitions, definition)
+		}
+		doc.Definitions = definitions
+		return doc, nil
+	}
+}
+
+// RemoveUnusedVariables removes the variable definitions of the operations
+// whose variables are not used by the operation, directly or through the
+// fragments it spreads.
+func RemoveUnusedVariables() Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		if doc == nil {
+			return nil, nil
+		}
+		doc = ast.Clone(doc).(*ast.Document)
+		fragments := fragmentDefinitions(doc)
+		usages := usages(doc)
+		for _, definition := range doc.Definitions {
+			operation, ok := definition.(*ast.OperationDefinition)
+			if !ok || usages[operation] == nil {
+				continue
+			}
+			used := usages[operation].variables
+			for name := range reachable(operation, usages, fragments) {
+				for variable := range usages[fragments[name]].variables {
+					used[variable] = true
+				}
+			}
+			varDefs := []*ast.VariableDefinition{}
+			for _, varDef := range operation.VariableDefinitions {
+				if varDef.Variable != nil && varDef.Variable.Name != nil && !used[varDef.Variable.Name.Value] {
+					continue
+				}
+				varDefs = append(varDefs, varDef)
+			}
+			operation.VariableDefinitions = varDefs
+		}
+		return doc, nil
+	}
+}
+
+// RemoveFieldsWithDirective removes the fields, fragment spreads and inline
+// fragments annotated with the directive named name. The fields and
+// fragments whose selections are all removed are removed too, as are the
+// fragment definitions and the operations left without selections, and the
+// spreads of the removed fragments.
+//
+// The variables and fragments only used by the removed selections are kept,
+// see RemoveUnusedVariables and RemoveUnusedFragments.
+func RemoveFieldsWithDirective(name string) Transform {
+	hasDirective := func(directives []*ast.Directive) bool {
+		for _, directive := range directives {
+			if directive.Name != nil && directive.Name.Value == name {
+				return true
+			}
+		}
+		return false
+	}
+	// emptied reports whether the selections of selectionSet were all removed.
+	emptied := func(selectionSet *ast.SelectionSet) bool {
+		return selectionSet != nil && len(selectionSet.Selections) == 0
+	}
+	return func(doc *ast.Document) (*ast.Document, error) {
+		if doc == nil {
+			return nil, nil
+		}
+		doc = ast.Clone(doc).(*ast.Document)
+		removed := map[string]bool{}
+		// removing a fragment definition removes its spreads, which may leave
+		// other selection sets empty: walk until no fragment is removed.
+		for changed := true; changed; {
+			changed = false
+			doc = visitor.Walk(doc, &visitor.Visitor{
+				EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+					if hasDirective(node.Directives) {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+				EnterInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+					if hasDirective(node.Directives) {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+				EnterFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
+					if hasDirective(node.Directives) || node.Name != nil && removed[node.Name.Value] {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+				LeaveField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+					if emptied(node.SelectionSet) {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+				LeaveInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+					if emptied(node.SelectionSet) {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+				LeaveFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+					if emptied(node.SelectionSet) {
+						c.Delete()
+						if node.Name != nil && !removed[node.Name.Value] {
+							removed[node.Name.Value] = true
+							changed = true
+						}
+					}
+					return visitor.Continue
+				},
+				LeaveOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+					if emptied(node.SelectionSet) {
+						c.Delete()
+					}
+					return visitor.Continue
+				},
+			}).(*ast.Document)
+		}
+		return doc, nil
+	}
+}
+
+// StripClientFields removes the fields annotated with @client, which are
+// resolved by the client and unknown to the server. It is
+// RemoveFieldsWithDirective("client").
+func StripClientFields() Transform {
+	return RemoveFieldsWithDirective("client")
+}
+
+// RenameOperations renames every operation to the name returned by rename,
+// which is given the current name of the operation, or "" if it is
+// anonymous. The operation is made anonymous if rename returns "".
+func RenameOperations(rename func(name string) string) Transform {
+	return func(doc *ast.Document) (*ast.Document, error) {
+		var err error
+		doc = edit(doc, &visitor.Visitor{
+			EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+				name := ""
+				if node.Name != nil {
+					name = node.Name.Value
+				}
+				switch renamed := rename(name); {
+				case renamed == name:
+				case renamed == "":
+					node.Name = nil
+				case !isName(renamed):
+					err = fmt.Errorf(`transform: invalid operation name %q`, renamed)
+					return visitor.Break
+				default:
+					node.Name = newName(renamed)
+				}
+				return visitor.Skip
+			},
+			EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+				return visitor.Skip
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+}
+
+// isName reports whether s matches /[_A-Za-z][_0-9A-Za-z]*/.
+func isName(s string) bool {
+	for i, r := range s {
+		letter := r == '_' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,236 @@
+package transform_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/language/transform"
+	"github.com/dagger/graphql/testutil"
+)
+
+func parse(t *testing.T, query string) *ast.Document {
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source: query,
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return astDoc
+}
+
+func expectTransform(t *testing.T, query string, tr transform.Transform, expected string) {
+	astDoc := parse(t, query)
+	results, err := tr(astDoc)
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+	printed := printer.Format(results, printer.Options{Compact: true})
+	if printed != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, printed))
+	}
+}
+
+func TestAddTypename_AddsTypenameToCompositeSelections(t *testing.T) {
+	query := `
+query Q {
+  viewer { name friends { id __typename } }
+  node(id: 1) { ... on User { name } ...F }
+}
+fragment F on Node { id }
+`
+	expected := `query Q{` +
+		`viewer{name friends{id __typename} __typename} ` +
+		`node(id:1){...on User{name __typename} ...F __typename}` +
+		`} ` +
+		`fragment F on Node{id __typename}`
+	expectTransform(t, query, transform.AddTypename(), expected)
+}
+
+func TestAddTypename_KeepsAliasedTypename(t *testing.T) {
+	query := `{ viewer { __typename: name } }`
+	expected := `{viewer{__typename:name}}`
+	expectTransform(t, query, transform.AddTypename(), expected)
+}
+
+func TestInlineFragments_InlinesFragmentSpreads(t *testing.T) {
+	query := `
+query Q {
+  node { ...User @include(if: true) }
+}
+fragment User on User @skip(if: false) {
+  name
+  friends { ...Id }
+}
+fragment Id on Node { id }
+`
+	expected := `query Q{` +
+		`node{...on User@include(if:true)@skip(if:false){name friends{...on Node{id}}}}` +
+		`}`
+	expectTransform(t, query, transform.InlineFragments(), expected)
+}
+
+func TestInlineFragments_InlinesAFragmentSpreadManyTimes(t *testing.T) {
+	query := `
+{ a { ...F } b { ...F } }
+fragment F on T { ...G }
+fragment G on T { id }
+`
+	expected := `{a{...on T{...on T{id}}} b{...on T{...on T{id}}}}`
+	expectTransform(t, query, transform.InlineFragments(), expected)
+}
+
+func TestInlineFragments_ReportsInvalidFragmentSpreads(t *testing.T) {
+	tests := map[string]string{
+		`{ ...Unknown }`: `Unknown fragment "Unknown".`,
+		`{ ...A } fragment A on T { b { ...B } } fragment B on T { ...A }`: `Cannot spread fragment "A" within itself.`,
+	}
+	for query, expected := range tests {
+		_, err := transform.InlineFragments()(parse(t, query))
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error %q for %v, got: %v", expected, query, err)
+		}
+	}
+}
+
+func TestRemoveUnusedFragments_RemovesFragmentsNotReachableFromOperations(t *testing.T) {
+	query := `
+query Q { ...A }
+fragment A on T { ...B }
+fragment B on T { id }
+fragment C on T { ...D }
+fragment D on T { id }
+`
+	expected := `query Q{...A} ` +
+		`fragment A on T{...B} ` +
+		`fragment B on T{id}`
+	expectTransform(t, query, transform.RemoveUnusedFragments(), expected)
+}
+
+func TestRemoveUnusedVariables_RemovesVariablesNotUsedByTheOperation(t *testing.T) {
+	query := `
+query Q($a: Int, $b: Int, $c: Int, $d: Int) @live(a: $d) {
+  f(a: $a) { ...F }
+}
+query R($b: Int) { g }
+fragment F on T { g(b: [{b: $b}]) }
+`
+	expected := `query Q($a:Int,$b:Int,$d:Int)@live(a:$d){f(a:$a){...F}} ` +
+		`query R{g} ` +
+		`fragment F on T{g(b:[{b:$b}])}`
+	expectTransform(t, query, transform.RemoveUnusedVariables(), expected)
+}
+
+func TestStripClientFields_RemovesClientFields(t *testing.T) {
+	query := `
+query Q {
+  viewer {
+    name
+    isLoggedIn @client
+    settings { theme @client }
+    ... on User @client { id }
+    ...Local @client
+  }
+  local { ...Local }
+}
+fragment Local on T { cart @client { id } }
+`
+	expected := `query Q{viewer{name}}`
+	expectTransform(t, query, transform.StripClientFields(), expected)
+}
+
+func TestRemoveFieldsWithDirective_RemovesEmptiedOperations(t *testing.T) {
+	query := `
+query Q { a @internal }
+query R { b }
+`
+	expected := `query R{b}`
+	expectTransform(t, query, transform.RemoveFieldsWithDirective("internal"), expected)
+}
+
+func TestRenameOperations_RenamesOperations(t *testing.T) {
+	query := `
+query Q { a }
+mutation M { b }
+{ c }
+fragment F on T { d }
+`
+	expected := `query Gateway_Q{a} ` +
+		`mutation M{b} ` +
+		`query Anonymous{c} ` +
+		`fragment F on T{d}`
+	expectTransform(t, query, transform.RenameOperations(func(name string) string {
+		switch name {
+		case "Q":
+			return "Gateway_Q"
+		case "":
+			return "Anonymous"
+		}
+		return name
+	}), expected)
+
+	_, err := transform.RenameOperations(func(name string) string {
+		return "not a name"
+	})(parse(t, query))
+	if err == nil || err.Error() != `transform: invalid operation name "not a name"` {
+		t.Fatalf("Expected an invalid operation name error, got: %v", err)
+	}
+}
+
+func TestChain_AppliesTransformsInOrder(t *testing.T) {
+	query := `
+query Q($id: ID, $local: Boolean) {
+  node(id: $id) { ...Node }
+  cart @client(always: $local) { id }
+}
+fragment Node on Node { id local @client }
+fragment Unused on T { id }
+`
+	expected := `query Q($id:ID){node(id:$id){...on Node{id __typename} __typename}}`
+	results, err := transform.Apply(parse(t, query),
+		transform.StripClientFields(),
+		transform.RemoveUnusedVariables(),
+		transform.InlineFragments(),
+		transform.AddTypename(),
+	)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	printed := printer.Format(results, printer.Options{Compact: true})
+	if printed != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, printed))
+	}
+
+	_, err = transform.Chain(transform.InlineFragments(), transform.AddTypename())(parse(t, `{ ...F }`))
+	if err == nil {
+		t.Fatalf("Expected Chain to stop at the first error")
+	}
+}
+
+func TestTransforms_DoNotAlterAST(t *testing.T) {
+	query := `
+query Q($a: Int, $b: Int) { f(a: $a) { ...F g @client } }
+fragment F on T { h { id } }
+fragment G on T { id }
+`
+	transforms := []transform.Transform{
+		transform.AddTypename(),
+		transform.InlineFragments(),
+		transform.RemoveUnusedFragments(),
+		transform.RemoveUnusedVariables(),
+		transform.StripClientFields(),
+		transform.RenameOperations(func(name string) string { return "R" }),
+	}
+	for _, tr := range transforms {
+		astDoc := parse(t, query)
+		astDocBefore := parse(t, query)
+		if _, err := tr(astDoc); err != nil {
+			t.Fatalf("Transform failed: %v", err)
+		}
+		if !reflect.DeepEqual(astDoc, astDocBefore) {
+			t.Fatalf("AST was altered by the transform")
+		}
+	}
+}
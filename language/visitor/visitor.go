@@ -31,6 +31,7 @@ var QueryDocumentKeys = KeyMap{
 		"Variable",
 		"Type",
 		"DefaultValue",
+		"Directives",
 	},
 	"Variable":     []string{"Name"},
 	"SelectionSet": []string{"Selections"},
@@ -38,6 +39,7 @@ var QueryDocumentKeys = KeyMap{
 		"Alias",
 		"Name",
 		"Arguments",
+		"Nullability",
 		"Directives",
 		"SelectionSet",
 	},
@@ -83,6 +85,10 @@ var QueryDocumentKeys = KeyMap{
 	"List":    []string{"Type"},
 	"NonNull": []string{"Type"},
 
+	"RequiredDesignator": []string{"Element"},
+	"OptionalDesignator": []string{"Element"},
+	"ListNullability":    []string{"Element"},
+
 	"SchemaDefinition": []string{
 		"Directives",
 		"OperationTypes",
@@ -137,6 +143,10 @@ var QueryDocumentKeys = KeyMap{
 	},
 
 	"TypeExtensionDefinition": []string{"Definition"},
+	"SchemaExtensionDefinition": []string{
+		"Directives",
+		"OperationTypes",
+	},
 
 	"DirectiveDefinition": []string{"Name", "Arguments", "Locations"},
 }
@@ -0,0 +1,293 @@
+package visitor
+
+import (
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/typeInfo"
+)
+
+// Action tells Walk how to continue after a Visitor callback.
+type Action int
+
+const (
+	// Continue walks on normally.
+	Continue Action = iota
+	// Skip does not walk the children of the node, nor calls its leave
+	// callbacks. It has no effect when leaving a node.
+	Skip
+	// Break stops the walk.
+	Break
+)
+
+// Cursor describes the node being visited and allows to edit it.
+// The slices returned by its methods are only valid during the callback.
+type Cursor struct {
+	visitor   *Visitor
+	node      ast.Node
+	key       string
+	index     int
+	path      []interface{}
+	ancestors []ast.Node
+	broken    bool
+}
+
+// Node returns the node being visited, or nil if it has been deleted.
+func (c *Cursor) Node() ast.Node {
+	return c.node
+}
+
+// Parent returns the node holding the visited node, or nil for the root.
+func (c *Cursor) Parent() ast.Node {
+	if len(c.ancestors) == 0 {
+		return nil
+	}
+	return c.ancestors[len(c.ancestors)-1]
+}
+
+// Key returns the name of the field of the parent holding the visited node,
+// such as "SelectionSet" or "Arguments".
+func (c *Cursor) Key() string {
+	return c.key
+}
+
+// Index returns the index of the visited node in the field of its parent,
+// or -1 if that field is not a list.
+func (c *Cursor) Index() int {
+	return c.index
+}
+
+// Ancestors returns the nodes from the root to the parent of the visited node.
+func (c *Cursor) Ancestors() []ast.Node {
+	return c.ancestors
+}
+
+// Path returns the keys and indexes leading from the root to the visited node.
+func (c *Cursor) Path() []interface{} {
+	return c.path
+}
+
+// Replace replaces the visited node in its parent. When called on enter, the
+// children of the new node are walked instead. It panics if node is nil or
+// of a type the parent field cannot hold.
+func (c *Cursor) Replace(node ast.Node) {
+	if node == nil {
+		panic("visitor: Replace called with a nil node, use Delete")
+	}
+	c.node = node
+}
+
+// Delete removes the visited node from its parent.
+func (c *Cursor) Delete() {
+	c.node = nil
+}
+
+// Walk traverses the AST from root depth-first, calling the callbacks of v on
+// enter and leave of each node. Edits made with the Cursor are applied in place
+// to the parents of the edited nodes; the possibly replaced root is returned.
+// Unlike Visit, Walk does not use reflection.
+func Walk(root ast.Node, v *Visitor) ast.Node {
+	if root == nil {
+		return nil
+	}
+	c := &Cursor{visitor: v}
+	return c.walk(root, "", -1)
+}
+
+func (c *Cursor) walk(node ast.Node, key string, index int) ast.Node {
+	depth := len(c.path)
+	if key != "" {
+		c.path = append(c.path, key)
+		if index >= 0 {
+			c.path = append(c.path, index)
+		}
+	}
+
+	c.node, c.key, c.index = node, key, index
+	action := c.visitor.enter(c)
+	node = c.node
+	if action == Break {
+		c.broken = true
+	}
+	if !c.broken && action != Skip && node != nil {
+		c.ancestors = append(c.ancestors, node)
+		c.children(node)
+		c.ancestors = c.ancestors[:len(c.ancestors)-1]
+		if !c.broken {
+			c.node, c.key, c.index = node, key, index
+			if c.visitor.leave(c) == Break {
+				c.broken = true
+			}
+			node = c.node
+		}
+	}
+	c.path = c.path[:depth]
+	return node
+}
+
+// InParallel creates a visitor which delegates to many visitors to run in
+// parallel. Each visitor is called for each node before moving on; a visitor
+// skipping or breaking does not affect the others.
+//
+// If a visitor replaces or deletes a node, the following visitors do not see
+// that node.
+func InParallel(visitors ...*Visitor) *Visitor {
+	// skipping holds, for each visitor, one more than the depth of the node
+	// it skipped, or 0.
+	skipping := make([]int, len(visitors))
+	broken := make([]bool, len(visitors))
+	running := len(visitors)
+	done := func(i int) {
+		broken[i] = true
+		running--
+	}
+	return &Visitor{
+		Enter: func(c *Cursor) Action {
+			node := c.node
+			for i, v := range visitors {
+				if skipping[i] != 0 && skipping[i] >= len(c.ancestors)+1 {
+					// the skipped node was deleted before being left
+					skipping[i] = 0
+				}
+				if broken[i] || skipping[i] != 0 {
+					continue
+				}
+				switch v.enter(c) {
+				case Skip:
+					skipping[i] = len(c.ancestors) + 1
+				case Break:
+					done(i)
+				}
+				if c.node != node {
+					break
+				}
+			}
+			if running == 0 {
+				return Break
+			}
+			return Continue
+		},
+		Leave: func(c *Cursor) Action {
+			node := c.node
+			for i, v := range visitors {
+				if broken[i] {
+					continue
+				}
+				if skipping[i] != 0 {
+					if skipping[i] == len(c.ancestors)+1 {
+						skipping[i] = 0
+					}
+					continue
+				}
+				if v.leave(c) == Break {
+					done(i)
+				}
+				if c.node != node {
+					break
+				}
+			}
+			if running == 0 {
+				return Break
+			}
+			return Continue
+		},
+	}
+}
+
+// WithTypeInfo creates a visitor which maintains the provided TypeInfo
+// along with calling v.
+func WithTypeInfo(info typeInfo.TypeInfoI, v *Visitor) *Visitor {
+	return &Visitor{
+		Enter: func(c *Cursor) Action {
+			node := c.node
+			info.Enter(node)
+			action := v.enter(c)
+			if c.node != node {
+				info.Leave(node)
+				if c.node != nil {
+					info.Enter(c.node)
+				}
+			}
+			if action == Skip && c.node != nil {
+				info.Leave(c.node)
+			}
+			return action
+		},
+		Leave: func(c *Cursor) Action {
+			node := c.node
+			action := v.leave(c)
+			info.Leave(node)
+			return action
+		},
+	}
+}
+
+// FromOptions adapts VisitorOptions written for Visit to Walk. The callbacks
+// receive the same VisitFuncParams as with Visit. An ActionUpdate with an
+// ast.Node replaces the visited node and one with nil deletes it; other
+// values cannot be stored in the AST and are ignored.
+func FromOptions(visitorOpts *VisitorOptions) *Visitor {
+	return &Visitor{
+		Enter: func(c *Cursor) Action {
+			return c.callVisitFn(GetVisitFn(visitorOpts, c.node.GetKind(), false), false)
+		},
+		Leave: func(c *Cursor) Action {
+			return c.callVisitFn(GetVisitFn(visitorOpts, c.node.GetKind(), true), true)
+		},
+	}
+}
+
+func (c *Cursor) callVisitFn(fn VisitFunc, isLeaving bool) Action {
+	if fn == nil {
+		return Continue
+	}
+	action, result := fn(c.visitFuncParams(isLeaving))
+	switch action {
+	case ActionBreak:
+		return Break
+	case ActionSkip:
+		return Skip
+	case ActionUpdate:
+		if result == nil {
+			c.Delete()
+		} else if node, ok := result.(ast.Node); ok {
+			c.Replace(node)
+		}
+	}
+	return Continue
+}
+
+// visitFuncParams builds the parameters Visit would give: the parent and
+// ancestors of the items of a list include a nil entry standing for the list,
+// and the path of a node being left does not include its last element.
+func (c *Cursor) visitFuncParams(isLeaving bool) VisitFuncParams {
+	p := VisitFuncParams{
+		Node: c.node,
+		Path: c.path,
+	}
+	if isLeaving && len(c.path) > 0 {
+		p.Path = c.path[:len(c.path)-1]
+	}
+	if len(c.ancestors) == 0 {
+		p.Ancestors = []ast.Node{}
+		return p
+	}
+	p.Key = c.key
+	if c.index >= 0 {
+		p.Key = c.index
+	}
+	stack := []ast.Node{nil, c.ancestors[0]}
+	i := 0 // position in path of the key of node
+	for _, node := range c.ancestors[1:] {
+		if _, ok := c.path[i+1].(int); ok {
+			stack = append(stack, nil)
+			i++
+		}
+		i++
+		stack = append(stack, node)
+	}
+	if c.index >= 0 {
+		stack = append(stack, nil)
+	}
+	p.Parent = stack[len(stack)-1]
+	p.Ancestors = stack[:len(stack)-1]
+	return p
+}
@@ -0,0 +1,1363 @@
+package visitor
+
+import (
+	"github.com/dagger/graphql/language/ast"
+)
+
+// Visitor holds the callbacks called by Walk. Enter and Leave are called for
+// every node, before the callbacks specific to the kind of the node. A kind
+// callback is only called when the generic one returned Continue.
+type Visitor struct {
+	Enter func(c *Cursor) Action
+	Leave func(c *Cursor) Action
+
+	EnterName                      func(node *ast.Name, c *Cursor) Action
+	LeaveName                      func(node *ast.Name, c *Cursor) Action
+	EnterDocument                  func(node *ast.Document, c *Cursor) Action
+	LeaveDocument                  func(node *ast.Document, c *Cursor) Action
+	EnterOperationDefinition       func(node *ast.OperationDefinition, c *Cursor) Action
+	LeaveOperationDefinition       func(node *ast.OperationDefinition, c *Cursor) Action
+	EnterVariableDefinition        func(node *ast.VariableDefinition, c *Cursor) Action
+	LeaveVariableDefinition        func(node *ast.VariableDefinition, c *Cursor) Action
+	EnterVariable                  func(node *ast.Variable, c *Cursor) Action
+	LeaveVariable                  func(node *ast.Variable, c *Cursor) Action
+	EnterSelectionSet              func(node *ast.SelectionSet, c *Cursor) Action
+	LeaveSelectionSet              func(node *ast.SelectionSet, c *Cursor) Action
+	EnterField                     func(node *ast.Field, c *Cursor) Action
+	LeaveField                     func(node *ast.Field, c *Cursor) Action
+	EnterArgument                  func(node *ast.Argument, c *Cursor) Action
+	LeaveArgument                  func(node *ast.Argument, c *Cursor) Action
+	EnterFragmentSpread            func(node *ast.FragmentSpread, c *Cursor) Action
+	LeaveFragmentSpread            func(node *ast.FragmentSpread, c *Cursor) Action
+	EnterInlineFragment            func(node *ast.InlineFragment, c *Cursor) Action
+	LeaveInlineFragment            func(node *ast.InlineFragment, c *Cursor) Action
+	EnterFragmentDefinition        func(node *ast.FragmentDefinition, c *Cursor) Action
+	LeaveFragmentDefinition        func(node *ast.FragmentDefinition, c *Cursor) Action
+	EnterIntValue                  func(node *ast.IntValue, c *Cursor) Action
+	LeaveIntValue                  func(node *ast.IntValue, c *Cursor) Action
+	EnterFloatValue                func(node *ast.FloatValue, c *Cursor) Action
+	LeaveFloatValue                func(node *ast.FloatValue, c *Cursor) Action
+	EnterStringValue               func(node *ast.StringValue, c *Cursor) Action
+	LeaveStringValue               func(node *ast.StringValue, c *Cursor) Action
+	EnterBooleanValue              func(node *ast.BooleanValue, c *Cursor) Action
+	LeaveBooleanValue              func(node *ast.BooleanValue, c *Cursor) Action
+	EnterEnumValue                 func(node *ast.EnumValue, c *Cursor) Action
+	LeaveEnumValue                 func(node *ast.EnumValue, c *Cursor) Action
+	EnterListValue                 func(node *ast.ListValue, c *Cursor) Action
+	LeaveListValue                 func(node *ast.ListValue, c *Cursor) Action
+	EnterObjectValue               func(node *ast.ObjectValue, c *Cursor) Action
+	LeaveObjectValue               func(node *ast.ObjectValue, c *Cursor) Action
+	EnterObjectField               func(node *ast.ObjectField, c *Cursor) Action
+	LeaveObjectField               func(node *ast.ObjectField, c *Cursor) Action
+	EnterDirective                 func(node *ast.Directive, c *Cursor) Action
+	LeaveDirective                 func(node *ast.Directive, c *Cursor) Action
+	EnterNamed                     func(node *ast.Named, c *Cursor) Action
+	LeaveNamed                     func(node *ast.Named, c *Cursor) Action
+	EnterList                      func(node *ast.List, c *Cursor) Action
+	LeaveList                      func(node *ast.List, c *Cursor) Action
+	EnterNonNull                   func(node *ast.NonNull, c *Cursor) Action
+	LeaveNonNull                   func(node *ast.NonNull, c *Cursor) Action
+	EnterRequiredDesignator        func(node *ast.RequiredDesignator, c *Cursor) Action
+	LeaveRequiredDesignator        func(node *ast.RequiredDesignator, c *Cursor) Action
+	EnterOptionalDesignator        func(node *ast.OptionalDesignator, c *Cursor) Action
+	LeaveOptionalDesignator        func(node *ast.OptionalDesignator, c *Cursor) Action
+	EnterListNullability           func(node *ast.ListNullability, c *Cursor) Action
+	LeaveListNullability           func(node *ast.ListNullability, c *Cursor) Action
+	EnterSchemaDefinition          func(node *ast.SchemaDefinition, c *Cursor) Action
+	LeaveSchemaDefinition          func(node *ast.SchemaDefinition, c *Cursor) Action
+	EnterOperationTypeDefinition   func(node *ast.OperationTypeDefinition, c *Cursor) Action
+	LeaveOperationTypeDefinition   func(node *ast.OperationTypeDefinition, c *Cursor) Action
+	EnterScalarDefinition          func(node *ast.ScalarDefinition, c *Cursor) Action
+	LeaveScalarDefinition          func(node *ast.ScalarDefinition, c *Cursor) Action
+	EnterObjectDefinition          func(node *ast.ObjectDefinition, c *Cursor) Action
+	LeaveObjectDefinition          func(node *ast.ObjectDefinition, c *Cursor) Action
+	EnterFieldDefinition           func(node *ast.FieldDefinition, c *Cursor) Action
+	LeaveFieldDefinition           func(node *ast.FieldDefinition, c *Cursor) Action
+	EnterInputValueDefinition      func(node *ast.InputValueDefinition, c *Cursor) Action
+	LeaveInputValueDefinition      func(node *ast.InputValueDefinition, c *Cursor) Action
+	EnterInterfaceDefinition       func(node *ast.InterfaceDefinition, c *Cursor) Action
+	LeaveInterfaceDefinition       func(node *ast.InterfaceDefinition, c *Cursor) Action
+	EnterUnionDefinition           func(node *ast.UnionDefinition, c *Cursor) Action
+	LeaveUnionDefinition           func(node *ast.UnionDefinition, c *Cursor) Action
+	EnterEnumDefinition            func(node *ast.EnumDefinition, c *Cursor) Action
+	LeaveEnumDefinition            func(node *ast.EnumDefinition, c *Cursor) Action
+	EnterEnumValueDefinition       func(node *ast.EnumValueDefinition, c *Cursor) Action
+	LeaveEnumValueDefinition       func(node *ast.EnumValueDefinition, c *Cursor) Action
+	EnterInputObjectDefinition     func(node *ast.InputObjectDefinition, c *Cursor) Action
+	LeaveInputObjectDefinition     func(node *ast.InputObjectDefinition, c *Cursor) Action
+	EnterTypeExtensionDefinition   func(node *ast.TypeExtensionDefinition, c *Cursor) Action
+	LeaveTypeExtensionDefinition   func(node *ast.TypeExtensionDefinition, c *Cursor) Action
+	EnterSchemaExtensionDefinition func(node *ast.SchemaExtensionDefinition, c *Cursor) Action
+	LeaveSchemaExtensionDefinition func(node *ast.SchemaExtensionDefinition, c *Cursor) Action
+	EnterDirectiveDefinition       func(node *ast.DirectiveDefinition, c *Cursor) Action
+	LeaveDirectiveDefinition       func(node *ast.DirectiveDefinition, c *Cursor) Action
+}
+
+func (v *Visitor) enter(c *Cursor) Action {
+	if v.Enter != nil {
+		if action := v.Enter(c); action != Continue || c.node == nil {
+			return action
+		}
+	}
+	switch node := c.node.(type) {
+	case *ast.Name:
+		if v.EnterName != nil {
+			return v.EnterName(node, c)
+		}
+	case *ast.Document:
+		if v.EnterDocument != nil {
+			return v.EnterDocument(node, c)
+		}
+	case *ast.OperationDefinition:
+		if v.EnterOperationDefinition != nil {
+			return v.EnterOperationDefinition(node, c)
+		}
+	case *ast.VariableDefinition:
+		if v.EnterVariableDefinition != nil {
+			return v.EnterVariableDefinition(node, c)
+		}
+	case *ast.Variable:
+		if v.EnterVariable != nil {
+			return v.EnterVariable(node, c)
+		}
+	case *ast.SelectionSet:
+		if v.EnterSelectionSet != nil {
+			return v.EnterSelectionSet(node, c)
+		}
+	case *ast.Field:
+		if v.EnterField != nil {
+			return v.EnterField(node, c)
+		}
+	case *ast.Argument:
+		if v.EnterArgument != nil {
+			return v.EnterArgument(node, c)
+		}
+	case *ast.FragmentSpread:
+		if v.EnterFragmentSpread != nil {
+			return v.EnterFragmentSpread(node, c)
+		}
+	case *ast.InlineFragment:
+		if v.EnterInlineFragment != nil {
+			return v.EnterInlineFragment(node, c)
+		}
+	case *ast.FragmentDefinition:
+		if v.EnterFragmentDefinition != nil {
+			return v.EnterFragmentDefinition(node, c)
+		}
+	case *ast.IntValue:
+		if v.EnterIntValue != nil {
+			return v.EnterIntValue(node, c)
+		}
+	case *ast.FloatValue:
+		if v.EnterFloatValue != nil {
+			return v.EnterFloatValue(node, c)
+		}
+	case *ast.StringValue:
+		if v.EnterStringValue != nil {
+			return v.EnterStringValue(node, c)
+		}
+	case *ast.BooleanValue:
+		if v.EnterBooleanValue != nil {
+			return v.EnterBooleanValue(node, c)
+		}
+	case *ast.EnumValue:
+		if v.EnterEnumValue != nil {
+			return v.EnterEnumValue(node, c)
+		}
+	case *ast.ListValue:
+		if v.EnterListValue != nil {
+			return v.EnterListValue(node, c)
+		}
+	case *ast.ObjectValue:
+		if v.EnterObjectValue != nil {
+			return v.EnterObjectValue(node, c)
+		}
+	case *ast.ObjectField:
+		if v.EnterObjectField != nil {
+			return v.EnterObjectField(node, c)
+		}
+	case *ast.Directive:
+		if v.EnterDirective != nil {
+			return v.EnterDirective(node, c)
+		}
+	case *ast.Named:
+		if v.EnterNamed != nil {
+			return v.EnterNamed(node, c)
+		}
+	case *ast.List:
+		if v.EnterList != nil {
+			return v.EnterList(node, c)
+		}
+	case *ast.NonNull:
+		if v.EnterNonNull != nil {
+			return v.EnterNonNull(node, c)
+		}
+	case *ast.RequiredDesignator:
+		if v.EnterRequiredDesignator != nil {
+			return v.EnterRequiredDesignator(node, c)
+		}
+	case *ast.OptionalDesignator:
+		if v.EnterOptionalDesignator != nil {
+			return v.EnterOptionalDesignator(node, c)
+		}
+	case *ast.ListNullability:
+		if v.EnterListNullability != nil {
+			return v.EnterListNullability(node, c)
+		}
+	case *ast.SchemaDefinition:
+		if v.EnterSchemaDefinition != nil {
+			return v.EnterSchemaDefinition(node, c)
+		}
+	case *ast.OperationTypeDefinition:
+		if v.EnterOperationTypeDefinition != nil {
+			return v.EnterOperationTypeDefinition(node, c)
+		}
+	case *ast.ScalarDefinition:
+		if v.EnterScalarDefinition != nil {
+			return v.EnterScalarDefinition(node, c)
+		}
+	case *ast.ObjectDefinition:
+		if v.EnterObjectDefinition != nil {
+			return v.EnterObjectDefinition(node, c)
+		}
+	case *ast.FieldDefinition:
+		if v.EnterFieldDefinition != nil {
+			return v.EnterFieldDefinition(node, c)
+		}
+	case *ast.InputValueDefinition:
+		if v.EnterInputValueDefinition != nil {
+			return v.EnterInputValueDefinition(node, c)
+		}
+	case *ast.InterfaceDefinition:
+		if v.EnterInterfaceDefinition != nil {
+			return v.EnterInterfaceDefinition(node, c)
+		}
+	case *ast.UnionDefinition:
+		if v.EnterUnionDefinition != nil {
+			return v.EnterUnionDefinition(node, c)
+		}
+	case *ast.EnumDefinition:
+		if v.EnterEnumDefinition != nil {
+			return v.EnterEnumDefinition(node, c)
+		}
+	case *ast.EnumValueDefinition:
+		if v.EnterEnumValueDefinition != nil {
+			return v.EnterEnumValueDefinition(node, c)
+		}
+	case *ast.InputObjectDefinition:
+		if v.EnterInputObjectDefinition != nil {
+			return v.EnterInputObjectDefinition(node, c)
+		}
+	case *ast.TypeExtensionDefinition:
+		if v.EnterTypeExtensionDefinition != nil {
+			return v.EnterTypeExtensionDefinition(node, c)
+		}
+	case *ast.SchemaExtensionDefinition:
+		if v.EnterSchemaExtensionDefinition != nil {
+			return v.EnterSchemaExtensionDefinition(node, c)
+		}
+	case *ast.DirectiveDefinition:
+		if v.EnterDirectiveDefinition != nil {
+			return v.EnterDirectiveDefinition(node, c)
+		}
+	}
+	return Continue
+}
+
+func (v *Visitor) leave(c *Cursor) Action {
+	if v.Leave != nil {
+		if action := v.Leave(c); action != Continue || c.node == nil {
+			return action
+		}
+	}
+	switch node := c.node.(type) {
+	case *ast.Name:
+		if v.LeaveName != nil {
+			return v.LeaveName(node, c)
+		}
+	case *ast.Document:
+		if v.LeaveDocument != nil {
+			return v.LeaveDocument(node, c)
+		}
+	case *ast.OperationDefinition:
+		if v.LeaveOperationDefinition != nil {
+			return v.LeaveOperationDefinition(node, c)
+		}
+	case *ast.VariableDefinition:
+		if v.LeaveVariableDefinition != nil {
+			return v.LeaveVariableDefinition(node, c)
+		}
+	case *ast.Variable:
+		if v.LeaveVariable != nil {
+			return v.LeaveVariable(node, c)
+		}
+	case *ast.SelectionSet:
+		if v.LeaveSelectionSet != nil {
+			return v.LeaveSelectionSet(node, c)
+		}
+	case *ast.Field:
+		if v.LeaveField != nil {
+			return v.LeaveField(node, c)
+		}
+	case *ast.Argument:
+		if v.LeaveArgument != nil {
+			return v.LeaveArgument(node, c)
+		}
+	case *ast.FragmentSpread:
+		if v.LeaveFragmentSpread != nil {
+			return v.LeaveFragmentSpread(node, c)
+		}
+	case *ast.InlineFragment:
+		if v.LeaveInlineFragment != nil {
+			return v.LeaveInlineFragment(node, c)
+		}
+	case *ast.FragmentDefinition:
+		if v.LeaveFragmentDefinition != nil {
+			return v.LeaveFragmentDefinition(node, c)
+		}
+	case *ast.IntValue:
+		if v.LeaveIntValue != nil {
+			return v.LeaveIntValue(node, c)
+		}
+	case *ast.FloatValue:
+		if v.LeaveFloatValue != nil {
+			return v.LeaveFloatValue(node, c)
+		}
+	case *ast.StringValue:
+		if v.LeaveStringValue != nil {
+			return v.LeaveStringValue(node, c)
+		}
+	case *ast.BooleanValue:
+		if v.LeaveBooleanValue != nil {
+			return v.LeaveBooleanValue(node, c)
+		}
+	case *ast.EnumValue:
+		if v.LeaveEnumValue != nil {
+			return v.LeaveEnumValue(node, c)
+		}
+	case *ast.ListValue:
+		if v.LeaveListValue != nil {
+			return v.LeaveListValue(node, c)
+		}
+	case *ast.ObjectValue:
+		if v.LeaveObjectValue != nil {
+			return v.LeaveObjectValue(node, c)
+		}
+	case *ast.ObjectField:
+		if v.LeaveObjectField != nil {
+			return v.LeaveObjectField(node, c)
+		}
+	case *ast.Directive:
+		if v.LeaveDirective != nil {
+			return v.LeaveDirective(node, c)
+		}
+	case *ast.Named:
+		if v.LeaveNamed != nil {
+			return v.LeaveNamed(node, c)
+		}
+	case *ast.List:
+		if v.LeaveList != nil {
+			return v.LeaveList(node, c)
+		}
+	case *ast.NonNull:
+		if v.LeaveNonNull != nil {
+			return v.LeaveNonNull(node, c)
+		}
+	case *ast.RequiredDesignator:
+		if v.LeaveRequiredDesignator != nil {
+			return v.LeaveRequiredDesignator(node, c)
+		}
+	case *ast.OptionalDesignator:
+		if v.LeaveOptionalDesignator != nil {
+			return v.LeaveOptionalDesignator(node, c)
+		}
+	case *ast.ListNullability:
+		if v.LeaveListNullability != nil {
+			return v.LeaveListNullability(node, c)
+		}
+	case *ast.SchemaDefinition:
+		if v.LeaveSchemaDefinition != nil {
+			return v.LeaveSchemaDefinition(node, c)
+		}
+	case *ast.OperationTypeDefinition:
+		if v.LeaveOperationTypeDefinition != nil {
+			return v.LeaveOperationTypeDefinition(node, c)
+		}
+	case *ast.ScalarDefinition:
+		if v.LeaveScalarDefinition != nil {
+			return v.LeaveScalarDefinition(node, c)
+		}
+	case *ast.ObjectDefinition:
+		if v.LeaveObjectDefinition != nil {
+			return v.LeaveObjectDefinition(node, c)
+		}
+	case *ast.FieldDefinition:
+		if v.LeaveFieldDefinition != nil {
+			return v.LeaveFieldDefinition(node, c)
+		}
+	case *ast.InputValueDefinition:
+		if v.LeaveInputValueDefinition != nil {
+			return v.LeaveInputValueDefinition(node, c)
+		}
+	case *ast.InterfaceDefinition:
+		if v.LeaveInterfaceDefinition != nil {
+			return v.LeaveInterfaceDefinition(node, c)
+		}
+	case *ast.UnionDefinition:
+		if v.LeaveUnionDefinition != nil {
+			return v.LeaveUnionDefinition(node, c)
+		}
+	case *ast.EnumDefinition:
+		if v.LeaveEnumDefinition != nil {
+			return v.LeaveEnumDefinition(node, c)
+		}
+	case *ast.EnumValueDefinition:
+		if v.LeaveEnumValueDefinition != nil {
+			return v.LeaveEnumValueDefinition(node, c)
+		}
+	case *ast.InputObjectDefinition:
+		if v.LeaveInputObjectDefinition != nil {
+			return v.LeaveInputObjectDefinition(node, c)
+		}
+	case *ast.TypeExtensionDefinition:
+		if v.LeaveTypeExtensionDefinition != nil {
+			return v.LeaveTypeExtensionDefinition(node, c)
+		}
+	case *ast.SchemaExtensionDefinition:
+		if v.LeaveSchemaExtensionDefinition != nil {
+			return v.LeaveSchemaExtensionDefinition(node, c)
+		}
+	case *ast.DirectiveDefinition:
+		if v.LeaveDirectiveDefinition != nil {
+			return v.LeaveDirectiveDefinition(node, c)
+		}
+	}
+	return Continue
+}
+
+// children walks the children of node, in the order of QueryDocumentKeys.
+func (c *Cursor) children(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Document:
+		if list, ok := c.nodes(node.Definitions, "Definitions"); ok {
+			node.Definitions = list
+		}
+
+	case *ast.OperationDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.variableDefinitions(node.VariableDefinitions, "VariableDefinitions"); ok {
+			node.VariableDefinitions = list
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if node.SelectionSet != nil {
+			if r, ok := c.field(node.SelectionSet, "SelectionSet"); ok {
+				node.SelectionSet = nil
+				if r != nil {
+					node.SelectionSet = r.(*ast.SelectionSet)
+				}
+			}
+		}
+
+	case *ast.VariableDefinition:
+		if node.Variable != nil {
+			if r, ok := c.field(node.Variable, "Variable"); ok {
+				node.Variable = nil
+				if r != nil {
+					node.Variable = r.(*ast.Variable)
+				}
+			}
+		}
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(ast.Type)
+				}
+			}
+		}
+		if node.DefaultValue != nil {
+			if r, ok := c.field(node.DefaultValue, "DefaultValue"); ok {
+				node.DefaultValue = nil
+				if r != nil {
+					node.DefaultValue = r.(ast.Value)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.Variable:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+
+	case *ast.SelectionSet:
+		if list, ok := c.selections(node.Selections, "Selections"); ok {
+			node.Selections = list
+		}
+
+	case *ast.Field:
+		if node.Alias != nil {
+			if r, ok := c.field(node.Alias, "Alias"); ok {
+				node.Alias = nil
+				if r != nil {
+					node.Alias = r.(*ast.Name)
+				}
+			}
+		}
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.arguments(node.Arguments, "Arguments"); ok {
+			node.Arguments = list
+		}
+		if node.Nullability != nil {
+			if r, ok := c.field(node.Nullability, "Nullability"); ok {
+				node.Nullability = nil
+				if r != nil {
+					node.Nullability = r.(ast.Nullability)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if node.SelectionSet != nil {
+			if r, ok := c.field(node.SelectionSet, "SelectionSet"); ok {
+				node.SelectionSet = nil
+				if r != nil {
+					node.SelectionSet = r.(*ast.SelectionSet)
+				}
+			}
+		}
+
+	case *ast.Argument:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if node.Value != nil {
+			if r, ok := c.field(node.Value, "Value"); ok {
+				node.Value = nil
+				if r != nil {
+					node.Value = r.(ast.Value)
+				}
+			}
+		}
+
+	case *ast.FragmentSpread:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.InlineFragment:
+		if node.TypeCondition != nil {
+			if r, ok := c.field(node.TypeCondition, "TypeCondition"); ok {
+				node.TypeCondition = nil
+				if r != nil {
+					node.TypeCondition = r.(*ast.Named)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if node.SelectionSet != nil {
+			if r, ok := c.field(node.SelectionSet, "SelectionSet"); ok {
+				node.SelectionSet = nil
+				if r != nil {
+					node.SelectionSet = r.(*ast.SelectionSet)
+				}
+			}
+		}
+
+	case *ast.FragmentDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if node.TypeCondition != nil {
+			if r, ok := c.field(node.TypeCondition, "TypeCondition"); ok {
+				node.TypeCondition = nil
+				if r != nil {
+					node.TypeCondition = r.(*ast.Named)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if node.SelectionSet != nil {
+			if r, ok := c.field(node.SelectionSet, "SelectionSet"); ok {
+				node.SelectionSet = nil
+				if r != nil {
+					node.SelectionSet = r.(*ast.SelectionSet)
+				}
+			}
+		}
+
+	case *ast.ListValue:
+		if list, ok := c.values(node.Values, "Values"); ok {
+			node.Values = list
+		}
+
+	case *ast.ObjectValue:
+		if list, ok := c.objectFields(node.Fields, "Fields"); ok {
+			node.Fields = list
+		}
+
+	case *ast.ObjectField:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if node.Value != nil {
+			if r, ok := c.field(node.Value, "Value"); ok {
+				node.Value = nil
+				if r != nil {
+					node.Value = r.(ast.Value)
+				}
+			}
+		}
+
+	case *ast.Directive:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.arguments(node.Arguments, "Arguments"); ok {
+			node.Arguments = list
+		}
+
+	case *ast.Named:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+
+	case *ast.List:
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(ast.Type)
+				}
+			}
+		}
+
+	case *ast.NonNull:
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(ast.Type)
+				}
+			}
+		}
+
+	case *ast.RequiredDesignator:
+		if node.Element != nil {
+			if r, ok := c.field(node.Element, "Element"); ok {
+				node.Element = nil
+				if r != nil {
+					node.Element = r.(*ast.ListNullability)
+				}
+			}
+		}
+
+	case *ast.OptionalDesignator:
+		if node.Element != nil {
+			if r, ok := c.field(node.Element, "Element"); ok {
+				node.Element = nil
+				if r != nil {
+					node.Element = r.(*ast.ListNullability)
+				}
+			}
+		}
+
+	case *ast.ListNullability:
+		if node.Element != nil {
+			if r, ok := c.field(node.Element, "Element"); ok {
+				node.Element = nil
+				if r != nil {
+					node.Element = r.(ast.Nullability)
+				}
+			}
+		}
+
+	case *ast.SchemaDefinition:
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.operationTypeDefinitions(node.OperationTypes, "OperationTypes"); ok {
+			node.OperationTypes = list
+		}
+
+	case *ast.OperationTypeDefinition:
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(*ast.Named)
+				}
+			}
+		}
+
+	case *ast.ScalarDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.ObjectDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.nameds(node.Interfaces, "Interfaces"); ok {
+			node.Interfaces = list
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.fieldDefinitions(node.Fields, "Fields"); ok {
+			node.Fields = list
+		}
+
+	case *ast.FieldDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.inputValueDefinitions(node.Arguments, "Arguments"); ok {
+			node.Arguments = list
+		}
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(ast.Type)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.InputValueDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if node.Type != nil {
+			if r, ok := c.field(node.Type, "Type"); ok {
+				node.Type = nil
+				if r != nil {
+					node.Type = r.(ast.Type)
+				}
+			}
+		}
+		if node.DefaultValue != nil {
+			if r, ok := c.field(node.DefaultValue, "DefaultValue"); ok {
+				node.DefaultValue = nil
+				if r != nil {
+					node.DefaultValue = r.(ast.Value)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.InterfaceDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.fieldDefinitions(node.Fields, "Fields"); ok {
+			node.Fields = list
+		}
+
+	case *ast.UnionDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.nameds(node.Types, "Types"); ok {
+			node.Types = list
+		}
+
+	case *ast.EnumDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.enumValueDefinitions(node.Values, "Values"); ok {
+			node.Values = list
+		}
+
+	case *ast.EnumValueDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+
+	case *ast.InputObjectDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.inputValueDefinitions(node.Fields, "Fields"); ok {
+			node.Fields = list
+		}
+
+	case *ast.TypeExtensionDefinition:
+		if node.Definition != nil {
+			if r, ok := c.field(node.Definition, "Definition"); ok {
+				node.Definition = nil
+				if r != nil {
+					node.Definition = r.(*ast.ObjectDefinition)
+				}
+			}
+		}
+
+	case *ast.SchemaExtensionDefinition:
+		if list, ok := c.directives(node.Directives, "Directives"); ok {
+			node.Directives = list
+		}
+		if list, ok := c.operationTypeDefinitions(node.OperationTypes, "OperationTypes"); ok {
+			node.OperationTypes = list
+		}
+
+	case *ast.DirectiveDefinition:
+		if node.Name != nil {
+			if r, ok := c.field(node.Name, "Name"); ok {
+				node.Name = nil
+				if r != nil {
+					node.Name = r.(*ast.Name)
+				}
+			}
+		}
+		if list, ok := c.inputValueDefinitions(node.Arguments, "Arguments"); ok {
+			node.Arguments = list
+		}
+		if list, ok := c.names(node.Locations, "Locations"); ok {
+			node.Locations = list
+		}
+	}
+}
+
+// field walks a child node, and reports whether it was replaced or deleted.
+func (c *Cursor) field(node ast.Node, key string) (ast.Node, bool) {
+	if c.broken {
+		return node, false
+	}
+	r := c.walk(node, key, -1)
+	return r, r != node
+}
+
+func (c *Cursor) nodes(list []ast.Node, key string) ([]ast.Node, bool) {
+	var out []ast.Node
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != item && out == nil {
+			out = append(make([]ast.Node, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r)
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) variableDefinitions(list []*ast.VariableDefinition, key string) ([]*ast.VariableDefinition, bool) {
+	var out []*ast.VariableDefinition
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.VariableDefinition, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.VariableDefinition))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) directives(list []*ast.Directive, key string) ([]*ast.Directive, bool) {
+	var out []*ast.Directive
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.Directive, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.Directive))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) selections(list []ast.Selection, key string) ([]ast.Selection, bool) {
+	var out []ast.Selection
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		node, _ := item.(ast.Node)
+		if node == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(node, key, i)
+		if r != node && out == nil {
+			out = append(make([]ast.Selection, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(ast.Selection))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) arguments(list []*ast.Argument, key string) ([]*ast.Argument, bool) {
+	var out []*ast.Argument
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.Argument, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.Argument))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) values(list []ast.Value, key string) ([]ast.Value, bool) {
+	var out []ast.Value
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != item && out == nil {
+			out = append(make([]ast.Value, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(ast.Value))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) objectFields(list []*ast.ObjectField, key string) ([]*ast.ObjectField, bool) {
+	var out []*ast.ObjectField
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.ObjectField, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.ObjectField))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) operationTypeDefinitions(list []*ast.OperationTypeDefinition, key string) ([]*ast.OperationTypeDefinition, bool) {
+	var out []*ast.OperationTypeDefinition
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.OperationTypeDefinition, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.OperationTypeDefinition))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) nameds(list []*ast.Named, key string) ([]*ast.Named, bool) {
+	var out []*ast.Named
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.Named, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.Named))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) fieldDefinitions(list []*ast.FieldDefinition, key string) ([]*ast.FieldDefinition, bool) {
+	var out []*ast.FieldDefinition
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.FieldDefinition, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.FieldDefinition))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) inputValueDefinitions(list []*ast.InputValueDefinition, key string) ([]*ast.InputValueDefinition, bool) {
+	var out []*ast.InputValueDefinition
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.InputValueDefinition, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.InputValueDefinition))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) enumValueDefinitions(list []*ast.EnumValueDefinition, key string) ([]*ast.EnumValueDefinition, bool) {
+	var out []*ast.EnumValueDefinition
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.EnumValueDefinition, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.EnumValueDefinition))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+func (c *Cursor) names(list []*ast.Name, key string) ([]*ast.Name, bool) {
+	var out []*ast.Name
+	for i, item := range list {
+		if c.broken {
+			break
+		}
+		if item == nil {
+			if out != nil {
+				out = append(out, item)
+			}
+			continue
+		}
+		r := c.walk(item, key, i)
+		if r != ast.Node(item) && out == nil {
+			out = append(make([]*ast.Name, 0, len(list)), list[:i]...)
+		} else if out == nil {
+			continue
+		}
+		if r != nil {
+			out = append(out, r.(*ast.Name))
+		}
+		if c.broken {
+			out = append(out, list[i+1:]...)
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
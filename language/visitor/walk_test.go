@@ -0,0 +1,256 @@
+package visitor_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/language/visitor"
+	"github.com/dagger/graphql/testutil"
+)
+
+func walkLog(visited *[]interface{}, prefix ...interface{}) (enter, leave func(c *visitor.Cursor) visitor.Action) {
+	log := func(event string) func(c *visitor.Cursor) visitor.Action {
+		return func(c *visitor.Cursor) visitor.Action {
+			entry := append(append([]interface{}{}, prefix...), event, c.Node().GetKind(), nil)
+			if node, ok := c.Node().(*ast.Name); ok {
+				entry[len(entry)-1] = node.Value
+			}
+			*visited = append(*visited, entry)
+			return visitor.Continue
+		}
+	}
+	return log("enter"), log("leave")
+}
+
+func TestWalk_AllowsSkippingASubTree(t *testing.T) {
+	astDoc := parse(t, `{ a, b { x }, c }`)
+
+	visited := []interface{}{}
+	expectedVisited := []interface{}{
+		[]interface{}{"enter", "Document", nil},
+		[]interface{}{"enter", "OperationDefinition", nil},
+		[]interface{}{"enter", "SelectionSet", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Name", "a"},
+		[]interface{}{"leave", "Name", "a"},
+		[]interface{}{"leave", "Field", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Name", "c"},
+		[]interface{}{"leave", "Name", "c"},
+		[]interface{}{"leave", "Field", nil},
+		[]interface{}{"leave", "SelectionSet", nil},
+		[]interface{}{"leave", "OperationDefinition", nil},
+		[]interface{}{"leave", "Document", nil},
+	}
+
+	enter, leave := walkLog(&visited)
+	visitor.Walk(astDoc, &visitor.Visitor{
+		Enter: enter,
+		Leave: leave,
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			if node.Name.Value == "b" {
+				return visitor.Skip
+			}
+			return visitor.Continue
+		},
+	})
+
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedVisited, visited))
+	}
+}
+
+func TestWalk_AllowsEarlyExitWhileVisiting(t *testing.T) {
+	astDoc := parse(t, `{ a, b { x }, c }`)
+
+	visited := []interface{}{}
+	expectedVisited := []interface{}{
+		[]interface{}{"enter", "Document", nil},
+		[]interface{}{"enter", "OperationDefinition", nil},
+		[]interface{}{"enter", "SelectionSet", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Name", "a"},
+		[]interface{}{"leave", "Name", "a"},
+		[]interface{}{"leave", "Field", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Name", "b"},
+		[]interface{}{"leave", "Name", "b"},
+		[]interface{}{"enter", "SelectionSet", nil},
+		[]interface{}{"enter", "Field", nil},
+		[]interface{}{"enter", "Name", "x"},
+	}
+
+	enter, leave := walkLog(&visited)
+	visitor.Walk(astDoc, &visitor.Visitor{
+		Enter: enter,
+		Leave: leave,
+		EnterName: func(node *ast.Name, c *visitor.Cursor) visitor.Action {
+			if node.Value == "x" {
+				return visitor.Break
+			}
+			return visitor.Continue
+		},
+	})
+
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedVisited, visited))
+	}
+}
+
+func TestWalk_AllowsReplacingAndDeletingNodes(t *testing.T) {
+	astDoc := parse(t, `{ a, b { x }, c(arg: 1) @skip(if: true), d }`)
+
+	visitedNames := []string{}
+	root := visitor.Walk(astDoc, &visitor.Visitor{
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			switch node.Name.Value {
+			case "a":
+				c.Delete()
+			case "b":
+				c.Replace(ast.NewField(&ast.Field{
+					Name: ast.NewName(&ast.Name{Value: "e"}),
+					SelectionSet: ast.NewSelectionSet(&ast.SelectionSet{
+						Selections: []ast.Selection{
+							ast.NewField(&ast.Field{Name: ast.NewName(&ast.Name{Value: "y"})}),
+						},
+					}),
+				}))
+			}
+			return visitor.Continue
+		},
+		EnterName: func(node *ast.Name, c *visitor.Cursor) visitor.Action {
+			visitedNames = append(visitedNames, node.Value)
+			return visitor.Continue
+		},
+		LeaveDirective: func(node *ast.Directive, c *visitor.Cursor) visitor.Action {
+			c.Delete()
+			return visitor.Continue
+		},
+		LeaveIntValue: func(node *ast.IntValue, c *visitor.Cursor) visitor.Action {
+			c.Replace(ast.NewIntValue(&ast.IntValue{Value: "2"}))
+			return visitor.Continue
+		},
+	})
+
+	if root != astDoc {
+		t.Fatalf("expected the root to be kept")
+	}
+	expected := "{\n  e {\n    y\n  }\n  c(arg: 2)\n  d\n}\n"
+	if printed := printer.Print(astDoc); printed != expected {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, printed))
+	}
+	expectedNames := []string{"e", "y", "c", "arg", "skip", "if", "d"}
+	if !reflect.DeepEqual(visitedNames, expectedNames) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedNames, visitedNames))
+	}
+}
+
+func TestWalk_InParallel_AllowsSkippingDifferentSubTrees(t *testing.T) {
+	astDoc := parse(t, `{ a { x }, b { y} }`)
+
+	visited := []interface{}{}
+	expectedVisited := []interface{}{
+		[]interface{}{"no-a", "enter", "Document", nil},
+		[]interface{}{"no-b", "enter", "Document", nil},
+		[]interface{}{"no-a", "enter", "OperationDefinition", nil},
+		[]interface{}{"no-b", "enter", "OperationDefinition", nil},
+		[]interface{}{"no-a", "enter", "SelectionSet", nil},
+		[]interface{}{"no-b", "enter", "SelectionSet", nil},
+		[]interface{}{"no-a", "enter", "Field", nil},
+		[]interface{}{"no-b", "enter", "Field", nil},
+		[]interface{}{"no-b", "enter", "Name", "a"},
+		[]interface{}{"no-b", "leave", "Name", "a"},
+		[]interface{}{"no-b", "enter", "SelectionSet", nil},
+		[]interface{}{"no-b", "enter", "Field", nil},
+		[]interface{}{"no-b", "enter", "Name", "x"},
+		[]interface{}{"no-b", "leave", "Name", "x"},
+		[]interface{}{"no-b", "leave", "Field", nil},
+		[]interface{}{"no-b", "leave", "SelectionSet", nil},
+		[]interface{}{"no-b", "leave", "Field", nil},
+		[]interface{}{"no-a", "enter", "Field", nil},
+		[]interface{}{"no-b", "enter", "Field", nil},
+		[]interface{}{"no-a", "enter", "Name", "b"},
+		[]interface{}{"no-a", "leave", "Name", "b"},
+		[]interface{}{"no-a", "enter", "SelectionSet", nil},
+		[]interface{}{"no-a", "enter", "Field", nil},
+		[]interface{}{"no-a", "enter", "Name", "y"},
+		[]interface{}{"no-a", "leave", "Name", "y"},
+		[]interface{}{"no-a", "leave", "Field", nil},
+		[]interface{}{"no-a", "leave", "SelectionSet", nil},
+		[]interface{}{"no-a", "leave", "Field", nil},
+		[]interface{}{"no-a", "leave", "SelectionSet", nil},
+		[]interface{}{"no-b", "leave", "SelectionSet", nil},
+		[]interface{}{"no-a", "leave", "OperationDefinition", nil},
+		[]interface{}{"no-b", "leave", "OperationDefinition", nil},
+		[]interface{}{"no-a", "leave", "Document", nil},
+		[]interface{}{"no-b", "leave", "Document", nil},
+	}
+
+	skipping := func(name string) *visitor.Visitor {
+		enter, leave := walkLog(&visited, "no-"+name)
+		return &visitor.Visitor{
+			Enter: enter,
+			Leave: leave,
+			EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+				if node.Name.Value == name {
+					return visitor.Skip
+				}
+				return visitor.Continue
+			},
+		}
+	}
+	visitor.Walk(astDoc, visitor.InParallel(skipping("a"), skipping("b")))
+
+	if !reflect.DeepEqual(visited, expectedVisited) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedVisited, visited))
+	}
+}
+
+func TestWalk_FromOptionsGivesTheParamsOfVisit(t *testing.T) {
+	b, err := ioutil.ReadFile("../../kitchen-sink.graphql")
+	if err != nil {
+		t.Fatalf("unable to load kitchen-sink.graphql")
+	}
+	astDoc := parse(t, string(b))
+
+	kind := func(node ast.Node) interface{} {
+		if node == nil {
+			return nil
+		}
+		return node.GetKind()
+	}
+	record := func(visited *[]string) visitor.VisitFunc {
+		return func(p visitor.VisitFuncParams) (string, interface{}) {
+			ancestors := []interface{}{}
+			for _, ancestor := range p.Ancestors {
+				ancestors = append(ancestors, kind(ancestor))
+			}
+			*visited = append(*visited, fmt.Sprint(kind(p.Node.(ast.Node)), p.Key, kind(p.Parent), p.Path, ancestors))
+			return visitor.ActionNoChange, nil
+		}
+	}
+
+	expectedVisited, visited := []string{}, []string{}
+	visitor.Visit(astDoc, &visitor.VisitorOptions{
+		Enter: record(&expectedVisited),
+		Leave: record(&expectedVisited),
+	}, nil)
+	visitor.Walk(astDoc, visitor.FromOptions(&visitor.VisitorOptions{
+		Enter: record(&visited),
+		Leave: record(&visited),
+	}))
+
+	for i := range expectedVisited {
+		if i >= len(visited) || visited[i] != expectedVisited[i] {
+			t.Fatalf("Unexpected visit #%v, expected: %v, got: %v", i, expectedVisited[i], visited[i])
+		}
+	}
+	if len(visited) != len(expectedVisited) {
+		t.Fatalf("Unexpected visits, expected: %v, got: %v", len(expectedVisited), len(visited))
+	}
+}
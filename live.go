@@ -0,0 +1,238 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dagger/graphql/language/ast"
+)
+
+// LiveDirective marks a query as live: LiveQueryStore.ExecuteLive
+// re-executes it whenever the resources its result depends on are
+// invalidated. It must be added to the directives of the schema to be used:
+//
+//	Directives: append(graphql.SpecifiedDirectives, graphql.LiveDirective)
+var LiveDirective = NewDirective(DirectiveConfig{
+	Name: "live",
+	Description: "Directs the executor to send a new result whenever the data " +
+		"of the query changes.",
+	Locations: []string{
+		DirectiveLocationQuery,
+	},
+})
+
+type liveRecorderContextKey struct{}
+
+// liveRecorder records the invalidation keys of an execution of a live query.
+type liveRecorder struct {
+	store *LiveQueryStore
+	query *liveQuery
+	keys  map[string]struct{}
+}
+
+// AddInvalidationKeys records that the result being resolved with ctx
+// depends on the resources identified by keys, so that invalidating any of
+// them re-executes the live query. It must be called before reading the
+// resources, and is a no-op outside of a live query.
+func AddInvalidationKeys(ctx context.Context, keys ...string) {
+	if ctx == nil {
+		return
+	}
+	recorder, ok := ctx.Value(liveRecorderContextKey{}).(*liveRecorder)
+	if !ok {
+		return
+	}
+	recorder.store.watch(recorder, keys)
+}
+
+// LiveQueryStore executes live queries and tracks the invalidation keys their
+// results depend on. It is safe for concurrent use.
+type LiveQueryStore struct {
+	// Throttle is the minimum interval between two executions of a live
+	// query. Invalidations are coalesced in the meantime.
+	Throttle time.Duration
+
+	mu      sync.Mutex
+	queries map[string]map[*liveQuery]struct{}
+}
+
+type liveQuery struct {
+	// keys is guarded by the mutex of the store
+	keys map[string]struct{}
+
+	invalidated chan struct{}
+}
+
+// NewLiveQueryStore returns an empty LiveQueryStore.
+func NewLiveQueryStore() *LiveQueryStore {
+	return &LiveQueryStore{
+		queries: map[string]map[*liveQuery]struct{}{},
+	}
+}
+
+// Invalidate re-executes the live queries depending on any of keys.
+func (s *LiveQueryStore) Invalidate(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		for query := range s.queries[key] {
+			select {
+			case query.invalidated <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (s *LiveQueryStore) watch(recorder *liveRecorder, keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		recorder.keys[key] = struct{}{}
+		if _, ok := recorder.query.keys[key]; ok {
+			continue
+		}
+		recorder.query.keys[key] = struct{}{}
+		queries, ok := s.queries[key]
+		if !ok {
+			queries = map[*liveQuery]struct{}{}
+			s.queries[key] = queries
+		}
+		queries[recorder.query] = struct{}{}
+	}
+}
+
+// unwatch stops watching the keys of query that are not in keys.
+func (s *LiveQueryStore) unwatch(query *liveQuery, keys map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range query.keys {
+		if _, ok := keys[key]; ok {
+			continue
+		}
+		delete(query.keys, key)
+		delete(s.queries[key], query)
+		if len(s.queries[key]) == 0 {
+			delete(s.queries, key)
+		}
+	}
+}
+
+// Live performs a live query on the given query and schema, see ExecuteLive.
+func (s *LiveQueryStore) Live(p Params) chan *Result {
+	AST, result := parseAndValidate(&p)
+	if result != nil {
+		return sendOneResultAndClose(result)
+	}
+
+	return s.ExecuteLive(ExecuteParams{
+		Schema:        p.Schema,
+		Root:          p.RootObject,
+		AST:           AST,
+		OperationName: p.OperationName,
+		Args:          p.VariableValues,
+		Context:       p.Context,
+	})
+}
+
+// ExecuteLive is similar to graphql.Execute but returns a channel instead of a Result.
+// If the operation is a query marked with the @live directive, the invalidation keys
+// added by its resolvers with AddInvalidationKeys are recorded, and a new Result is
+// sent each time one of them is invalidated with Invalidate, until p.Context is done.
+// Otherwise, the channel is closed after the first Result.
+func (s *LiveQueryStore) ExecuteLive(p ExecuteParams) chan *Result {
+	if p.Context == nil {
+		p.Context = context.Background()
+	}
+	ctx, cancel := context.WithCancel(p.Context)
+
+	resultChannel := make(chan *Result)
+	go func() {
+		defer close(resultChannel)
+		defer cancel()
+
+		if !isLiveOperation(p.AST, p.OperationName) {
+			p.Context = ctx
+			select {
+			case resultChannel <- Execute(p):
+			case <-ctx.Done():
+			}
+			return
+		}
+
+		query := &liveQuery{
+			keys:        map[string]struct{}{},
+			invalidated: make(chan struct{}, 1),
+		}
+		defer s.unwatch(query, nil)
+
+		for {
+			// invalidations from now on trigger a new execution
+			select {
+			case <-query.invalidated:
+			default:
+			}
+			executedAt := time.Now()
+
+			recorder := &liveRecorder{
+				store: s,
+				query: query,
+				keys:  map[string]struct{}{},
+			}
+			p.Context = context.WithValue(ctx, liveRecorderContextKey{}, recorder)
+			result := Execute(p)
+			s.unwatch(query, recorder.keys)
+
+			select {
+			case resultChannel <- result:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-query.invalidated:
+			case <-ctx.Done():
+				return
+			}
+			if wait := s.Throttle - time.Since(executedAt); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}
+	}()
+
+	return resultChannel
+}
+
+// isLiveOperation reports whether the operation of doc selected by
+// operationName is a query marked with the @live directive.
+func isLiveOperation(doc *ast.Document, operationName string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName != "" && (operation.Name == nil || operation.Name.Value != operationName) {
+			continue
+		}
+		if operation.Operation != ast.OperationTypeQuery {
+			return false
+		}
+		for _, directive := range operation.Directives {
+			if directive.Name != nil && directive.Name.Value == LiveDirective.Name {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
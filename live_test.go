@@ -0,0 +1,131 @@
+package graphql_test
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/testutil"
+)
+
+func newLiveSchema(t *testing.T, counter *int32) graphql.Schema {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"counter": &graphql.Field{
+					Type: graphql.Int,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						graphql.AddInvalidationKeys(p.Context, "counter")
+						return int(atomic.LoadInt32(counter)), nil
+					},
+				},
+			},
+		}),
+		Directives: append(graphql.SpecifiedDirectives, graphql.LiveDirective),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func expectLiveResult(t *testing.T, c chan *graphql.Result, expected map[string]interface{}) {
+	t.Helper()
+	select {
+	case result, ok := <-c:
+		if !ok {
+			t.Fatal("unexpected end of the live query")
+		}
+		if !reflect.DeepEqual(expected, result.Data) {
+			t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result.Data))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a result")
+	}
+}
+
+func TestLiveQuery(t *testing.T) {
+	var counter int32
+	store := graphql.NewLiveQueryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	c := store.Live(graphql.Params{
+		Schema:        newLiveSchema(t, &counter),
+		RequestString: "query @live { counter }",
+		Context:       ctx,
+	})
+	expectLiveResult(t, c, map[string]interface{}{"counter": 0})
+
+	atomic.AddInt32(&counter, 1)
+	store.Invalidate("counter")
+	expectLiveResult(t, c, map[string]interface{}{"counter": 1})
+
+	store.Invalidate("other")
+	select {
+	case result := <-c:
+		t.Fatalf("unexpected result %v", result)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancel()
+	for range c {
+	}
+}
+
+func TestLiveQuery_Throttle(t *testing.T) {
+	var counter int32
+	store := graphql.NewLiveQueryStore()
+	store.Throttle = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := store.Live(graphql.Params{
+		Schema:        newLiveSchema(t, &counter),
+		RequestString: "query @live { counter }",
+		Context:       ctx,
+	})
+	expectLiveResult(t, c, map[string]interface{}{"counter": 0})
+	start := time.Now()
+
+	// both invalidations are coalesced in a single execution
+	atomic.AddInt32(&counter, 1)
+	store.Invalidate("counter")
+	atomic.AddInt32(&counter, 1)
+	store.Invalidate("counter")
+	expectLiveResult(t, c, map[string]interface{}{"counter": 2})
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected the execution to be throttled, got a result after %v", elapsed)
+	}
+}
+
+func TestLiveQuery_NotLive(t *testing.T) {
+	var counter int32
+	store := graphql.NewLiveQueryStore()
+	c := store.Live(graphql.Params{
+		Schema:        newLiveSchema(t, &counter),
+		RequestString: "{ counter }",
+	})
+	expectLiveResult(t, c, map[string]interface{}{"counter": 0})
+	if _, ok := <-c; ok {
+		t.Fatal("expected a single result")
+	}
+}
+
+func TestLiveQuery_UnknownDirective(t *testing.T) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: dummyQuery,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := graphql.NewLiveQueryStore().Live(graphql.Params{
+		Schema:        schema,
+		RequestString: "query @live { hello }",
+	})
+	result := <-c
+	if len(result.Errors) != 1 || result.Errors[0].Message != `Unknown directive "live".` {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
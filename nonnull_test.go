@@ -6,7 +6,9 @@ import (
 
 	"github.com/dagger/graphql"
 	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
 	"github.com/dagger/graphql/language/location"
+	"github.com/dagger/graphql/language/parser"
 	"github.com/dagger/graphql/testutil"
 )
 
@@ -1171,3 +1173,132 @@ func TestNonNull_NullsTheTopLevelIfSyncNonNullableFieldResolvesNull(t *testing.T
 		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
 	}
 }
+
+func parseWithNullability(t *testing.T, query string) *ast.Document {
+	astDoc, err := parser.Parse(parser.ParseParams{
+		Source:  query,
+		Options: parser.ParseOptions{ExperimentalClientControlledNullability: true},
+	})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return astDoc
+}
+
+func TestNonNull_RequiredDesignatorNullsTheParentOfAFieldReturningNull(t *testing.T) {
+	doc := `
+      query Q { nest { sync! } }
+	`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"nest": nil,
+		},
+		Errors: []gqlerrors.FormattedError{
+			{
+				Message: `Cannot return null for non-nullable field DataType.sync.`,
+				Locations: []location.SourceLocation{
+					{Line: 2, Column: 24},
+				},
+				Path: []interface{}{
+					"nest", "sync",
+				},
+			},
+		},
+	}
+	ep := graphql.ExecuteParams{
+		Schema: nonNullTestSchema,
+		AST:    parseWithNullability(t, doc),
+		Root:   nullingData,
+	}
+	result := testutil.TestExecute(t, ep)
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestNonNull_OptionalDesignatorNullsOnlyAFieldThatThrows(t *testing.T) {
+	doc := `
+      query Q { nest { nonNullSync? } }
+	`
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"nest": map[string]interface{}{
+				"nonNullSync": nil,
+			},
+		},
+		Errors: []gqlerrors.FormattedError{
+			{
+				Message: nonNullSyncError,
+				Locations: []location.SourceLocation{
+					{Line: 2, Column: 24},
+				},
+				Path: []interface{}{
+					"nest", "nonNullSync",
+				},
+			},
+		},
+	}
+	ep := graphql.ExecuteParams{
+		Schema: nonNullTestSchema,
+		AST:    parseWithNullability(t, doc),
+		Root:   throwingData,
+	}
+	result := testutil.TestExecute(t, ep)
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestNonNull_ListNullabilityAppliesToTheItems(t *testing.T) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"list": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return []interface{}{"a", nil}, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Error in schema %v", err.Error())
+	}
+
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"list": []interface{}{"a", nil},
+		},
+	}
+	result := testutil.TestExecute(t, graphql.ExecuteParams{
+		Schema: schema,
+		AST:    parseWithNullability(t, `{ list[?] }`),
+	})
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+
+	expected = &graphql.Result{
+		Data: nil,
+		Errors: []gqlerrors.FormattedError{
+			{
+				Message: `Cannot return null for non-nullable field Query.list.`,
+				Locations: []location.SourceLocation{
+					{Line: 1, Column: 3},
+				},
+				Path: []interface{}{
+					"list", 1,
+				},
+			},
+		},
+	}
+	result = testutil.TestExecute(t, graphql.ExecuteParams{
+		Schema: schema,
+		AST:    parseWithNullability(t, `{ list! }`),
+	})
+	if !testutil.EqualResults(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
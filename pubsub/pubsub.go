@@ -0,0 +1,256 @@
+// Package pubsub provides an in-memory, topic-based publish/subscribe broker
+// whose subscriptions can be returned from the Subscribe function of a
+// graphql.Field.
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dagger/graphql"
+)
+
+// DefaultBufferSize is the number of payloads buffered for each subscriber
+// when no buffer size is set.
+const DefaultBufferSize = 16
+
+// Policy is what a subscriber does when its buffer is full.
+type Policy int
+
+const (
+	// DefaultPolicy applies the default policy of the PubSub to a
+	// subscriber, and DropOldest to the PubSub itself.
+	DefaultPolicy Policy = iota
+
+	// DropOldest discards the oldest buffered payload to make room for the new
+	// one.
+	DropOldest
+
+	// Block makes Publish wait until the subscriber receives a payload or is
+	// unsubscribed.
+	Block
+
+	// Disconnect unsubscribes the subscriber, closing its channel.
+	Disconnect
+)
+
+// Filter reports whether payload is sent to a subscription. args are the
+// arguments of the subscription field.
+type Filter func(payload interface{}, args map[string]interface{}) bool
+
+// Config configures a PubSub.
+type Config struct {
+	// BufferSize is the default number of payloads buffered for each
+	// subscriber. DefaultBufferSize is used when zero.
+	BufferSize int
+
+	// Policy is the default policy applied when a subscriber's buffer is full.
+	// DropOldest is used when DefaultPolicy.
+	Policy Policy
+}
+
+// SubscribeOptions overrides the buffering of a single subscriber.
+type SubscribeOptions struct {
+	// BufferSize is the number of payloads buffered for the subscriber. The
+	// PubSub's buffer size is used when zero.
+	BufferSize int
+
+	// Policy is applied when the subscriber's buffer is full. The PubSub's
+	// policy is used when DefaultPolicy.
+	Policy Policy
+}
+
+// PubSub is an in-memory publish/subscribe broker. It is safe for
+// concurrent use.
+type PubSub struct {
+	bufferSize int
+	policy     Policy
+
+	mu     sync.RWMutex
+	topics map[string]map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	topic  string
+	filter func(payload interface{}) bool
+	policy Policy
+
+	// done is closed when the subscriber is unsubscribed, to release the
+	// publishers blocked on it
+	done chan struct{}
+	once sync.Once
+
+	// mu guards sends on ch against its closing
+	mu     sync.Mutex
+	ch     chan interface{}
+	closed bool
+}
+
+// New returns a PubSub for the given config.
+func New(p *Config) *PubSub {
+	if p == nil {
+		p = &Config{}
+	}
+	bufferSize := p.BufferSize
+	if bufferSize == 0 {
+		bufferSize = DefaultBufferSize
+	}
+	policy := p.Policy
+	if policy == DefaultPolicy {
+		policy = DropOldest
+	}
+	return &PubSub{
+		bufferSize: bufferSize,
+		policy:     policy,
+		topics:     map[string]map[*subscriber]struct{}{},
+	}
+}
+
+// Subscribe returns a channel receiving the payloads published to topic for
+// which filter, if set, returns true. The subscriber is unsubscribed and the
+// channel closed once ctx is done.
+func (ps *PubSub) Subscribe(ctx context.Context, topic string, filter func(payload interface{}) bool) <-chan interface{} {
+	return ps.SubscribeWithOptions(ctx, topic, filter, SubscribeOptions{})
+}
+
+// SubscribeWithOptions is like Subscribe, with the buffering of the
+// subscriber set by opts.
+func (ps *PubSub) SubscribeWithOptions(ctx context.Context, topic string, filter func(payload interface{}) bool, opts SubscribeOptions) <-chan interface{} {
+	bufferSize := opts.BufferSize
+	if bufferSize == 0 {
+		bufferSize = ps.bufferSize
+	}
+	policy := opts.Policy
+	if policy == DefaultPolicy {
+		policy = ps.policy
+	}
+	sub := &subscriber{
+		topic:  topic,
+		filter: filter,
+		policy: policy,
+		done:   make(chan struct{}),
+		ch:     make(chan interface{}, bufferSize),
+	}
+
+	ps.mu.Lock()
+	subs, ok := ps.topics[topic]
+	if !ok {
+		subs = map[*subscriber]struct{}{}
+		ps.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	ps.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			ps.unsubscribe(sub)
+		case <-sub.done:
+		}
+	}()
+	return sub.ch
+}
+
+// SubscribeFn returns a graphql.FieldResolveFn subscribing to topic, for use
+// as the Subscribe function of a field. filter, if set, is called with the
+// arguments of the field.
+func (ps *PubSub) SubscribeFn(topic string, filter Filter) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		ctx := p.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		var fn func(payload interface{}) bool
+		if filter != nil {
+			fn = func(payload interface{}) bool {
+				return filter(payload, p.Args)
+			}
+		}
+		return ps.Subscribe(ctx, topic, fn), nil
+	}
+}
+
+// Publish sends payload to the subscribers of topic. It only blocks for the
+// subscribers using the Block policy.
+func (ps *PubSub) Publish(topic string, payload interface{}) {
+	ps.mu.RLock()
+	subs := make([]*subscriber, 0, len(ps.topics[topic]))
+	for sub := range ps.topics[topic] {
+		subs = append(subs, sub)
+	}
+	ps.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.filter != nil && !sub.filter(payload) {
+			continue
+		}
+		if !sub.send(payload) {
+			ps.unsubscribe(sub)
+		}
+	}
+}
+
+// Subscribers returns the number of subscribers of topic.
+func (ps *PubSub) Subscribers(topic string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.topics[topic])
+}
+
+func (ps *PubSub) unsubscribe(sub *subscriber) {
+	sub.once.Do(func() {
+		close(sub.done)
+
+		ps.mu.Lock()
+		if subs, ok := ps.topics[sub.topic]; ok {
+			delete(subs, sub)
+			if len(subs) == 0 {
+				delete(ps.topics, sub.topic)
+			}
+		}
+		ps.mu.Unlock()
+
+		sub.mu.Lock()
+		sub.closed = true
+		close(sub.ch)
+		sub.mu.Unlock()
+	})
+}
+
+// send delivers payload according to the subscriber's policy, and returns
+// false when the subscriber must be disconnected.
+func (sub *subscriber) send(payload interface{}) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return true
+	}
+
+	switch sub.policy {
+	case Block:
+		select {
+		case sub.ch <- payload:
+		case <-sub.done:
+		}
+		return true
+	case Disconnect:
+		select {
+		case sub.ch <- payload:
+			return true
+		default:
+			return false
+		}
+	default:
+		for {
+			select {
+			case sub.ch <- payload:
+				return true
+			default:
+			}
+			select {
+			case <-sub.ch:
+			default:
+			}
+		}
+	}
+}
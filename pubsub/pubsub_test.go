@@ -0,0 +1,202 @@
+package pubsub_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/pubsub"
+	"github.com/dagger/graphql/testutil"
+)
+
+// receive returns the payloads received from c until it is closed.
+func receive(t *testing.T, c <-chan interface{}) []interface{} {
+	payloads := []interface{}{}
+	for {
+		select {
+		case payload, ok := <-c:
+			if !ok {
+				return payloads
+			}
+			payloads = append(payloads, payload)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the channel was not closed")
+		}
+	}
+}
+
+func TestPubSub_PublishSubscribe(t *testing.T) {
+	ps := pubsub.New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	all := ps.Subscribe(ctx, "events", nil)
+	even := ps.Subscribe(ctx, "events", func(payload interface{}) bool {
+		return payload.(int)%2 == 0
+	})
+	other := ps.Subscribe(ctx, "other", nil)
+
+	for i := 1; i <= 4; i++ {
+		ps.Publish("events", i)
+	}
+	cancel()
+
+	if expected, payloads := []interface{}{1, 2, 3, 4}, receive(t, all); !reflect.DeepEqual(expected, payloads) {
+		t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+	}
+	if expected, payloads := []interface{}{2, 4}, receive(t, even); !reflect.DeepEqual(expected, payloads) {
+		t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+	}
+	if payloads := receive(t, other); len(payloads) != 0 {
+		t.Fatalf("unexpected payloads %v", payloads)
+	}
+}
+
+func TestPubSub_UnsubscribeOnCancel(t *testing.T) {
+	ps := pubsub.New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	c := ps.Subscribe(ctx, "events", nil)
+	if n := ps.Subscribers("events"); n != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", n)
+	}
+	cancel()
+	receive(t, c)
+	if n := ps.Subscribers("events"); n != 0 {
+		t.Fatalf("expected no subscribers, got %d", n)
+	}
+	// publishing without subscribers is a no-op
+	ps.Publish("events", 1)
+}
+
+func TestPubSub_Policies(t *testing.T) {
+	ps := pubsub.New(&pubsub.Config{BufferSize: 2})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := ps.SubscribeWithOptions(ctx, "drop", nil, pubsub.SubscribeOptions{Policy: pubsub.DropOldest})
+		for i := 1; i <= 4; i++ {
+			ps.Publish("drop", i)
+		}
+		cancel()
+		if expected, payloads := []interface{}{3, 4}, receive(t, c); !reflect.DeepEqual(expected, payloads) {
+			t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+		}
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		c := ps.SubscribeWithOptions(context.Background(), "disconnect", nil, pubsub.SubscribeOptions{Policy: pubsub.Disconnect})
+		for i := 1; i <= 4; i++ {
+			ps.Publish("disconnect", i)
+		}
+		if expected, payloads := []interface{}{1, 2}, receive(t, c); !reflect.DeepEqual(expected, payloads) {
+			t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+		}
+		if n := ps.Subscribers("disconnect"); n != 0 {
+			t.Fatalf("expected no subscribers, got %d", n)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := ps.SubscribeWithOptions(ctx, "block", nil, pubsub.SubscribeOptions{Policy: pubsub.Block, BufferSize: 1})
+		published := make(chan struct{})
+		go func() {
+			ps.Publish("block", 1)
+			ps.Publish("block", 2)
+			close(published)
+		}()
+		select {
+		case <-published:
+			t.Fatal("expected Publish to block")
+		case <-time.After(20 * time.Millisecond):
+		}
+		if payload := <-c; payload != 1 {
+			t.Fatalf("unexpected payload %v", payload)
+		}
+		<-published
+
+		// the buffer is full again, cancelling releases the blocked publisher
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		ps.Publish("block", 3)
+		if expected, payloads := []interface{}{2}, receive(t, c); !reflect.DeepEqual(expected, payloads) {
+			t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+		}
+	})
+}
+
+func TestPubSub_SubscribeOptionsDefaultToThePubSubPolicy(t *testing.T) {
+	ps := pubsub.New(&pubsub.Config{Policy: pubsub.Disconnect})
+	c := ps.SubscribeWithOptions(context.Background(), "events", nil, pubsub.SubscribeOptions{BufferSize: 2})
+	for i := 1; i <= 4; i++ {
+		ps.Publish("events", i)
+	}
+	if expected, payloads := []interface{}{1, 2}, receive(t, c); !reflect.DeepEqual(expected, payloads) {
+		t.Fatalf("Unexpected payloads, Diff: %v", testutil.Diff(expected, payloads))
+	}
+	if n := ps.Subscribers("events"); n != 0 {
+		t.Fatalf("expected no subscribers, got %d", n)
+	}
+}
+
+func TestPubSub_SubscribeFn(t *testing.T) {
+	ps := pubsub.New(nil)
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{Type: graphql.String},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"messages": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						&graphql.ArgumentConfig{
+							Name: "room",
+							Type: graphql.NewNonNull(graphql.String),
+						},
+					},
+					Subscribe: ps.SubscribeFn("messages", func(payload interface{}, args map[string]interface{}) bool {
+						return payload.(map[string]interface{})["room"] == args["room"]
+					}),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(map[string]interface{})["text"], nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { messages(room: "go") }`,
+		Context:       ctx,
+	})
+	for ps.Subscribers("messages") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ps.Publish("messages", map[string]interface{}{"room": "rust", "text": "hi"})
+	ps.Publish("messages", map[string]interface{}{"room": "go", "text": "hello"})
+
+	res := <-c
+	expected := map[string]interface{}{"messages": "hello"}
+	if !reflect.DeepEqual(expected, res.Data) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, res.Data))
+	}
+	cancel()
+	for range c {
+	}
+	for ps.Subscribers("messages") != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -29,6 +29,7 @@ var SpecifiedRules = []ValidationRuleFn{
 	NoUndefinedVariablesRule,
 	NoUnusedFragmentsRule,
 	NoUnusedVariablesRule,
+	NullabilityDesignatorsMatchListDepthRule,
 	OverlappingFieldsCanBeMergedRule,
 	PossibleFragmentSpreadsRule,
 	ProvidedNonNullArgumentsRule,
@@ -43,6 +44,10 @@ var SpecifiedRules = []ValidationRuleFn{
 }
 
 type ValidationRuleInstance struct {
+	// Visitor is walked over the document along with the other rules.
+	Visitor *visitor.Visitor
+	// VisitorOpts is used instead of Visitor when the latter is nil, for rules
+	// written for visitor.Visit.
 	VisitorOpts *visitor.VisitorOptions
 }
 type ValidationRuleFn func(context *ValidationContext) *ValidationRuleInstance
@@ -58,9 +63,8 @@ func newValidationError(message string, nodes []ast.Node) *gqlerrors.Error {
 	)
 }
 
-func reportError(context *ValidationContext, message string, nodes []ast.Node) (string, interface{}) {
+func reportError(context *ValidationContext, message string, nodes []ast.Node) {
 	context.ReportError(newValidationError(message, nodes))
-	return visitor.ActionNoChange, nil
 }
 
 // ArgumentsOfCorrectTypeRule Argument values of correct type
@@ -68,38 +72,32 @@ func reportError(context *ValidationContext, message string, nodes []ast.Node) (
 // A GraphQL document is only valid if all field argument literal values are
 // of the type expected by their position.
 func ArgumentsOfCorrectTypeRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Argument: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if argAST, ok := p.Node.(*ast.Argument); ok {
-						if argDef := context.Argument(); argDef != nil {
-							if isValid, messages := isValidLiteralValue(argDef.Type, argAST.Value); !isValid {
-								var messagesStr, argNameValue string
-								if argAST.Name != nil {
-									argNameValue = argAST.Name.Value
-								}
-
-								if len(messages) > 0 {
-									messagesStr = "\n" + strings.Join(messages, "\n")
-								}
-								reportError(
-									context,
-									fmt.Sprintf(`Argument "%v" has invalid value %v.%v`,
-										argNameValue, printer.Print(argAST.Value), messagesStr),
-									[]ast.Node{argAST.Value},
-								)
-							}
+	ruleVisitor := &visitor.Visitor{
+		EnterArgument: func(argAST *ast.Argument, c *visitor.Cursor) visitor.Action {
+			if argDef := context.Argument(); argDef != nil {
+				if isValid, messages := isValidLiteralValue(argDef.Type, argAST.Value); !isValid {
+					var messagesStr, argNameValue string
+					if argAST.Name != nil {
+						argNameValue = argAST.Name.Value
+					}
 
-						}
+					if len(messages) > 0 {
+						messagesStr = "\n" + strings.Join(messages, "\n")
 					}
-					return visitor.ActionSkip, nil
-				},
-			},
+					reportError(
+						context,
+						fmt.Sprintf(`Argument "%v" has invalid value %v.%v`,
+							argNameValue, printer.Print(argAST.Value), messagesStr),
+						[]ast.Node{argAST.Value},
+					)
+				}
+
+			}
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -108,59 +106,49 @@ func ArgumentsOfCorrectTypeRule(context *ValidationContext) *ValidationRuleInsta
 // A GraphQL document is only valid if all variable default values are of the
 // type expected by their definition.
 func DefaultValuesOfCorrectTypeRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if varDefAST, ok := p.Node.(*ast.VariableDefinition); ok {
-						var (
-							name         string
-							defaultValue = varDefAST.DefaultValue
-							messagesStr  string
-						)
-						if varDefAST.Variable != nil && varDefAST.Variable.Name != nil {
-							name = varDefAST.Variable.Name.Value
-						}
-						ttype := context.InputType()
-
-						// when input variable value must be nonNull, and set default value is unnecessary
-						if ttype, ok := ttype.(*NonNull); ok && defaultValue != nil {
-							reportError(
-								context,
-								fmt.Sprintf(`Variable "$%v" of type "%v" is required and will not use the default value. Perhaps you meant to use type "%v".`,
-									name, ttype, ttype.OfType),
-								[]ast.Node{defaultValue},
-							)
-						}
-						if isValid, messages := isValidLiteralValue(ttype, defaultValue); !isValid && defaultValue != nil {
-							if len(messages) > 0 {
-								messagesStr = "\n" + strings.Join(messages, "\n")
-							}
-							reportError(
-								context,
-								fmt.Sprintf(`Variable "$%v" has invalid default value: %v.%v`,
-									name, printer.Print(defaultValue), messagesStr),
-								[]ast.Node{defaultValue},
-							)
-						}
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.SelectionSet: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterVariableDefinition: func(varDefAST *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			var (
+				name         string
+				defaultValue = varDefAST.DefaultValue
+				messagesStr  string
+			)
+			if varDefAST.Variable != nil && varDefAST.Variable.Name != nil {
+				name = varDefAST.Variable.Name.Value
+			}
+			ttype := context.InputType()
+
+			// when input variable value must be nonNull, and set default value is unnecessary
+			if ttype, ok := ttype.(*NonNull); ok && defaultValue != nil {
+				reportError(
+					context,
+					fmt.Sprintf(`Variable "$%v" of type "%v" is required and will not use the default value. Perhaps you meant to use type "%v".`,
+						name, ttype, ttype.OfType),
+					[]ast.Node{defaultValue},
+				)
+			}
+			if isValid, messages := isValidLiteralValue(ttype, defaultValue); !isValid && defaultValue != nil {
+				if len(messages) > 0 {
+					messagesStr = "\n" + strings.Join(messages, "\n")
+				}
+				reportError(
+					context,
+					fmt.Sprintf(`Variable "$%v" has invalid default value: %v.%v`,
+						name, printer.Print(defaultValue), messagesStr),
+					[]ast.Node{defaultValue},
+				)
+			}
+			return visitor.Skip
+		},
+		EnterSelectionSet: func(node *ast.SelectionSet, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 func quoteStrings(slice []string) []string {
@@ -205,51 +193,44 @@ func UndefinedFieldMessage(fieldName string, ttypeName string, suggestedTypeName
 // A GraphQL document is only valid if all fields selected are defined by the
 // parent type, or are an allowed meta field such as __typenamme
 func FieldsOnCorrectTypeRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Field: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					var action = visitor.ActionNoChange
-					if node, ok := p.Node.(*ast.Field); ok {
-						var ttype Composite
-						if ttype = context.ParentType(); ttype == nil {
-							return action, nil
-						}
-						switch ttype.(type) {
-						case *Object, *Interface, *Union:
-							if reflect.ValueOf(ttype).IsNil() {
-								return action, nil
-							}
-						}
-						fieldDef := context.FieldDef()
-						if fieldDef == nil {
-							// This field doesn't exist, lets look for suggestions.
-							var nodeName string
-							if node.Name != nil {
-								nodeName = node.Name.Value
-							}
-							// First determine if there are any suggested types to condition on.
-							suggestedTypeNames := getSuggestedTypeNames(context.Schema(), ttype, nodeName)
-
-							// If there are no suggested types, then perhaps this was a typo?
-							suggestedFieldNames := []string{}
-							if len(suggestedTypeNames) == 0 {
-								suggestedFieldNames = getSuggestedFieldNames(context.Schema(), ttype, nodeName)
-							}
-							reportError(
-								context,
-								UndefinedFieldMessage(nodeName, ttype.Name(), suggestedTypeNames, suggestedFieldNames),
-								[]ast.Node{node},
-							)
-						}
-					}
-					return action, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			var ttype Composite
+			if ttype = context.ParentType(); ttype == nil {
+				return visitor.Continue
+			}
+			switch ttype.(type) {
+			case *Object, *Interface, *Union:
+				if reflect.ValueOf(ttype).IsNil() {
+					return visitor.Continue
+				}
+			}
+			fieldDef := context.FieldDef()
+			if fieldDef == nil {
+				// This field doesn't exist, lets look for suggestions.
+				var nodeName string
+				if node.Name != nil {
+					nodeName = node.Name.Value
+				}
+				// First determine if there are any suggested types to condition on.
+				suggestedTypeNames := getSuggestedTypeNames(context.Schema(), ttype, nodeName)
+
+				// If there are no suggested types, then perhaps this was a typo?
+				suggestedFieldNames := []string{}
+				if len(suggestedTypeNames) == 0 {
+					suggestedFieldNames = getSuggestedFieldNames(context.Schema(), ttype, nodeName)
+				}
+				reportError(
+					context,
+					UndefinedFieldMessage(nodeName, ttype.Name(), suggestedTypeNames, suggestedFieldNames),
+					[]ast.Node{node},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -368,46 +349,36 @@ func (s suggestedInterfaceSortedSlice) Less(i, j int) bool {
 // can only be spread into a composite type (object, interface, or union), the
 // type condition must also be a composite type.
 func FragmentsOnCompositeTypesRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.InlineFragment: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.InlineFragment); ok {
-						ttype := context.Type()
-						if node.TypeCondition != nil && ttype != nil && !IsCompositeType(ttype) {
-							reportError(
-								context,
-								fmt.Sprintf(`Fragment cannot condition on non composite type "%v".`, ttype),
-								[]ast.Node{node.TypeCondition},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.FragmentDefinition); ok {
-						ttype := context.Type()
-						if ttype != nil && !IsCompositeType(ttype) {
-							nodeName := ""
-							if node.Name != nil {
-								nodeName = node.Name.Value
-							}
-							reportError(
-								context,
-								fmt.Sprintf(`Fragment "%v" cannot condition on non composite type "%v".`, nodeName, printer.Print(node.TypeCondition)),
-								[]ast.Node{node.TypeCondition},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+			ttype := context.Type()
+			if node.TypeCondition != nil && ttype != nil && !IsCompositeType(ttype) {
+				reportError(
+					context,
+					fmt.Sprintf(`Fragment cannot condition on non composite type "%v".`, ttype),
+					[]ast.Node{node.TypeCondition},
+				)
+			}
+			return visitor.Continue
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			ttype := context.Type()
+			if ttype != nil && !IsCompositeType(ttype) {
+				nodeName := ""
+				if node.Name != nil {
+					nodeName = node.Name.Value
+				}
+				reportError(
+					context,
+					fmt.Sprintf(`Fragment "%v" cannot condition on non composite type "%v".`, nodeName, printer.Print(node.TypeCondition)),
+					[]ast.Node{node.TypeCondition},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -436,86 +407,76 @@ func unknownDirectiveArgMessage(argName string, directiveName string, suggestedA
 // A GraphQL field is only valid if all supplied arguments are defined by
 // that field.
 func KnownArgumentNamesRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Argument: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					var action = visitor.ActionNoChange
-					if node, ok := p.Node.(*ast.Argument); ok {
-						var argumentOf ast.Node
-						if len(p.Ancestors) > 0 {
-							argumentOf = p.Ancestors[len(p.Ancestors)-1]
-						}
-						if argumentOf == nil {
-							return action, nil
-						}
-						//  verify node, if the node's name exists in Arguments{Field, Directive}
-						var (
-							fieldArgDef    *Argument
-							fieldDef       = context.FieldDef()
-							directive      = context.Directive()
-							argNames       []string
-							parentTypeName string
-						)
-						switch argumentOf.GetKind() {
-						case kinds.Field:
-							// get field definition
-							if fieldDef == nil {
-								return action, nil
-							}
-							for _, arg := range fieldDef.Args {
-								if arg.Name() == node.Name.Value {
-									fieldArgDef = arg
-									break
-								}
-								argNames = append(argNames, arg.Name())
-							}
-							if fieldArgDef == nil {
-								parentType := context.ParentType()
-								if parentType != nil {
-									parentTypeName = parentType.Name()
-								}
-								reportError(
-									context,
-									unknownArgMessage(
-										node.Name.Value,
-										fieldDef.Name,
-										parentTypeName, suggestionList(node.Name.Value, argNames),
-									),
-									[]ast.Node{node},
-								)
-							}
-						case kinds.Directive:
-							if directive = context.Directive(); directive == nil {
-								return action, nil
-							}
-							for _, arg := range directive.Args {
-								if arg.Name() == node.Name.Value {
-									fieldArgDef = arg
-									break
-								}
-								argNames = append(argNames, arg.Name())
-							}
-							if fieldArgDef == nil {
-								reportError(
-									context,
-									unknownDirectiveArgMessage(
-										node.Name.Value,
-										directive.Name,
-										suggestionList(node.Name.Value, argNames),
-									),
-									[]ast.Node{node},
-								)
-							}
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterArgument: func(node *ast.Argument, c *visitor.Cursor) visitor.Action {
+			argumentOf := c.Parent()
+			if argumentOf == nil {
+				return visitor.Continue
+			}
+			//  verify node, if the node's name exists in Arguments{Field, Directive}
+			var (
+				fieldArgDef    *Argument
+				fieldDef       = context.FieldDef()
+				directive      = context.Directive()
+				argNames       []string
+				parentTypeName string
+			)
+			switch argumentOf.GetKind() {
+			case kinds.Field:
+				// get field definition
+				if fieldDef == nil {
+					return visitor.Continue
+				}
+				for _, arg := range fieldDef.Args {
+					if arg.Name() == node.Name.Value {
+						fieldArgDef = arg
+						break
+					}
+					argNames = append(argNames, arg.Name())
+				}
+				if fieldArgDef == nil {
+					parentType := context.ParentType()
+					if parentType != nil {
+						parentTypeName = parentType.Name()
+					}
+					reportError(
+						context,
+						unknownArgMessage(
+							node.Name.Value,
+							fieldDef.Name,
+							parentTypeName, suggestionList(node.Name.Value, argNames),
+						),
+						[]ast.Node{node},
+					)
+				}
+			case kinds.Directive:
+				if directive = context.Directive(); directive == nil {
+					return visitor.Continue
+				}
+				for _, arg := range directive.Args {
+					if arg.Name() == node.Name.Value {
+						fieldArgDef = arg
+						break
 					}
-					return action, nil
-				},
-			},
+					argNames = append(argNames, arg.Name())
+				}
+				if fieldArgDef == nil {
+					reportError(
+						context,
+						unknownDirectiveArgMessage(
+							node.Name.Value,
+							directive.Name,
+							suggestionList(node.Name.Value, argNames),
+						),
+						[]ast.Node{node},
+					)
+				}
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -528,65 +489,58 @@ func MisplaceDirectiveMessage(directiveName string, location string) string {
 // A GraphQL document is only valid if all `@directives` are known by the
 // schema and legally positioned.
 func KnownDirectivesRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Directive: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					var action = visitor.ActionNoChange
-					var result interface{}
-					if node, ok := p.Node.(*ast.Directive); ok {
-
-						nodeName := ""
-						if node.Name != nil {
-							nodeName = node.Name.Value
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterDirective: func(node *ast.Directive, c *visitor.Cursor) visitor.Action {
 
-						var directiveDef *Directive
-						for _, def := range context.Schema().Directives() {
-							if def.Name == nodeName {
-								directiveDef = def
-							}
-						}
-						if directiveDef == nil {
-							return reportError(
-								context,
-								fmt.Sprintf(`Unknown directive "%v".`, nodeName),
-								[]ast.Node{node},
-							)
-						}
+			nodeName := ""
+			if node.Name != nil {
+				nodeName = node.Name.Value
+			}
+
+			var directiveDef *Directive
+			for _, def := range context.Schema().Directives() {
+				if def.Name == nodeName {
+					directiveDef = def
+				}
+			}
+			if directiveDef == nil {
+				reportError(
+					context,
+					fmt.Sprintf(`Unknown directive "%v".`, nodeName),
+					[]ast.Node{node},
+				)
+				return visitor.Continue
+			}
 
-						candidateLocation := getDirectiveLocationForASTPath(p.Ancestors)
+			candidateLocation := getDirectiveLocationForASTPath(c.Ancestors())
 
-						directiveHasLocation := false
-						for _, loc := range directiveDef.Locations {
-							if loc == candidateLocation {
-								directiveHasLocation = true
-								break
-							}
-						}
+			directiveHasLocation := false
+			for _, loc := range directiveDef.Locations {
+				if loc == candidateLocation {
+					directiveHasLocation = true
+					break
+				}
+			}
 
-						if candidateLocation == "" {
-							reportError(
-								context,
-								MisplaceDirectiveMessage(nodeName, node.GetKind()),
-								[]ast.Node{node},
-							)
-						} else if !directiveHasLocation {
-							reportError(
-								context,
-								MisplaceDirectiveMessage(nodeName, candidateLocation),
-								[]ast.Node{node},
-							)
-						}
+			if candidateLocation == "" {
+				reportError(
+					context,
+					MisplaceDirectiveMessage(nodeName, node.GetKind()),
+					[]ast.Node{node},
+				)
+			} else if !directiveHasLocation {
+				reportError(
+					context,
+					MisplaceDirectiveMessage(nodeName, candidateLocation),
+					[]ast.Node{node},
+				)
+			}
 
-					}
-					return action, result
-				},
-			},
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -623,7 +577,10 @@ func getDirectiveLocationForASTPath(ancestors []ast.Node) string {
 	if kind == kinds.FragmentDefinition {
 		return DirectiveLocationFragmentDefinition
 	}
-	if kind == kinds.SchemaDefinition {
+	if kind == kinds.VariableDefinition {
+		return DirectiveLocationVariableDefinition
+	}
+	if kind == kinds.SchemaDefinition || kind == kinds.SchemaExtensionDefinition {
 		return DirectiveLocationSchema
 	}
 	if kind == kinds.ScalarDefinition {
@@ -652,8 +609,8 @@ func getDirectiveLocationForASTPath(ancestors []ast.Node) string {
 	}
 	if kind == kinds.InputValueDefinition {
 		var parentNode ast.Node
-		if len(ancestors) >= 3 {
-			parentNode = ancestors[len(ancestors)-3]
+		if len(ancestors) >= 2 {
+			parentNode = ancestors[len(ancestors)-2]
 		}
 		if parentNode.GetKind() == kinds.InputObjectDefinition {
 			return DirectiveLocationInputFieldDefinition
@@ -669,35 +626,27 @@ func getDirectiveLocationForASTPath(ancestors []ast.Node) string {
 // A GraphQL document is only valid if all `...Fragment` fragment spreads refer
 // to fragments defined in the same document.
 func KnownFragmentNamesRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.FragmentSpread: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					var action = visitor.ActionNoChange
-					var result interface{}
-					if node, ok := p.Node.(*ast.FragmentSpread); ok {
-
-						fragmentName := ""
-						if node.Name != nil {
-							fragmentName = node.Name.Value
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
 
-						fragment := context.Fragment(fragmentName)
-						if fragment == nil {
-							reportError(
-								context,
-								fmt.Sprintf(`Unknown fragment "%v".`, fragmentName),
-								[]ast.Node{node.Name},
-							)
-						}
-					}
-					return action, result
-				},
-			},
+			fragmentName := ""
+			if node.Name != nil {
+				fragmentName = node.Name.Value
+			}
+
+			fragment := context.Fragment(fragmentName)
+			if fragment == nil {
+				reportError(
+					context,
+					fmt.Sprintf(`Unknown fragment "%v".`, fragmentName),
+					[]ast.Node{node.Name},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -715,56 +664,42 @@ func unknownTypeMessage(typeName string, suggestedTypes []string) string {
 // A GraphQL document is only valid if referenced types (specifically
 // variable definitions and fragment conditions) are defined by the type schema.
 func KnownTypeNamesRule(context *ValidationContext) *ValidationRuleInstance {
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.ObjectDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.InterfaceDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.UnionDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.InputObjectDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.Named: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.Named); ok {
-						typeNameValue := ""
-						typeName := node.Name
-						if typeName != nil {
-							typeNameValue = typeName.Value
-						}
-						ttype := context.Schema().Type(typeNameValue)
-						if ttype == nil {
-							suggestedTypes := []string{}
-							for key := range context.Schema().TypeMap() {
-								suggestedTypes = append(suggestedTypes, key)
-							}
-							reportError(
-								context,
-								unknownTypeMessage(typeNameValue, suggestionList(typeNameValue, suggestedTypes)),
-								[]ast.Node{node},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterObjectDefinition: func(node *ast.ObjectDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterInterfaceDefinition: func(node *ast.InterfaceDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterUnionDefinition: func(node *ast.UnionDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterInputObjectDefinition: func(node *ast.InputObjectDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterNamed: func(node *ast.Named, c *visitor.Cursor) visitor.Action {
+			typeNameValue := ""
+			typeName := node.Name
+			if typeName != nil {
+				typeNameValue = typeName.Value
+			}
+			ttype := context.Schema().Type(typeNameValue)
+			if ttype == nil {
+				suggestedTypes := []string{}
+				for key := range context.Schema().TypeMap() {
+					suggestedTypes = append(suggestedTypes, key)
+				}
+				reportError(
+					context,
+					unknownTypeMessage(typeNameValue, suggestionList(typeNameValue, suggestedTypes)),
+					[]ast.Node{node},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -774,39 +709,29 @@ func KnownTypeNamesRule(context *ValidationContext) *ValidationRuleInstance {
 // (the query short-hand) that it contains only that one operation definition.
 func LoneAnonymousOperationRule(context *ValidationContext) *ValidationRuleInstance {
 	var operationCount = 0
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Document: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.Document); ok {
-						operationCount = 0
-						for _, definition := range node.Definitions {
-							if definition.GetKind() == kinds.OperationDefinition {
-								operationCount++
-							}
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.OperationDefinition); ok {
-						if node.Name == nil && operationCount > 1 {
-							reportError(
-								context,
-								`This anonymous operation must be the only defined operation.`,
-								[]ast.Node{node},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterDocument: func(node *ast.Document, c *visitor.Cursor) visitor.Action {
+			operationCount = 0
+			for _, definition := range node.Definitions {
+				if definition.GetKind() == kinds.OperationDefinition {
+					operationCount++
+				}
+			}
+			return visitor.Continue
+		},
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			if node.Name == nil && operationCount > 1 {
+				reportError(
+					context,
+					`This anonymous operation must be the only defined operation.`,
+					[]ast.Node{node},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -896,31 +821,23 @@ func NoFragmentCyclesRule(context *ValidationContext) *ValidationRuleInstance {
 
 	}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.FragmentDefinition); ok && node != nil {
-						nodeName := ""
-						if node.Name != nil {
-							nodeName = node.Name.Value
-						}
-						if _, ok := visitedFrags[nodeName]; !ok {
-							detectCycleRecursive(node)
-						}
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			nodeName := ""
+			if node.Name != nil {
+				nodeName = node.Name.Value
+			}
+			if _, ok := visitedFrags[nodeName]; !ok {
+				detectCycleRecursive(node)
+			}
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -938,60 +855,50 @@ func UndefinedVarMessage(varName string, opName string) string {
 func NoUndefinedVariablesRule(context *ValidationContext) *ValidationRuleInstance {
 	var variableNameDefined = map[string]bool{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
-					variableNameDefined = map[string]bool{}
-					return visitor.ActionNoChange, nil
-				},
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if operation, ok := p.Node.(*ast.OperationDefinition); ok && operation != nil {
-						usages := context.RecursiveVariableUsages(operation)
-
-						for _, usage := range usages {
-							if usage == nil {
-								continue
-							}
-							if usage.Node == nil {
-								continue
-							}
-							varName := ""
-							if usage.Node.Name != nil {
-								varName = usage.Node.Name.Value
-							}
-							opName := ""
-							if operation.Name != nil {
-								opName = operation.Name.Value
-							}
-							if res, ok := variableNameDefined[varName]; !ok || !res {
-								reportError(
-									context,
-									UndefinedVarMessage(varName, opName),
-									[]ast.Node{usage.Node, operation},
-								)
-							}
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.VariableDefinition); ok && node != nil {
-						variableName := ""
-						if node.Variable != nil && node.Variable.Name != nil {
-							variableName = node.Variable.Name.Value
-						}
-						variableNameDefined[variableName] = true
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			variableNameDefined = map[string]bool{}
+			return visitor.Continue
+		},
+		LeaveOperationDefinition: func(operation *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			usages := context.RecursiveVariableUsages(operation)
+
+			for _, usage := range usages {
+				if usage == nil {
+					continue
+				}
+				if usage.Node == nil {
+					continue
+				}
+				varName := ""
+				if usage.Node.Name != nil {
+					varName = usage.Node.Name.Value
+				}
+				opName := ""
+				if operation.Name != nil {
+					opName = operation.Name.Value
+				}
+				if res, ok := variableNameDefined[varName]; !ok || !res {
+					reportError(
+						context,
+						UndefinedVarMessage(varName, opName),
+						[]ast.Node{usage.Node, operation},
+					)
+				}
+			}
+			return visitor.Continue
+		},
+		EnterVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			variableName := ""
+			if node.Variable != nil && node.Variable.Name != nil {
+				variableName = node.Variable.Name.Value
+			}
+			variableNameDefined[variableName] = true
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1004,60 +911,48 @@ func NoUnusedFragmentsRule(context *ValidationContext) *ValidationRuleInstance {
 	var fragmentDefs = []*ast.FragmentDefinition{}
 	var operationDefs = []*ast.OperationDefinition{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.OperationDefinition); ok && node != nil {
-						operationDefs = append(operationDefs, node)
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.FragmentDefinition); ok && node != nil {
-						fragmentDefs = append(fragmentDefs, node)
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.Document: {
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					fragmentNameUsed := map[string]bool{}
-					for _, operation := range operationDefs {
-						fragments := context.RecursivelyReferencedFragments(operation)
-						for _, fragment := range fragments {
-							fragName := ""
-							if fragment.Name != nil {
-								fragName = fragment.Name.Value
-							}
-							fragmentNameUsed[fragName] = true
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			operationDefs = append(operationDefs, node)
+			return visitor.Skip
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			fragmentDefs = append(fragmentDefs, node)
+			return visitor.Skip
+		},
+		LeaveDocument: func(node *ast.Document, c *visitor.Cursor) visitor.Action {
+			fragmentNameUsed := map[string]bool{}
+			for _, operation := range operationDefs {
+				fragments := context.RecursivelyReferencedFragments(operation)
+				for _, fragment := range fragments {
+					fragName := ""
+					if fragment.Name != nil {
+						fragName = fragment.Name.Value
 					}
+					fragmentNameUsed[fragName] = true
+				}
+			}
 
-					for _, def := range fragmentDefs {
-						defName := ""
-						if def.Name != nil {
-							defName = def.Name.Value
-						}
+			for _, def := range fragmentDefs {
+				defName := ""
+				if def.Name != nil {
+					defName = def.Name.Value
+				}
 
-						isFragNameUsed, ok := fragmentNameUsed[defName]
-						if !ok || isFragNameUsed != true {
-							reportError(
-								context,
-								fmt.Sprintf(`Fragment "%v" is never used.`, defName),
-								[]ast.Node{def},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+				isFragNameUsed, ok := fragmentNameUsed[defName]
+				if !ok || isFragNameUsed != true {
+					reportError(
+						context,
+						fmt.Sprintf(`Fragment "%v" is never used.`, defName),
+						[]ast.Node{def},
+					)
+				}
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1076,65 +971,115 @@ func NoUnusedVariablesRule(context *ValidationContext) *ValidationRuleInstance {
 
 	var variableDefs = []*ast.VariableDefinition{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
-					variableDefs = []*ast.VariableDefinition{}
-					return visitor.ActionNoChange, nil
-				},
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if operation, ok := p.Node.(*ast.OperationDefinition); ok && operation != nil {
-						variableNameUsed := map[string]bool{}
-						usages := context.RecursiveVariableUsages(operation)
-
-						for _, usage := range usages {
-							varName := ""
-							if usage != nil && usage.Node != nil && usage.Node.Name != nil {
-								varName = usage.Node.Name.Value
-							}
-							if varName != "" {
-								variableNameUsed[varName] = true
-							}
-						}
-						for _, variableDef := range variableDefs {
-							variableName := ""
-							if variableDef != nil && variableDef.Variable != nil && variableDef.Variable.Name != nil {
-								variableName = variableDef.Variable.Name.Value
-							}
-							opName := ""
-							if operation.Name != nil {
-								opName = operation.Name.Value
-							}
-							if res, ok := variableNameUsed[variableName]; !ok || !res {
-								reportError(
-									context,
-									UnusedVariableMessage(variableName, opName),
-									[]ast.Node{variableDef},
-								)
-							}
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			variableDefs = []*ast.VariableDefinition{}
+			return visitor.Continue
+		},
+		LeaveOperationDefinition: func(operation *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			variableNameUsed := map[string]bool{}
+			usages := context.RecursiveVariableUsages(operation)
+
+			for _, usage := range usages {
+				varName := ""
+				if usage != nil && usage.Node != nil && usage.Node.Name != nil {
+					varName = usage.Node.Name.Value
+				}
+				if varName != "" {
+					variableNameUsed[varName] = true
+				}
+			}
+			for _, variableDef := range variableDefs {
+				variableName := ""
+				if variableDef != nil && variableDef.Variable != nil && variableDef.Variable.Name != nil {
+					variableName = variableDef.Variable.Name.Value
+				}
+				opName := ""
+				if operation.Name != nil {
+					opName = operation.Name.Value
+				}
+				if res, ok := variableNameUsed[variableName]; !ok || !res {
+					reportError(
+						context,
+						UnusedVariableMessage(variableName, opName),
+						[]ast.Node{variableDef},
+					)
+				}
+			}
 
-					}
+			return visitor.Continue
+		},
+		EnterVariableDefinition: func(def *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			variableDefs = append(variableDefs, def)
+			return visitor.Continue
+		},
+	}
+	return &ValidationRuleInstance{
+		Visitor: ruleVisitor,
+	}
+}
 
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if def, ok := p.Node.(*ast.VariableDefinition); ok && def != nil {
-						variableDefs = append(variableDefs, def)
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+// NullabilityDesignatorsMatchListDepthRule Nullability designators match list depth
+//
+// A GraphQL document is only valid if the brackets of the client controlled
+// nullability designators of its fields have as many list dimensions as the
+// types of the fields.
+func NullabilityDesignatorsMatchListDepthRule(context *ValidationContext) *ValidationRuleInstance {
+	ruleVisitor := &visitor.Visitor{
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			ttype := context.Type()
+			designatorDepth := nullabilityListDepth(node.Nullability)
+			if ttype == nil || designatorDepth == 0 {
+				return visitor.Continue
+			}
+			typeDepth := 0
+			for t := Type(ttype); ; typeDepth++ {
+				if nonNull, ok := t.(*NonNull); ok {
+					t = nonNull.OfType
+				}
+				list, ok := t.(*List)
+				if !ok {
+					break
+				}
+				t = list.OfType
+			}
+			if designatorDepth != typeDepth {
+				nodeName := ""
+				if node.Name != nil {
+					nodeName = node.Name.Value
+				}
+				reportError(
+					context,
+					fmt.Sprintf(`Nullability designator of field "%v" has %v list dimensions but its type "%v" has %v.`,
+						nodeName, designatorDepth, ttype, typeDepth),
+					[]ast.Node{node.Nullability},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
+// nullabilityListDepth returns the number of brackets of nullability.
+func nullabilityListDepth(nullability ast.Nullability) int {
+	switch nullability := nullability.(type) {
+	case *ast.RequiredDesignator:
+		if nullability.Element != nil {
+			return nullabilityListDepth(nullability.Element)
+		}
+	case *ast.OptionalDesignator:
+		if nullability.Element != nil {
+			return nullabilityListDepth(nullability.Element)
+		}
+	case *ast.ListNullability:
+		return 1 + nullabilityListDepth(nullability.Element)
+	}
+	return 0
+}
+
 func getFragmentType(context *ValidationContext, name string) Type {
 	frag := context.Fragment(name)
 	if frag == nil {
@@ -1193,51 +1138,41 @@ func doTypesOverlap(schema *Schema, t1 Type, t2 Type) bool {
 // and possible types which pass the type condition.
 func PossibleFragmentSpreadsRule(context *ValidationContext) *ValidationRuleInstance {
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.InlineFragment: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.InlineFragment); ok && node != nil {
-						fragType := context.Type()
-						parentType, _ := context.ParentType().(Type)
-
-						if fragType != nil && parentType != nil && !doTypesOverlap(context.Schema(), fragType, parentType) {
-							reportError(
-								context,
-								fmt.Sprintf(`Fragment cannot be spread here as objects of `+
-									`type "%v" can never be of type "%v".`, parentType, fragType),
-								[]ast.Node{node},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.FragmentSpread: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.FragmentSpread); ok && node != nil {
-						fragName := ""
-						if node.Name != nil {
-							fragName = node.Name.Value
-						}
-						fragType := getFragmentType(context, fragName)
-						parentType, _ := context.ParentType().(Type)
-						if fragType != nil && parentType != nil && !doTypesOverlap(context.Schema(), fragType, parentType) {
-							reportError(
-								context,
-								fmt.Sprintf(`Fragment "%v" cannot be spread here as objects of `+
-									`type "%v" can never be of type "%v".`, fragName, parentType, fragType),
-								[]ast.Node{node},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterInlineFragment: func(node *ast.InlineFragment, c *visitor.Cursor) visitor.Action {
+			fragType := context.Type()
+			parentType, _ := context.ParentType().(Type)
+
+			if fragType != nil && parentType != nil && !doTypesOverlap(context.Schema(), fragType, parentType) {
+				reportError(
+					context,
+					fmt.Sprintf(`Fragment cannot be spread here as objects of `+
+						`type "%v" can never be of type "%v".`, parentType, fragType),
+					[]ast.Node{node},
+				)
+			}
+			return visitor.Continue
+		},
+		EnterFragmentSpread: func(node *ast.FragmentSpread, c *visitor.Cursor) visitor.Action {
+			fragName := ""
+			if node.Name != nil {
+				fragName = node.Name.Value
+			}
+			fragType := getFragmentType(context, fragName)
+			parentType, _ := context.ParentType().(Type)
+			if fragType != nil && parentType != nil && !doTypesOverlap(context.Schema(), fragType, parentType) {
+				reportError(
+					context,
+					fmt.Sprintf(`Fragment "%v" cannot be spread here as objects of `+
+						`type "%v" can never be of type "%v".`, fragName, parentType, fragType),
+					[]ast.Node{node},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1247,93 +1182,82 @@ func PossibleFragmentSpreadsRule(context *ValidationContext) *ValidationRuleInst
 // have been provided.
 func ProvidedNonNullArgumentsRule(context *ValidationContext) *ValidationRuleInstance {
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Field: {
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					// Validate on leave to allow for deeper errors to appear first.
-					if fieldAST, ok := p.Node.(*ast.Field); ok && fieldAST != nil {
-						fieldDef := context.FieldDef()
-						if fieldDef == nil {
-							return visitor.ActionSkip, nil
-						}
+	ruleVisitor := &visitor.Visitor{
+		LeaveField: func(fieldAST *ast.Field, c *visitor.Cursor) visitor.Action {
+			// Validate on leave to allow for deeper errors to appear first.
+			fieldDef := context.FieldDef()
+			if fieldDef == nil {
+				return visitor.Skip
+			}
 
-						argASTs := fieldAST.Arguments
+			argASTs := fieldAST.Arguments
 
-						argASTMap := map[string]*ast.Argument{}
-						for _, arg := range argASTs {
-							name := ""
-							if arg.Name != nil {
-								name = arg.Name.Value
-							}
-							argASTMap[name] = arg
-						}
-						for _, argDef := range fieldDef.Args {
-							argAST, _ := argASTMap[argDef.Name()]
-							if argAST == nil {
-								if argDefType, ok := argDef.Type.(*NonNull); ok {
-									fieldName := ""
-									if fieldAST.Name != nil {
-										fieldName = fieldAST.Name.Value
-									}
-									reportError(
-										context,
-										fmt.Sprintf(`Field "%v" argument "%v" of type "%v" `+
-											`is required but not provided.`, fieldName, argDef.Name(), argDefType),
-										[]ast.Node{fieldAST},
-									)
-								}
-							}
+			argASTMap := map[string]*ast.Argument{}
+			for _, arg := range argASTs {
+				name := ""
+				if arg.Name != nil {
+					name = arg.Name.Value
+				}
+				argASTMap[name] = arg
+			}
+			for _, argDef := range fieldDef.Args {
+				argAST, _ := argASTMap[argDef.Name()]
+				if argAST == nil {
+					if argDefType, ok := argDef.Type.(*NonNull); ok {
+						fieldName := ""
+						if fieldAST.Name != nil {
+							fieldName = fieldAST.Name.Value
 						}
+						reportError(
+							context,
+							fmt.Sprintf(`Field "%v" argument "%v" of type "%v" `+
+								`is required but not provided.`, fieldName, argDef.Name(), argDefType),
+							[]ast.Node{fieldAST},
+						)
 					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.Directive: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					// Validate on leave to allow for deeper errors to appear first.
-
-					if directiveAST, ok := p.Node.(*ast.Directive); ok && directiveAST != nil {
-						directiveDef := context.Directive()
-						if directiveDef == nil {
-							return visitor.ActionSkip, nil
-						}
-						argASTs := directiveAST.Arguments
-
-						argASTMap := map[string]*ast.Argument{}
-						for _, arg := range argASTs {
-							name := ""
-							if arg.Name != nil {
-								name = arg.Name.Value
-							}
-							argASTMap[name] = arg
-						}
+				}
+			}
+			return visitor.Continue
+		},
+		EnterDirective: func(directiveAST *ast.Directive, c *visitor.Cursor) visitor.Action {
+			// Validate on leave to allow for deeper errors to appear first.
+			directiveDef := context.Directive()
+			if directiveDef == nil {
+				return visitor.Skip
+			}
+			argASTs := directiveAST.Arguments
+
+			argASTMap := map[string]*ast.Argument{}
+			for _, arg := range argASTs {
+				name := ""
+				if arg.Name != nil {
+					name = arg.Name.Value
+				}
+				argASTMap[name] = arg
+			}
 
-						for _, argDef := range directiveDef.Args {
-							argAST, _ := argASTMap[argDef.Name()]
-							if argAST == nil {
-								if argDefType, ok := argDef.Type.(*NonNull); ok {
-									directiveName := ""
-									if directiveAST.Name != nil {
-										directiveName = directiveAST.Name.Value
-									}
-									reportError(
-										context,
-										fmt.Sprintf(`Directive "@%v" argument "%v" of type `+
-											`"%v" is required but not provided.`, directiveName, argDef.Name(), argDefType),
-										[]ast.Node{directiveAST},
-									)
-								}
-							}
+			for _, argDef := range directiveDef.Args {
+				argAST, _ := argASTMap[argDef.Name()]
+				if argAST == nil {
+					if argDefType, ok := argDef.Type.(*NonNull); ok {
+						directiveName := ""
+						if directiveAST.Name != nil {
+							directiveName = directiveAST.Name.Value
 						}
+						reportError(
+							context,
+							fmt.Sprintf(`Directive "@%v" argument "%v" of type `+
+								`"%v" is required but not provided.`, directiveName, argDef.Name(), argDefType),
+							[]ast.Node{directiveAST},
+						)
 					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+				}
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1343,41 +1267,35 @@ func ProvidedNonNullArgumentsRule(context *ValidationContext) *ValidationRuleIns
 // sub selections) are of scalar or enum types.
 func ScalarLeafsRule(context *ValidationContext) *ValidationRuleInstance {
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Field: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.Field); ok && node != nil {
-						nodeName := ""
-						if node.Name != nil {
-							nodeName = node.Name.Value
-						}
-						ttype := context.Type()
-						if ttype != nil {
-							if IsLeafType(ttype) {
-								if node.SelectionSet != nil {
-									reportError(
-										context,
-										fmt.Sprintf(`Field "%v" of type "%v" must not have a sub selection.`, nodeName, ttype),
-										[]ast.Node{node.SelectionSet},
-									)
-								}
-							} else if node.SelectionSet == nil {
-								reportError(
-									context,
-									fmt.Sprintf(`Field "%v" of type "%v" must have a sub selection.`, nodeName, ttype),
-									[]ast.Node{node},
-								)
-							}
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			nodeName := ""
+			if node.Name != nil {
+				nodeName = node.Name.Value
+			}
+			ttype := context.Type()
+			if ttype != nil {
+				if IsLeafType(ttype) {
+					if node.SelectionSet != nil {
+						reportError(
+							context,
+							fmt.Sprintf(`Field "%v" of type "%v" must not have a sub selection.`, nodeName, ttype),
+							[]ast.Node{node.SelectionSet},
+						)
 					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+				} else if node.SelectionSet == nil {
+					reportError(
+						context,
+						fmt.Sprintf(`Field "%v" of type "%v" must have a sub selection.`, nodeName, ttype),
+						[]ast.Node{node},
+					)
+				}
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1388,44 +1306,34 @@ func ScalarLeafsRule(context *ValidationContext) *ValidationRuleInstance {
 func UniqueArgumentNamesRule(context *ValidationContext) *ValidationRuleInstance {
 	knownArgNames := map[string]*ast.Name{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.Field: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					knownArgNames = map[string]*ast.Name{}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.Directive: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					knownArgNames = map[string]*ast.Name{}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.Argument: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.Argument); ok {
-						argName := ""
-						if node.Name != nil {
-							argName = node.Name.Value
-						}
-						if nameAST, ok := knownArgNames[argName]; ok {
-							reportError(
-								context,
-								fmt.Sprintf(`There can be only one argument named "%v".`, argName),
-								[]ast.Node{nameAST, node.Name},
-							)
-						} else {
-							knownArgNames[argName] = node.Name
-						}
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterField: func(node *ast.Field, c *visitor.Cursor) visitor.Action {
+			knownArgNames = map[string]*ast.Name{}
+			return visitor.Continue
+		},
+		EnterDirective: func(node *ast.Directive, c *visitor.Cursor) visitor.Action {
+			knownArgNames = map[string]*ast.Name{}
+			return visitor.Continue
+		},
+		EnterArgument: func(node *ast.Argument, c *visitor.Cursor) visitor.Action {
+			argName := ""
+			if node.Name != nil {
+				argName = node.Name.Value
+			}
+			if nameAST, ok := knownArgNames[argName]; ok {
+				reportError(
+					context,
+					fmt.Sprintf(`There can be only one argument named "%v".`, argName),
+					[]ast.Node{nameAST, node.Name},
+				)
+			} else {
+				knownArgNames[argName] = node.Name
+			}
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1435,37 +1343,29 @@ func UniqueArgumentNamesRule(context *ValidationContext) *ValidationRuleInstance
 func UniqueFragmentNamesRule(context *ValidationContext) *ValidationRuleInstance {
 	knownFragmentNames := map[string]*ast.Name{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.FragmentDefinition); ok && node != nil {
-						fragmentName := ""
-						if node.Name != nil {
-							fragmentName = node.Name.Value
-						}
-						if nameAST, ok := knownFragmentNames[fragmentName]; ok {
-							reportError(
-								context,
-								fmt.Sprintf(`There can only be one fragment named "%v".`, fragmentName),
-								[]ast.Node{nameAST, node.Name},
-							)
-						} else {
-							knownFragmentNames[fragmentName] = node.Name
-						}
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			fragmentName := ""
+			if node.Name != nil {
+				fragmentName = node.Name.Value
+			}
+			if nameAST, ok := knownFragmentNames[fragmentName]; ok {
+				reportError(
+					context,
+					fmt.Sprintf(`There can only be one fragment named "%v".`, fragmentName),
+					[]ast.Node{nameAST, node.Name},
+				)
+			} else {
+				knownFragmentNames[fragmentName] = node.Name
+			}
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1477,45 +1377,37 @@ func UniqueInputFieldNamesRule(context *ValidationContext) *ValidationRuleInstan
 	knownNameStack := []map[string]*ast.Name{}
 	knownNames := map[string]*ast.Name{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.ObjectValue: {
-				Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
-					knownNameStack = append(knownNameStack, knownNames)
-					knownNames = map[string]*ast.Name{}
-					return visitor.ActionNoChange, nil
-				},
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					// pop
-					knownNames, knownNameStack = knownNameStack[len(knownNameStack)-1], knownNameStack[:len(knownNameStack)-1]
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.ObjectField: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.ObjectField); ok {
-						fieldName := ""
-						if node.Name != nil {
-							fieldName = node.Name.Value
-						}
-						if knownNameAST, ok := knownNames[fieldName]; ok {
-							reportError(
-								context,
-								fmt.Sprintf(`There can be only one input field named "%v".`, fieldName),
-								[]ast.Node{knownNameAST, node.Name},
-							)
-						} else {
-							knownNames[fieldName] = node.Name
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterObjectValue: func(node *ast.ObjectValue, c *visitor.Cursor) visitor.Action {
+			knownNameStack = append(knownNameStack, knownNames)
+			knownNames = map[string]*ast.Name{}
+			return visitor.Continue
+		},
+		LeaveObjectValue: func(node *ast.ObjectValue, c *visitor.Cursor) visitor.Action {
+			// pop
+			knownNames, knownNameStack = knownNameStack[len(knownNameStack)-1], knownNameStack[:len(knownNameStack)-1]
+			return visitor.Continue
+		},
+		EnterObjectField: func(node *ast.ObjectField, c *visitor.Cursor) visitor.Action {
+			fieldName := ""
+			if node.Name != nil {
+				fieldName = node.Name.Value
+			}
+			if knownNameAST, ok := knownNames[fieldName]; ok {
+				reportError(
+					context,
+					fmt.Sprintf(`There can be only one input field named "%v".`, fieldName),
+					[]ast.Node{knownNameAST, node.Name},
+				)
+			} else {
+				knownNames[fieldName] = node.Name
+			}
 
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1525,41 +1417,33 @@ func UniqueInputFieldNamesRule(context *ValidationContext) *ValidationRuleInstan
 func UniqueOperationNamesRule(context *ValidationContext) *ValidationRuleInstance {
 	knownOperationNames := make(map[string]ast.Node)
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.OperationDefinition); ok && node != nil {
-						operationName := ""
-						if node.Name != nil {
-							operationName = node.Name.Value
-						}
-						var errNode ast.Node = node
-						if node.Name != nil {
-							errNode = node.Name
-						}
-						if nameAST, ok := knownOperationNames[operationName]; ok {
-							reportError(
-								context,
-								fmt.Sprintf(`There can only be one operation named "%v".`, operationName),
-								[]ast.Node{nameAST, errNode},
-							)
-						} else {
-							knownOperationNames[operationName] = errNode
-						}
-					}
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.FragmentDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			operationName := ""
+			if node.Name != nil {
+				operationName = node.Name.Value
+			}
+			var errNode ast.Node = node
+			if node.Name != nil {
+				errNode = node.Name
+			}
+			if nameAST, ok := knownOperationNames[operationName]; ok {
+				reportError(
+					context,
+					fmt.Sprintf(`There can only be one operation named "%v".`, operationName),
+					[]ast.Node{nameAST, errNode},
+				)
+			} else {
+				knownOperationNames[operationName] = errNode
+			}
+			return visitor.Skip
+		},
+		EnterFragmentDefinition: func(node *ast.FragmentDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1569,42 +1453,32 @@ func UniqueOperationNamesRule(context *ValidationContext) *ValidationRuleInstanc
 func UniqueVariableNamesRule(context *ValidationContext) *ValidationRuleInstance {
 	knownVariableNames := map[string]*ast.Name{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.OperationDefinition); ok && node != nil {
-						knownVariableNames = map[string]*ast.Name{}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.VariableDefinition); ok && node != nil {
-						variableName := ""
-						var variableNameAST *ast.Name
-						if node.Variable != nil && node.Variable.Name != nil {
-							variableNameAST = node.Variable.Name
-							variableName = node.Variable.Name.Value
-						}
-						if nameAST, ok := knownVariableNames[variableName]; ok {
-							reportError(
-								context,
-								fmt.Sprintf(`There can only be one variable named "%v".`, variableName),
-								[]ast.Node{nameAST, variableNameAST},
-							)
-						} else {
-							knownVariableNames[variableName] = variableNameAST
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			knownVariableNames = map[string]*ast.Name{}
+			return visitor.Continue
+		},
+		EnterVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			variableName := ""
+			var variableNameAST *ast.Name
+			if node.Variable != nil && node.Variable.Name != nil {
+				variableNameAST = node.Variable.Name
+				variableName = node.Variable.Name.Value
+			}
+			if nameAST, ok := knownVariableNames[variableName]; ok {
+				reportError(
+					context,
+					fmt.Sprintf(`There can only be one variable named "%v".`, variableName),
+					[]ast.Node{nameAST, variableNameAST},
+				)
+			} else {
+				knownVariableNames[variableName] = variableNameAST
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1614,34 +1488,28 @@ func UniqueVariableNamesRule(context *ValidationContext) *ValidationRuleInstance
 // input types (scalar, enum, or input object).
 func VariablesAreInputTypesRule(context *ValidationContext) *ValidationRuleInstance {
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.VariableDefinition); ok && node != nil {
-						ttype, _ := typeFromAST(*context.Schema(), node.Type)
-
-						// If the variable type is not an input type, return an error.
-						if ttype != nil && !IsInputType(ttype) {
-							variableName := ""
-							if node.Variable != nil && node.Variable.Name != nil {
-								variableName = node.Variable.Name.Value
-							}
-							reportError(
-								context,
-								fmt.Sprintf(`Variable "$%v" cannot be non-input type "%v".`,
-									variableName, printer.Print(node.Type)),
-								[]ast.Node{node.Type},
-							)
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			ttype, _ := typeFromAST(*context.Schema(), node.Type)
+
+			// If the variable type is not an input type, return an error.
+			if ttype != nil && !IsInputType(ttype) {
+				variableName := ""
+				if node.Variable != nil && node.Variable.Name != nil {
+					variableName = node.Variable.Name.Value
+				}
+				reportError(
+					context,
+					fmt.Sprintf(`Variable "$%v" cannot be non-input type "%v".`,
+						variableName, printer.Print(node.Type)),
+					[]ast.Node{node.Type},
+				)
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -1661,61 +1529,51 @@ func VariablesInAllowedPositionRule(context *ValidationContext) *ValidationRuleI
 
 	varDefMap := map[string]*ast.VariableDefinition{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.OperationDefinition: {
-				Enter: func(p visitor.VisitFuncParams) (string, interface{}) {
-					varDefMap = map[string]*ast.VariableDefinition{}
-					return visitor.ActionNoChange, nil
-				},
-				Leave: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if operation, ok := p.Node.(*ast.OperationDefinition); ok {
-
-						usages := context.RecursiveVariableUsages(operation)
-						for _, usage := range usages {
-							varName := ""
-							if usage != nil && usage.Node != nil && usage.Node.Name != nil {
-								varName = usage.Node.Name.Value
-							}
-							varDef, _ := varDefMap[varName]
-							if varDef != nil && usage.Type != nil {
-								varType, err := typeFromAST(*context.Schema(), varDef.Type)
-								if err != nil {
-									varType = nil
-								}
-								if varType != nil && !isTypeSubTypeOf(context.Schema(), effectiveType(varType, varDef), usage.Type) {
-									reportError(
-										context,
-										fmt.Sprintf(`Variable "$%v" of type "%v" used in position `+
-											`expecting type "%v".`, varName, varType, usage.Type),
-										[]ast.Node{varDef, usage.Node},
-									)
-								}
-							}
-						}
+	ruleVisitor := &visitor.Visitor{
+		EnterOperationDefinition: func(node *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
+			varDefMap = map[string]*ast.VariableDefinition{}
+			return visitor.Continue
+		},
+		LeaveOperationDefinition: func(operation *ast.OperationDefinition, c *visitor.Cursor) visitor.Action {
 
+			usages := context.RecursiveVariableUsages(operation)
+			for _, usage := range usages {
+				varName := ""
+				if usage != nil && usage.Node != nil && usage.Node.Name != nil {
+					varName = usage.Node.Name.Value
+				}
+				varDef, _ := varDefMap[varName]
+				if varDef != nil && usage.Type != nil {
+					varType, err := typeFromAST(*context.Schema(), varDef.Type)
+					if err != nil {
+						varType = nil
 					}
-					return visitor.ActionNoChange, nil
-				},
-			},
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if varDefAST, ok := p.Node.(*ast.VariableDefinition); ok {
-						defName := ""
-						if varDefAST.Variable != nil && varDefAST.Variable.Name != nil {
-							defName = varDefAST.Variable.Name.Value
-						}
-						if defName != "" {
-							varDefMap[defName] = varDefAST
-						}
+					if varType != nil && !isTypeSubTypeOf(context.Schema(), effectiveType(varType, varDef), usage.Type) {
+						reportError(
+							context,
+							fmt.Sprintf(`Variable "$%v" of type "%v" used in position `+
+								`expecting type "%v".`, varName, varType, usage.Type),
+							[]ast.Node{varDef, usage.Node},
+						)
 					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+				}
+			}
+
+			return visitor.Continue
+		},
+		EnterVariableDefinition: func(varDefAST *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			defName := ""
+			if varDefAST.Variable != nil && varDefAST.Variable.Name != nil {
+				defName = varDefAST.Variable.Name.Value
+			}
+			if defName != "" {
+				varDefMap[defName] = varDefAST
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
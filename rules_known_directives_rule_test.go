@@ -93,6 +93,22 @@ func TestValidate_KnownDirectives_WithMisplacedDirectives(t *testing.T) {
 		testutil.RuleError(`Directive "onQuery" may not be used on MUTATION.`, 7, 20),
 	})
 }
+func TestValidate_KnownDirectives_WithWellPlacedVariableDefinitionDirectives(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.KnownDirectivesRule, `
+      query Foo($var: Boolean @onVariableDefinition) {
+        name
+      }
+    `)
+}
+func TestValidate_KnownDirectives_WithMisplacedVariableDefinitionDirectives(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.KnownDirectivesRule, `
+      query Foo($var: Boolean @onQuery) {
+        name
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Directive "onQuery" may not be used on VARIABLE_DEFINITION.`, 2, 31),
+	})
+}
 
 func TestValidate_KnownDirectives_WithinSchemaLanguage_WithWellPlacedDirectives(t *testing.T) {
 	testutil.ExpectPassesRule(t, graphql.KnownDirectivesRule, `
@@ -163,3 +179,17 @@ func TestValidate_KnownDirectives_WithinSchemaLanguage_WithMisplacedDirectives(t
 		testutil.RuleError(`Directive "onObject" may not be used on SCHEMA.`, 22, 16),
 	})
 }
+
+func TestValidate_KnownDirectives_WithinSchemaLanguage_WithWellPlacedSchemaExtensionDirectives(t *testing.T) {
+	testutil.ExpectPassesRule(t, graphql.KnownDirectivesRule, `
+        extend schema @onSchema
+    `)
+}
+
+func TestValidate_KnownDirectives_WithinSchemaLanguage_WithMisplacedSchemaExtensionDirectives(t *testing.T) {
+	testutil.ExpectFailsRule(t, graphql.KnownDirectivesRule, `
+        extend schema @onObject
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Directive "onObject" may not be used on SCHEMA.`, 2, 23),
+	})
+}
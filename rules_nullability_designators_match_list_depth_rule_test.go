@@ -0,0 +1,52 @@
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/testutil"
+)
+
+func TestValidate_NullabilityDesignatorsMatchListDepth_WithoutLists(t *testing.T) {
+	testutil.ExpectPassesNullabilityRule(t, graphql.NullabilityDesignatorsMatchListDepthRule, `
+      {
+        human {
+          name!
+          iq?
+          pets! {
+            name?
+          }
+        }
+      }
+    `)
+}
+func TestValidate_NullabilityDesignatorsMatchListDepth_WithMatchingLists(t *testing.T) {
+	testutil.ExpectPassesNullabilityRule(t, graphql.NullabilityDesignatorsMatchListDepthRule, `
+      {
+        human {
+          pets[!]? {
+            name
+          }
+          relatives[] {
+            name
+          }
+        }
+      }
+    `)
+}
+func TestValidate_NullabilityDesignatorsMatchListDepth_WithMismatchingLists(t *testing.T) {
+	testutil.ExpectFailsNullabilityRule(t, graphql.NullabilityDesignatorsMatchListDepthRule, `
+      {
+        human {
+          name[]!
+          pets[[!]] {
+            name
+          }
+        }
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Nullability designator of field "name" has 1 list dimensions but its type "String" has 0.`, 4, 15),
+		testutil.RuleError(`Nullability designator of field "pets" has 2 list dimensions but its type "[Pet]" has 1.`, 5, 15),
+	})
+}
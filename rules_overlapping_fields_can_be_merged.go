@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/dagger/graphql/language/ast"
-	"github.com/dagger/graphql/language/kinds"
 	"github.com/dagger/graphql/language/printer"
 	"github.com/dagger/graphql/language/visitor"
 )
@@ -55,39 +54,33 @@ func OverlappingFieldsCanBeMergedRule(context *ValidationContext) *ValidationRul
 	// times, so this improves the performance of this validator.
 	cacheMap := map[*ast.SelectionSet]*fieldsAndFragmentNames{}
 
-	visitorOpts := &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.SelectionSet: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if selectionSet, ok := p.Node.(*ast.SelectionSet); ok && selectionSet != nil {
-						parentType, _ := context.ParentType().(Named)
-
-						rule := &overlappingFieldsCanBeMergedRule{
-							context:     context,
-							comparedSet: comparedSet,
-							cacheMap:    cacheMap,
-						}
-						conflicts := rule.findConflictsWithinSelectionSet(parentType, selectionSet)
-						if len(conflicts) > 0 {
-							for _, c := range conflicts {
-								responseName := c.Reason.Name
-								reason := c.Reason
-								reportError(
-									context,
-									fieldsConflictMessage(responseName, reason),
-									append(c.FieldsLeft, c.FieldsRight...),
-								)
-							}
-							return visitor.ActionNoChange, nil
-						}
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	ruleVisitor := &visitor.Visitor{
+		EnterSelectionSet: func(selectionSet *ast.SelectionSet, c *visitor.Cursor) visitor.Action {
+			parentType, _ := context.ParentType().(Named)
+
+			rule := &overlappingFieldsCanBeMergedRule{
+				context:     context,
+				comparedSet: comparedSet,
+				cacheMap:    cacheMap,
+			}
+			conflicts := rule.findConflictsWithinSelectionSet(parentType, selectionSet)
+			if len(conflicts) > 0 {
+				for _, c := range conflicts {
+					responseName := c.Reason.Name
+					reason := c.Reason
+					reportError(
+						context,
+						fieldsConflictMessage(responseName, reason),
+						append(c.FieldsLeft, c.FieldsRight...),
+					)
+				}
+				return visitor.Continue
+			}
+			return visitor.Continue
 		},
 	}
 	return &ValidationRuleInstance{
-		VisitorOpts: visitorOpts,
+		Visitor: ruleVisitor,
 	}
 }
 
@@ -376,14 +369,15 @@ func (rule *overlappingFieldsCanBeMergedRule) findConflict(parentFieldsAreMutual
 	_, isParentType2Object := parentType2.(*Object)
 	areMutuallyExclusive := parentFieldsAreMutuallyExclusive || parentType1 != parentType2 && isParentType1Object && isParentType2Object
 
-	// The return type for each field.
+	// The return type for each field, as modified by its nullability
+	// designator.
 	var type1 Type
 	var type2 Type
 	if def1 != nil {
-		type1 = def1.Type
+		type1 = nullabilityType(def1.Type, ast1.Nullability)
 	}
 	if def2 != nil {
-		type2 = def2.Type
+		type2 = nullabilityType(def2.Type, ast2.Nullability)
 	}
 
 	if !areMutuallyExclusive {
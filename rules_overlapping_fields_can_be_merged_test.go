@@ -147,6 +147,28 @@ func TestValidate_OverlappingFieldsCanBeMerged_ConflictingArgs(t *testing.T) {
 			3, 9, 4, 9),
 	})
 }
+func TestValidate_OverlappingFieldsCanBeMerged_IdenticalNullabilityDesignators(t *testing.T) {
+	testutil.ExpectPassesNullabilityRule(t, graphql.OverlappingFieldsCanBeMergedRule, `
+      fragment identicalNullability on Dog {
+        name!
+        name!
+        nickname?
+        nickname
+      }
+    `)
+}
+func TestValidate_OverlappingFieldsCanBeMerged_ConflictingNullabilityDesignators(t *testing.T) {
+	testutil.ExpectFailsNullabilityRule(t, graphql.OverlappingFieldsCanBeMergedRule, `
+      fragment conflictingNullability on Dog {
+        name!
+        name
+      }
+    `, []gqlerrors.FormattedError{
+		testutil.RuleError(`Fields "name" conflict because they return conflicting types String! and String. `+
+			`Use different aliases on the fields to fetch both if this was intentional.`,
+			3, 9, 4, 9),
+	})
+}
 func TestValidate_OverlappingFieldsCanBeMerged_AllowDifferentArgsWhereNoConflictIsPossible(t *testing.T) {
 	// This is valid since no object can be both a "Dog" and a "Cat", thus
 	// these fields can never overlap.
@@ -0,0 +1,227 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/ast"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/language/printer"
+	"github.com/dagger/graphql/language/source"
+)
+
+// OperationRegistry holds the set of operations a server is willing to run.
+// When set on Params, any request whose normalized hash is not registered
+// is rejected before validation, unless ReportOnly is enabled, in which case
+// the operation is recorded and executed as usual.
+//
+// Operations are identified by the SHA-256 hash of their normalized form
+// (see OperationHash), so clients are free to format their documents
+// differently from the registered copy.
+type OperationRegistry struct {
+	// ReportOnly records unknown operations instead of rejecting them.
+	ReportOnly bool
+
+	// OnUnknown, if set, is called with the hash and the request string of
+	// every operation that is not in the registry.
+	OnUnknown func(hash string, requestString string)
+
+	mu         sync.RWMutex
+	operations map[string]string
+	unknown    map[string]string
+}
+
+// OperationManifest is the JSON representation of a set of registered
+// operations, as read by LoadOperationManifest.
+//
+// Example:
+//
+//	{
+//	  "operations": [
+//	    {"name": "GetUser", "body": "query GetUser($id: ID!) { user(id: $id) { name } }"}
+//	  ]
+//	}
+type OperationManifest struct {
+	Operations []OperationManifestEntry `json:"operations"`
+}
+
+// OperationManifestEntry is a single operation of an OperationManifest.
+// The ID is optional; when present it must match the normalized hash of
+// the body.
+type OperationManifestEntry struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Body string `json:"body"`
+}
+
+// maxUnknownOperations bounds the number of unknown operations kept by a
+// registry, so that clients cannot grow it without limit. OnUnknown is still
+// called once the limit is reached.
+const maxUnknownOperations = 1000
+
+// NewOperationRegistry returns an empty OperationRegistry.
+func NewOperationRegistry() *OperationRegistry {
+	return &OperationRegistry{
+		operations: map[string]string{},
+		unknown:    map[string]string{},
+	}
+}
+
+// LoadOperationRegistryFromDir registers every `.graphql` file found in dir
+// and its sub-directories. Each file is registered as a single document.
+func LoadOperationRegistryFromDir(dir string) (*OperationRegistry, error) {
+	registry := NewOperationRegistry()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".graphql" {
+			return nil
+		}
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := registry.register(string(body), path); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// LoadOperationManifest registers every operation of the JSON manifest read
+// from r. See OperationManifest for the expected format.
+func LoadOperationManifest(r io.Reader) (*OperationRegistry, error) {
+	manifest := OperationManifest{}
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid operation manifest: %v", err)
+	}
+	registry := NewOperationRegistry()
+	for i, entry := range manifest.Operations {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("operations[%d]", i)
+		}
+		hash, err := registry.register(entry.Body, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if entry.ID != "" && entry.ID != hash {
+			return nil, fmt.Errorf("%s: id %q does not match the operation hash %q", name, entry.ID, hash)
+		}
+	}
+	return registry, nil
+}
+
+// Register adds requestString to the registry and returns its hash.
+func (r *OperationRegistry) Register(requestString string) (string, error) {
+	return r.register(requestString, "GraphQL request")
+}
+
+func (r *OperationRegistry) register(requestString string, name string) (string, error) {
+	AST, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(requestString),
+			Name: name,
+		}),
+	})
+	if err != nil {
+		return "", err
+	}
+	hash := OperationHash(AST)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.operations == nil {
+		r.operations = map[string]string{}
+	}
+	r.operations[hash] = requestString
+	return hash, nil
+}
+
+// Lookup returns the registered request string for hash.
+func (r *OperationRegistry) Lookup(hash string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	requestString, ok := r.operations[hash]
+	return requestString, ok
+}
+
+// Hashes returns the sorted hashes of all the registered operations.
+func (r *OperationRegistry) Hashes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hashes := make([]string, 0, len(r.operations))
+	for hash := range r.operations {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// Unknown returns the operations that were seen but not registered, keyed
+// by hash. It is mostly useful in ReportOnly mode.
+func (r *OperationRegistry) Unknown() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	unknown := make(map[string]string, len(r.unknown))
+	for hash, requestString := range r.unknown {
+		unknown[hash] = requestString
+	}
+	return unknown
+}
+
+// check reports whether the document may be executed, recording it as
+// unknown if it is not registered.
+func (r *OperationRegistry) check(AST *ast.Document, requestString string) bool {
+	hash := OperationHash(AST)
+	if _, ok := r.Lookup(hash); ok {
+		return true
+	}
+
+	r.mu.Lock()
+	if r.unknown == nil {
+		r.unknown = map[string]string{}
+	}
+	if len(r.unknown) < maxUnknownOperations {
+		r.unknown[hash] = requestString
+	}
+	r.mu.Unlock()
+
+	if r.OnUnknown != nil {
+		r.OnUnknown(hash, requestString)
+	}
+	return r.ReportOnly
+}
+
+// OperationHash returns the hex encoded SHA-256 hash of the normalized form
+// of the document, as printed by printer.Format in compact mode. Formatting,
+// comments and commas do not change the hash, even when the comments are
+// kept in the document.
+func OperationHash(AST *ast.Document) string {
+	normalized := printer.Format(AST, printer.Options{Compact: true})
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkOperationRegistry returns the errors to report when the operation is
+// not allowed by p.OperationRegistry.
+func checkOperationRegistry(p *Params, AST *ast.Document) []gqlerrors.FormattedError {
+	if p.OperationRegistry == nil || p.OperationRegistry.check(AST, p.RequestString) {
+		return nil
+	}
+	return gqlerrors.FormatErrors(gqlerrors.NewFormattedError("Operation is not in the list of allowed operations."))
+}
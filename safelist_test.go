@@ -0,0 +1,218 @@
+package graphql_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dagger/graphql"
+	"github.com/dagger/graphql/gqlerrors"
+	"github.com/dagger/graphql/language/parser"
+	"github.com/dagger/graphql/testutil"
+)
+
+func TestOperationRegistry_AllowsRegisteredOperation(t *testing.T) {
+	registry := graphql.NewOperationRegistry()
+	if _, err := registry.Register(`query HeroNameQuery { hero { name } }`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// formatting differences do not change the hash
+	query := `
+		# a comment
+		query HeroNameQuery {
+			hero {
+				name,
+			}
+		}
+	`
+	result := graphql.Do(graphql.Params{
+		Schema:            testutil.StarWarsSchema,
+		RequestString:     query,
+		OperationRegistry: registry,
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"hero": map[string]interface{}{
+				"name": "R2-D2",
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+	if len(registry.Unknown()) != 0 {
+		t.Fatalf("expected no unknown operations, got %v", registry.Unknown())
+	}
+}
+
+func TestOperationRegistry_IgnoresKeptComments(t *testing.T) {
+	registry := graphql.NewOperationRegistry()
+	if _, err := registry.Register(`{ hero { name } }`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:            testutil.StarWarsSchema,
+		RequestString:     "# c\n{ hero { name # name\n } }",
+		OperationRegistry: registry,
+		ParseOptions:      parser.ParseOptions{KeepComments: true},
+	})
+	expected := &graphql.Result{
+		Data: map[string]interface{}{
+			"hero": map[string]interface{}{
+				"name": "R2-D2",
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+}
+
+func TestOperationRegistry_RejectsUnknownOperation(t *testing.T) {
+	registry := graphql.NewOperationRegistry()
+	if _, err := registry.Register(`query HeroNameQuery { hero { name } }`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := `query HeroNameQuery { hero { id name } }`
+	result := graphql.Do(graphql.Params{
+		Schema:            testutil.StarWarsSchema,
+		RequestString:     query,
+		OperationRegistry: registry,
+	})
+	expected := &graphql.Result{
+		Errors: []gqlerrors.FormattedError{
+			gqlerrors.NewFormattedError("Operation is not in the list of allowed operations."),
+		},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, result))
+	}
+	AST, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedUnknown := map[string]string{graphql.OperationHash(AST): query}
+	if !reflect.DeepEqual(expectedUnknown, registry.Unknown()) {
+		t.Fatalf("Unexpected unknown operations, Diff: %v", testutil.Diff(expectedUnknown, registry.Unknown()))
+	}
+}
+
+func TestOperationRegistry_ReportOnly(t *testing.T) {
+	registry := graphql.NewOperationRegistry()
+	registry.ReportOnly = true
+	reported := []string{}
+	registry.OnUnknown = func(hash string, requestString string) {
+		reported = append(reported, requestString)
+	}
+
+	query := `query HeroNameQuery { hero { name } }`
+	result := graphql.Do(graphql.Params{
+		Schema:            testutil.StarWarsSchema,
+		RequestString:     query,
+		OperationRegistry: registry,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if !reflect.DeepEqual([]string{query}, reported) {
+		t.Fatalf("Unexpected reported operations: %v", reported)
+	}
+	if len(registry.Unknown()) != 1 {
+		t.Fatalf("expected one unknown operation, got %v", registry.Unknown())
+	}
+}
+
+func TestOperationRegistry_LoadFromDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "operations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"hero.graphql":          `query HeroNameQuery { hero { name } }`,
+		"nested/human.graphql":  `query HumanQuery($id: String!) { human(id: $id) { name } }`,
+		"nested/ignored.txt":    `not a graphql file`,
+		"nested/.hidden.json":   `{}`,
+		"nested/deeper/a.other": `{`,
+	}
+	for name, body := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry, err := graphql.LoadOperationRegistryFromDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(registry.Hashes()) != 2 {
+		t.Fatalf("expected 2 registered operations, got %v", registry.Hashes())
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:            testutil.StarWarsSchema,
+		RequestString:     `query HumanQuery($id: String!) { human(id: $id) { name } }`,
+		VariableValues:    map[string]interface{}{"id": "1000"},
+		OperationRegistry: registry,
+	})
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+}
+
+func TestOperationRegistry_LoadFromDirReportsSyntaxErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "operations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "broken.graphql"), []byte(`query {`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = graphql.LoadOperationRegistryFromDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.graphql") {
+		t.Fatalf("expected an error mentioning the file, got %v", err)
+	}
+}
+
+func TestOperationRegistry_LoadManifest(t *testing.T) {
+	AST, err := parser.Parse(parser.ParseParams{Source: `query HeroNameQuery { hero { name } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := graphql.OperationHash(AST)
+
+	manifest := `{
+		"operations": [
+			{"id": "` + hash + `", "name": "HeroNameQuery", "body": "query HeroNameQuery { hero { name } }"},
+			{"name": "HeroIDQuery", "body": "query HeroIDQuery { hero { id } }"}
+		]
+	}`
+	registry, err := graphql.LoadOperationManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := registry.Lookup(hash); !ok {
+		t.Fatalf("expected %v to be registered", hash)
+	}
+	if len(registry.Hashes()) != 2 {
+		t.Fatalf("expected 2 registered operations, got %v", registry.Hashes())
+	}
+
+	badManifest := `{"operations": [{"id": "nope", "body": "{ hero { name } }"}]}`
+	if _, err := graphql.LoadOperationManifest(strings.NewReader(badManifest)); err == nil {
+		t.Fatalf("expected an error for a mismatching id")
+	}
+}
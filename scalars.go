@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"strconv"
 	"time"
@@ -568,3 +569,46 @@ var DateTime = NewScalar(ScalarConfig{
 		return nil
 	},
 })
+
+// UploadedFile is the value of an Upload argument: a file sent along with
+// the operation in a GraphQL multipart request.
+type UploadedFile struct {
+	// Filename is the name of the file as sent by the client.
+	Filename string
+	// Size is the size of the file in bytes.
+	Size int64
+	// ContentType is the media type of the file as sent by the client.
+	ContentType string
+	// File holds the content of the file. It is only valid while the
+	// operation executes.
+	File io.Reader
+}
+
+func coerceUpload(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *UploadedFile:
+		if value == nil {
+			return nil
+		}
+		return value
+	case UploadedFile:
+		return &value
+	default:
+		return nil
+	}
+}
+
+// Upload is the type of files sent through GraphQL multipart requests.
+// Uploads can only be provided as variables; the value received by resolvers
+// is an *UploadedFile.
+var Upload = NewScalar(ScalarConfig{
+	Name:        "Upload",
+	Description: "The `Upload` scalar type represents a file upload.",
+	Serialize: func(value interface{}) interface{} {
+		return nil
+	},
+	ParseValue: coerceUpload,
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
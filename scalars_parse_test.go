@@ -52,3 +52,25 @@ func TestTypeSystem_Scalar_ParseLiteralOutputDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeSystem_Scalar_ParseValueOutputUpload(t *testing.T) {
+	file := &graphql.UploadedFile{Filename: "a.txt", Size: 1, ContentType: "text/plain"}
+	tests := []struct {
+		Value    interface{}
+		Expected interface{}
+	}{
+		{nil, nil},
+		{"a.txt", nil},
+		{(*graphql.UploadedFile)(nil), nil},
+		{file, file},
+	}
+	for _, test := range tests {
+		val := graphql.Upload.ParseValue(test.Value)
+		if val != test.Expected {
+			t.Fatalf("failed Upload.ParseValue(%T(%v)), expected: %v, got %v", test.Value, test.Value, test.Expected, val)
+		}
+	}
+	if val := graphql.Upload.ParseLiteral(&ast.StringValue{Value: "a.txt"}); val != nil {
+		t.Fatalf("failed Upload.ParseLiteral, expected: nil, got %v", val)
+	}
+}
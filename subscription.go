@@ -3,56 +3,75 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"io"
+	"reflect"
 
 	"github.com/dagger/graphql/gqlerrors"
-	"github.com/dagger/graphql/language/parser"
-	"github.com/dagger/graphql/language/source"
 )
 
+// OverflowStrategy is what a subscription does when its consumer does not
+// keep up and the buffer of its result channel is full.
+type OverflowStrategy int
+
+const (
+	// OverflowBlock waits for the consumer to receive a result, or for the
+	// context of the subscription to be done.
+	OverflowBlock OverflowStrategy = iota
+
+	// OverflowDrop discards the new result.
+	OverflowDrop
+
+	// OverflowCoalesce discards the buffered results, keeping only the new one.
+	OverflowCoalesce
+
+	// OverflowClose ends the subscription, closing the result channel once the
+	// buffered results are received.
+	OverflowClose
+)
+
+// Backpressure configures how the results of a subscription are buffered for
+// slow consumers.
+type Backpressure struct {
+	// BufferSize is the capacity of the result channel. The channel is
+	// unbuffered when zero, unless Overflow is not OverflowBlock, in which case
+	// a capacity of 1 is used.
+	BufferSize int
+
+	// Overflow is applied when the result channel is full.
+	Overflow OverflowStrategy
+}
+
 // SubscribeParams parameters for subscribing
 type SubscribeParams struct {
-	Schema        Schema
-	RequestString string
-	RootValue     interface{}
-	// ContextValue    context.Context
-	VariableValues  map[string]interface{}
-	OperationName   string
-	FieldResolver   FieldResolveFn
+	Schema         Schema
+	RequestString  string
+	RootValue      interface{}
+	ContextValue   context.Context
+	VariableValues map[string]interface{}
+	OperationName  string
+
+	// FieldResolver is used to resolve the fields of each event that have no
+	// Resolve function. DefaultResolveFn is used when nil.
+	FieldResolver FieldResolveFn
+
+	// FieldSubscriber is used to subscribe to the subscription field when it
+	// has no Subscribe function.
 	FieldSubscriber FieldResolveFn
+
+	// Backpressure configures the buffering of the results.
+	Backpressure Backpressure
 }
 
 // Subscribe performs a subscribe operation on the given query and schema
 // To finish a subscription you can simply close the channel from inside the `Subscribe` function
-// currently does not support extensions hooks
+// The extensions' Init, ParseDidStart and ValidationDidStart hooks run once per subscription,
+// the execution hooks run for each event, see ExecuteSubscription.
 func Subscribe(p Params) chan *Result {
-
-	source := source.NewSource(&source.Source{
-		Body: []byte(p.RequestString),
-		Name: "GraphQL request",
-	})
-
-	// TODO run extensions hooks
-
-	// parse the source
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
-	if err != nil {
-
-		// merge the errors from extensions and the original error from parser
-		return sendOneResultAndClose(&Result{
-			Errors: gqlerrors.FormatErrors(err),
-		})
+	AST, result := parseAndValidate(&p)
+	if result != nil {
+		return sendOneResultAndClose(result)
 	}
 
-	// validate document
-	validationResult := ValidateDocument(&p.Schema, AST, nil)
-
-	if !validationResult.IsValid {
-		// run validation finish functions for extensions
-		return sendOneResultAndClose(&Result{
-			Errors: validationResult.Errors,
-		})
-
-	}
 	return ExecuteSubscription(ExecuteParams{
 		Schema:        p.Schema,
 		Root:          p.RootObject,
@@ -60,6 +79,39 @@ func Subscribe(p Params) chan *Result {
 		OperationName: p.OperationName,
 		Args:          p.VariableValues,
 		Context:       p.Context,
+		Backpressure:  p.Backpressure,
+	})
+}
+
+// SubscribeWithParams is like Subscribe, but takes SubscribeParams, allowing
+// to provide a default field resolver and subscriber.
+func SubscribeWithParams(p SubscribeParams) chan *Result {
+	params := Params{
+		Schema:         p.Schema,
+		RequestString:  p.RequestString,
+		VariableValues: p.VariableValues,
+		OperationName:  p.OperationName,
+		Context:        p.ContextValue,
+	}
+	if rootObject, ok := p.RootValue.(map[string]interface{}); ok {
+		params.RootObject = rootObject
+	}
+
+	AST, result := parseAndValidate(&params)
+	if result != nil {
+		return sendOneResultAndClose(result)
+	}
+
+	return ExecuteSubscription(ExecuteParams{
+		Schema:          p.Schema,
+		Root:            p.RootValue,
+		AST:             AST,
+		OperationName:   p.OperationName,
+		Args:            p.VariableValues,
+		Context:         params.Context,
+		FieldResolver:   p.FieldResolver,
+		FieldSubscriber: p.FieldSubscriber,
+		Backpressure:    p.Backpressure,
 	})
 }
 
@@ -71,12 +123,20 @@ func sendOneResultAndClose(res *Result) chan *Result {
 }
 
 // ExecuteSubscription is similar to graphql.Execute but returns a channel instead of a Result
-// currently does not support extensions
+// Each event is executed with Execute, so the extensions' ExecutionDidStart and ResolveFieldDidStart
+// hooks run for each event, and their results are attached to each emitted Result.
+// The ResolveFieldDidStart hooks also run around the call to the field's Subscribe function.
+// The Subscribe function may return any channel that can be received from, an EventStream,
+// or a single payload. Error values received from a channel are sent as error results.
+// The context passed to the Subscribe function is cancelled once the subscription ends,
+// and the subscription ends when p.Context is done, even while a result is pending.
 func ExecuteSubscription(p ExecuteParams) chan *Result {
 
 	if p.Context == nil {
 		p.Context = context.Background()
 	}
+	ctx, cancel := context.WithCancel(p.Context)
+	p.Context = ctx
 
 	var mapSourceToResponse = func(payload interface{}) *Result {
 		return Execute(ExecuteParams{
@@ -86,20 +146,61 @@ func ExecuteSubscription(p ExecuteParams) chan *Result {
 			OperationName: p.OperationName,
 			Args:          p.Args,
 			Context:       p.Context,
+			FieldResolver: p.FieldResolver,
 		})
 	}
-	var resultChannel = make(chan *Result)
+	bufferSize := p.Backpressure.BufferSize
+	if bufferSize < 1 && p.Backpressure.Overflow != OverflowBlock {
+		bufferSize = 1
+	}
+	var resultChannel = make(chan *Result, bufferSize)
+
+	// send sends res according to the overflow strategy, and returns false
+	// when the subscription must end
+	var send = func(res *Result) bool {
+		if p.Backpressure.Overflow == OverflowBlock {
+			select {
+			case resultChannel <- res:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case resultChannel <- res:
+				return true
+			default:
+			}
+			switch p.Backpressure.Overflow {
+			case OverflowDrop:
+				return true
+			case OverflowCoalesce:
+			drain:
+				for {
+					select {
+					case <-resultChannel:
+					default:
+						break drain
+					}
+				}
+			default:
+				return false
+			}
+		}
+	}
 	go func() {
 		defer close(resultChannel)
+		defer cancel()
 		defer func() {
 			if err := recover(); err != nil {
 				e, ok := err.(error)
 				if !ok {
 					return
 				}
-				resultChannel <- &Result{
+				send(&Result{
 					Errors: gqlerrors.FormatErrors(e),
-				}
+				})
 			}
 			return
 		}()
@@ -114,18 +215,18 @@ func ExecuteSubscription(p ExecuteParams) chan *Result {
 		})
 
 		if err != nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(err),
-			}
+			})
 
 			return
 		}
 
 		operationType, err := getOperationRootType(p.Schema, exeContext.Operation)
 		if err != nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(err),
-			}
+			})
 
 			return
 		}
@@ -147,19 +248,22 @@ func ExecuteSubscription(p ExecuteParams) chan *Result {
 		fieldDef := getFieldDef(p.Schema, operationType, fieldName)
 
 		if fieldDef == nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(fmt.Errorf("the subscription field %q is not defined", fieldName)),
-			}
+			})
 
 			return
 		}
 
 		resolveFn := fieldDef.Subscribe
+		if resolveFn == nil {
+			resolveFn = p.FieldSubscriber
+		}
 
 		if resolveFn == nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(fmt.Errorf("the subscription function %q is not defined", fieldName)),
-			}
+			})
 			return
 		}
 		fieldPath := &ResponsePath{
@@ -180,49 +284,119 @@ func ExecuteSubscription(p ExecuteParams) chan *Result {
 			VariableValues: exeContext.VariableValues,
 		}
 
+		extErrs, resolveFieldFinishFn := handleExtensionsResolveFieldDidStart(p.Schema.extensions, exeContext, &info)
+		if len(extErrs) != 0 {
+			send(&Result{
+				Errors: extErrs,
+			})
+
+			return
+		}
+
 		fieldResult, err := resolveFn(ResolveParams{
 			Source:  p.Root,
 			Args:    args,
 			Info:    info,
-			Context: p.Context,
+			Context: exeContext.Context,
 		})
+
+		extErrs = resolveFieldFinishFn(fieldResult, err)
+		if len(extErrs) != 0 {
+			send(&Result{
+				Errors: extErrs,
+			})
+
+			return
+		}
+
 		if err != nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(err),
-			}
+			})
 
 			return
 		}
 
 		if fieldResult == nil {
-			resultChannel <- &Result{
+			send(&Result{
 				Errors: gqlerrors.FormatErrors(fmt.Errorf("no field result")),
-			}
+			})
 
 			return
 		}
 
-		switch fieldResult.(type) {
-		case chan interface{}:
-			sub := fieldResult.(chan interface{})
-			for {
-				select {
-				case <-p.Context.Done():
-					return
-
-				case res, more := <-sub:
-					if !more {
-						return
-					}
-					resultChannel <- mapSourceToResponse(res)
-				}
+		streamEvents(p.Context, fieldResult, func(payload interface{}, err error) bool {
+			if err != nil {
+				return send(&Result{
+					Errors: gqlerrors.FormatErrors(err),
+				})
 			}
-		default:
-			resultChannel <- mapSourceToResponse(fieldResult)
-			return
-		}
+			return send(mapSourceToResponse(payload))
+		})
 	}()
 
 	// return a result channel
 	return resultChannel
 }
+
+// EventStream is a source of subscription events. A field's Subscribe
+// function may return an EventStream instead of a channel.
+type EventStream interface {
+	// Next blocks until the next event is available and returns it. It returns
+	// io.EOF once the stream is exhausted. Any other error is sent as an error
+	// result and ends the stream.
+	Next(ctx context.Context) (interface{}, error)
+
+	// Close releases the resources held by the stream. It is called once the
+	// subscription ends, including when its context is cancelled.
+	Close()
+}
+
+// streamEvents calls emit with each event of source until the source is
+// exhausted, ctx is done or emit returns false. The source can be an EventStream, any channel
+// that can be received from, in which case error values are emitted as
+// errors, or a single payload.
+func streamEvents(ctx context.Context, source interface{}, emit func(payload interface{}, err error) bool) {
+	if stream, ok := source.(EventStream); ok {
+		defer stream.Close()
+		for {
+			payload, err := stream.Next(ctx)
+			if ctx.Err() != nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				emit(nil, err)
+				return
+			}
+			if !emit(payload, nil) {
+				return
+			}
+		}
+	}
+
+	value := reflect.ValueOf(source)
+	if value.Kind() != reflect.Chan || value.Type().ChanDir()&reflect.RecvDir == 0 {
+		emit(source, nil)
+		return
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: value},
+	}
+	for {
+		chosen, event, ok := reflect.Select(cases)
+		if chosen == 0 || !ok {
+			return
+		}
+		payload := event.Interface()
+		if err, ok := payload.(error); ok {
+			if !emit(nil, err) {
+				return
+			}
+			continue
+		}
+		if !emit(payload, nil) {
+			return
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package graphql_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dagger/graphql"
 	"github.com/dagger/graphql/testutil"
@@ -228,7 +233,398 @@ func TestSchemaSubscribe(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "subscribe to a typed channel",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"sub_typed": &graphql.Field{
+						Type: graphql.String,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							c := make(chan string, 2)
+							c <- "a"
+							c <- "b"
+							close(c)
+							return c, nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					sub_typed
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "sub_typed": "a" }`},
+				{Data: `{ "sub_typed": "b" }`},
+			},
+		},
+		{
+			Name: "subscribe to a receive-only channel with errors",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"sub_recv_only": &graphql.Field{
+						Type: graphql.String,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							c := make(chan interface{}, 3)
+							c <- "a"
+							c <- errors.New("event error")
+							c <- "b"
+							close(c)
+							return (<-chan interface{})(c), nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					sub_recv_only
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "sub_recv_only": "a" }`},
+				{Errors: []string{"event error"}},
+				{Data: `{ "sub_recv_only": "b" }`},
+			},
+		},
+		{
+			Name: "subscribe to an event stream",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"sub_stream": &graphql.Field{
+						Type: graphql.String,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							return &sliceEventStream{events: []interface{}{"a", "b"}}, nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					sub_stream
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "sub_stream": "a" }`},
+				{Data: `{ "sub_stream": "b" }`},
+			},
+		},
+		{
+			Name: "event stream errors end the stream",
+			Schema: makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"sub_stream": &graphql.Field{
+						Type: graphql.String,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							return &sliceEventStream{
+								events: []interface{}{"a", "b"},
+								err:    errors.New("stream error"),
+							}, nil
+						},
+					},
+				},
+			}),
+			Query: `
+				subscription {
+					sub_stream
+				}
+			`,
+			ExpectedResults: []testutil.TestResponse{
+				{Data: `{ "sub_stream": "a" }`},
+				{Data: `{ "sub_stream": "b" }`},
+				{Errors: []string{"stream error"}},
+			},
+		},
+	})
+}
+
+type subscribeContextKey string
+
+func TestSubscribeWithParams(t *testing.T) {
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type: graphql.NewObject(graphql.ObjectConfig{
+					Name: "Event",
+					Fields: graphql.Fields{
+						"topic": &graphql.Field{
+							Type: graphql.String,
+						},
+						"payload": &graphql.Field{
+							Type: graphql.String,
+						},
+					},
+				}),
+				Args: graphql.FieldConfigArgument{
+					&graphql.ArgumentConfig{
+						Name: "topic",
+						Type: graphql.String,
+					},
+				},
+			},
+		},
 	})
+
+	// a generic event source, keyed by field name and topic
+	fieldSubscriber := func(p graphql.ResolveParams) (interface{}, error) {
+		prefix := p.Context.Value(subscribeContextKey("prefix")).(string)
+		topic := p.Args["topic"].(string)
+		return makeSubscribeToStringFunction([]string{
+			prefix + p.Info.FieldName + "/" + topic + "/1",
+			prefix + p.Info.FieldName + "/" + topic + "/2",
+		})(p)
+	}
+	// resolves the fields of events sent as "field/topic/payload" strings
+	fieldResolver := func(p graphql.ResolveParams) (interface{}, error) {
+		if p.Info.ParentType.Name() == "Subscription" {
+			return p.Source, nil
+		}
+		parts := strings.Split(strings.TrimPrefix(p.Source.(string), "from-context:"), "/")
+		if p.Info.FieldName == "topic" {
+			return parts[1], nil
+		}
+		return parts[2], nil
+	}
+
+	ctx := context.WithValue(context.Background(), subscribeContextKey("prefix"), "from-context:")
+	results := []*graphql.Result{}
+	for result := range graphql.SubscribeWithParams(graphql.SubscribeParams{
+		Schema:          schema,
+		RequestString:   `subscription { events(topic: "news") { topic payload } }`,
+		ContextValue:    ctx,
+		FieldResolver:   fieldResolver,
+		FieldSubscriber: fieldSubscriber,
+	}) {
+		results = append(results, result)
+	}
+
+	expected := []*graphql.Result{
+		{Data: map[string]interface{}{"events": map[string]interface{}{"topic": "news", "payload": "1"}}},
+		{Data: map[string]interface{}{"events": map[string]interface{}{"topic": "news", "payload": "2"}}},
+	}
+	if !reflect.DeepEqual(expected, results) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, results))
+	}
+}
+
+func TestSubscribeWithParams_FieldSubscriberNotDefined(t *testing.T) {
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+
+	results := []*graphql.Result{}
+	for result := range graphql.SubscribeWithParams(graphql.SubscribeParams{
+		Schema:        schema,
+		RequestString: `subscription { events }`,
+	}) {
+		results = append(results, result)
+	}
+	if len(results) != 1 || len(results[0].Errors) != 1 ||
+		results[0].Errors[0].Message != `the subscription function "events" is not defined` {
+		t.Fatalf("Unexpected results: %v", results)
+	}
+}
+
+// sliceEventStream is an EventStream emitting events, then failing with err
+// if set. Once exhausted, it blocks until its context is done if wait is set.
+type sliceEventStream struct {
+	events []interface{}
+	err    error
+	wait   bool
+	closed chan struct{}
+}
+
+func (s *sliceEventStream) Next(ctx context.Context) (interface{}, error) {
+	if len(s.events) > 0 {
+		event := s.events[0]
+		s.events = s.events[1:]
+		return event, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.wait {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return nil, io.EOF
+}
+
+func (s *sliceEventStream) Close() {
+	if s.closed != nil {
+		close(s.closed)
+	}
+}
+
+func TestSubscribe_EventStreamClosedOnCancel(t *testing.T) {
+	stream := &sliceEventStream{
+		events: []interface{}{"a"},
+		wait:   true,
+		closed: make(chan struct{}),
+	}
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					return stream, nil
+				},
+			},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: "subscription { events }",
+		Context:       ctx,
+	})
+	res := <-c
+	expected := map[string]interface{}{"events": "a"}
+	if !reflect.DeepEqual(expected, res.Data) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expected, res.Data))
+	}
+	cancel()
+
+	select {
+	case <-stream.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream was not closed")
+	}
+	if _, more := <-c; more {
+		t.Fatal("expected the result channel to be closed")
+	}
+}
+
+func TestSubscribe_Backpressure(t *testing.T) {
+	tests := []struct {
+		name         string
+		backpressure graphql.Backpressure
+		expected     []interface{}
+	}{
+		{"drop", graphql.Backpressure{BufferSize: 2, Overflow: graphql.OverflowDrop}, []interface{}{1, 2}},
+		{"coalesce", graphql.Backpressure{BufferSize: 2, Overflow: graphql.OverflowCoalesce}, []interface{}{3, 4}},
+		{"coalesce unbuffered", graphql.Backpressure{Overflow: graphql.OverflowCoalesce}, []interface{}{4}},
+		{"close", graphql.Backpressure{BufferSize: 2, Overflow: graphql.OverflowClose}, []interface{}{1, 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream := &sliceEventStream{
+				events: []interface{}{1, 2, 3, 4},
+				closed: make(chan struct{}),
+			}
+			schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+				Name: "Subscription",
+				Fields: graphql.Fields{
+					"events": &graphql.Field{
+						Type: graphql.Int,
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							return stream, nil
+						},
+					},
+				},
+			})
+			c := graphql.Subscribe(graphql.Params{
+				Schema:        schema,
+				RequestString: "subscription { events }",
+				Backpressure:  test.backpressure,
+			})
+
+			// only start receiving once the stream is exhausted
+			select {
+			case <-stream.closed:
+			case <-time.After(5 * time.Second):
+				t.Fatal("event stream was not closed")
+			}
+			events := []interface{}{}
+			for res := range c {
+				events = append(events, res.Data.(map[string]interface{})["events"])
+			}
+			if !reflect.DeepEqual(test.expected, events) {
+				t.Fatalf("Unexpected events, Diff: %v", testutil.Diff(test.expected, events))
+			}
+		})
+	}
+}
+
+func TestSubscribe_CancelWhileSendIsPending(t *testing.T) {
+	done := make(chan struct{})
+	schema := makeSubscriptionSchema(t, graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					c := make(chan string, 1)
+					c <- "a"
+					go func() {
+						<-p.Context.Done()
+						close(done)
+					}()
+					return c, nil
+				},
+			},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: "subscription { events }",
+		Context:       ctx,
+	})
+	// the result of "a" is pending as nothing receives from c
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription context was not cancelled")
+	}
+	closed := make(chan struct{})
+	go func() {
+		for range c {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the result channel to be closed")
+	}
 }
 
 func makeSubscribeToStringFunction(elements []string) func(p graphql.ResolveParams) (interface{}, error) {
@@ -517,6 +517,10 @@ func init() {
 				Name:      "onInlineFragment",
 				Locations: []string{graphql.DirectiveLocationInlineFragment},
 			}),
+			graphql.NewDirective(graphql.DirectiveConfig{
+				Name:      "onVariableDefinition",
+				Locations: []string{graphql.DirectiveLocationVariableDefinition},
+			}),
 			graphql.NewDirective(graphql.DirectiveConfig{
 				Name:      "onSchema",
 				Locations: []string{graphql.DirectiveLocationSchema},
@@ -575,11 +579,14 @@ func init() {
 	TestSchema = &schema
 
 }
-func expectValidRule(t *testing.T, schema *graphql.Schema, rules []graphql.ValidationRuleFn, queryString string) {
+func expectValidRule(t *testing.T, schema *graphql.Schema, options parser.ParseOptions, rules []graphql.ValidationRuleFn, queryString string) {
 	source := source.NewSource(&source.Source{
 		Body: []byte(queryString),
 	})
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
+	AST, err := parser.Parse(parser.ParseParams{
+		Source:  source,
+		Options: options,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -592,11 +599,14 @@ func expectValidRule(t *testing.T, schema *graphql.Schema, rules []graphql.Valid
 	}
 
 }
-func expectInvalidRule(t *testing.T, schema *graphql.Schema, rules []graphql.ValidationRuleFn, queryString string, expectedErrors []gqlerrors.FormattedError) {
+func expectInvalidRule(t *testing.T, schema *graphql.Schema, options parser.ParseOptions, rules []graphql.ValidationRuleFn, queryString string, expectedErrors []gqlerrors.FormattedError) {
 	source := source.NewSource(&source.Source{
 		Body: []byte(queryString),
 	})
-	AST, err := parser.Parse(parser.ParseParams{Source: source})
+	AST, err := parser.Parse(parser.ParseParams{
+		Source:  source,
+		Options: options,
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -622,16 +632,32 @@ func expectInvalidRule(t *testing.T, schema *graphql.Schema, rules []graphql.Val
 
 }
 func ExpectPassesRule(t *testing.T, rule graphql.ValidationRuleFn, queryString string) {
-	expectValidRule(t, TestSchema, []graphql.ValidationRuleFn{rule}, queryString)
+	expectValidRule(t, TestSchema, parser.ParseOptions{}, []graphql.ValidationRuleFn{rule}, queryString)
 }
 func ExpectFailsRule(t *testing.T, rule graphql.ValidationRuleFn, queryString string, expectedErrors []gqlerrors.FormattedError) {
-	expectInvalidRule(t, TestSchema, []graphql.ValidationRuleFn{rule}, queryString, expectedErrors)
+	expectInvalidRule(t, TestSchema, parser.ParseOptions{}, []graphql.ValidationRuleFn{rule}, queryString, expectedErrors)
 }
 func ExpectFailsRuleWithSchema(t *testing.T, schema *graphql.Schema, rule graphql.ValidationRuleFn, queryString string, expectedErrors []gqlerrors.FormattedError) {
-	expectInvalidRule(t, schema, []graphql.ValidationRuleFn{rule}, queryString, expectedErrors)
+	expectInvalidRule(t, schema, parser.ParseOptions{}, []graphql.ValidationRuleFn{rule}, queryString, expectedErrors)
 }
 func ExpectPassesRuleWithSchema(t *testing.T, schema *graphql.Schema, rule graphql.ValidationRuleFn, queryString string) {
-	expectValidRule(t, schema, []graphql.ValidationRuleFn{rule}, queryString)
+	expectValidRule(t, schema, parser.ParseOptions{}, []graphql.ValidationRuleFn{rule}, queryString)
+}
+
+// nullabilityOptions enable the experimental client controlled nullability
+// designators, which other rule tests parse as syntax errors.
+var nullabilityOptions = parser.ParseOptions{ExperimentalClientControlledNullability: true}
+
+// ExpectPassesNullabilityRule is like ExpectPassesRule, for queries using
+// the experimental client controlled nullability designators.
+func ExpectPassesNullabilityRule(t *testing.T, rule graphql.ValidationRuleFn, queryString string) {
+	expectValidRule(t, TestSchema, nullabilityOptions, []graphql.ValidationRuleFn{rule}, queryString)
+}
+
+// ExpectFailsNullabilityRule is like ExpectFailsRule, for queries using the
+// experimental client controlled nullability designators.
+func ExpectFailsNullabilityRule(t *testing.T, rule graphql.ValidationRuleFn, queryString string, expectedErrors []gqlerrors.FormattedError) {
+	expectInvalidRule(t, TestSchema, nullabilityOptions, []graphql.ValidationRuleFn{rule}, queryString, expectedErrors)
 }
 func RuleError(message string, locs ...int) gqlerrors.FormattedError {
 	locations := []location.SourceLocation{}
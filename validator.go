@@ -3,7 +3,6 @@ package graphql
 import (
 	"github.com/dagger/graphql/gqlerrors"
 	"github.com/dagger/graphql/language/ast"
-	"github.com/dagger/graphql/language/kinds"
 	"github.com/dagger/graphql/language/visitor"
 )
 
@@ -59,15 +58,19 @@ func ValidateDocument(schema *Schema, astDoc *ast.Document, rules []ValidationRu
 func VisitUsingRules(schema *Schema, typeInfo *TypeInfo, astDoc *ast.Document, rules []ValidationRuleFn) []gqlerrors.FormattedError {
 
 	context := NewValidationContext(schema, astDoc, typeInfo)
-	visitors := []*visitor.VisitorOptions{}
+	visitors := []*visitor.Visitor{}
 
 	for _, rule := range rules {
 		instance := rule(context)
-		visitors = append(visitors, instance.VisitorOpts)
+		if instance.Visitor != nil {
+			visitors = append(visitors, instance.Visitor)
+		} else {
+			visitors = append(visitors, visitor.FromOptions(instance.VisitorOpts))
+		}
 	}
 
 	// Visit the whole document with each instance of all provided rules.
-	visitor.Visit(astDoc, visitor.VisitWithTypeInfo(typeInfo, visitor.VisitInParallel(visitors...)), nil)
+	visitor.Walk(astDoc, visitor.WithTypeInfo(typeInfo, visitor.InParallel(visitors...)))
 	return context.Errors()
 }
 
@@ -228,26 +231,18 @@ func (ctx *ValidationContext) VariableUsages(node HasSelectionSet) []*VariableUs
 		Schema: ctx.schema,
 	})
 
-	visitor.Visit(node, visitor.VisitWithTypeInfo(typeInfo, &visitor.VisitorOptions{
-		KindFuncMap: map[string]visitor.NamedVisitFuncs{
-			kinds.VariableDefinition: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					return visitor.ActionSkip, nil
-				},
-			},
-			kinds.Variable: {
-				Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
-					if node, ok := p.Node.(*ast.Variable); ok && node != nil {
-						usages = append(usages, &VariableUsage{
-							Node: node,
-							Type: typeInfo.InputType(),
-						})
-					}
-					return visitor.ActionNoChange, nil
-				},
-			},
+	visitor.Walk(node, visitor.WithTypeInfo(typeInfo, &visitor.Visitor{
+		EnterVariableDefinition: func(node *ast.VariableDefinition, c *visitor.Cursor) visitor.Action {
+			return visitor.Skip
+		},
+		EnterVariable: func(node *ast.Variable, c *visitor.Cursor) visitor.Action {
+			usages = append(usages, &VariableUsage{
+				Node: node,
+				Type: typeInfo.InputType(),
+			})
+			return visitor.Continue
 		},
-	}), nil)
+	}))
 
 	ctx.variableUsages[node] = usages
 	return usages
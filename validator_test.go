@@ -1,6 +1,7 @@
 package graphql_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/dagger/graphql"
@@ -95,3 +96,32 @@ func TestValidator_SupportsFullValidation_ValidatesUsingACustomTypeInfo(t *testi
 		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedErrors, errors))
 	}
 }
+
+func TestValidator_ReportsTheSourceOfEachLocation(t *testing.T) {
+	AST, err := parser.ParseSources([]*source.Source{
+		{Body: []byte("query Dog {\n  dog {\n    name\n    ...Nickname\n  }\n}\n"), Name: "queries/dog.graphql"},
+		{Body: []byte("fragment Nickname on Dog {\n  name: nickname\n}\n"), Name: "fragments/nickname.graphql"},
+	}, parser.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	validationResult := graphql.ValidateDocument(testutil.TestSchema, AST, nil)
+
+	expectedErrors := []gqlerrors.FormattedError{
+		{
+			Message: `Fields "name" conflict because name and nickname are different fields. ` +
+				`Use different aliases on the fields to fetch both if this was intentional.`,
+			Locations: []location.SourceLocation{
+				{Line: 3, Column: 5},
+				{Line: 2, Column: 3},
+			},
+		},
+	}
+	if !testutil.EqualFormattedErrors(expectedErrors, validationResult.Errors) {
+		t.Fatalf("Unexpected result, Diff: %v", testutil.Diff(expectedErrors, validationResult.Errors))
+	}
+	expectedSources := []string{"queries/dog.graphql", "fragments/nickname.graphql"}
+	if sources := validationResult.Errors[0].Sources; !reflect.DeepEqual(expectedSources, sources) {
+		t.Fatalf("Unexpected sources, Diff: %v", testutil.Diff(expectedSources, sources))
+	}
+}